@@ -17,10 +17,23 @@ def generate_config_file(rd_server_v,db_name_v,redis_ip_v,redis_port_v,redis_use
 
     # apiserver.conf
     apiserver_file_template_str ='''
+    [mongodb]
+    host=$mongo_host
+    usr=$mongo_user
+    pwd=$mongo_pass
+    database=$db
+    port=$mongo_port
+    maxOpenConns=3000
+    maxIDleConns=1000
+    [auth]
+    enable=false
+    [ratelimit]
+    qps=0
+    burst=0
     '''
 
     template = FileTemplate(apiserver_file_template_str)
-    result = template.substitute()
+    result = template.substitute(dict(db=db_name_v,mongo_user=mongo_user_v,mongo_host=mongo_ip_v,mongo_pass=mongo_pass_v,mongo_port=mongo_port_v))
     with open( output + "apiserver.conf",'w') as tmp_file:
         tmp_file.write(result)
 
@@ -75,6 +88,10 @@ def generate_config_file(rd_server_v,db_name_v,redis_ip_v,redis_port_v,redis_use
     usr = $redis_user
     pwd = $redis_pass
     database = 0
+
+    [hostsnap]
+    history_days = 90
+    stale_hours = 24
     '''
 
     template = FileTemplate(datacollection_file_template_str)
@@ -100,6 +117,8 @@ def generate_config_file(rd_server_v,db_name_v,redis_ip_v,redis_port_v,redis_use
     port=$redis_port
     maxOpenConns=3000
     maxIDleConns=1000
+    [event]
+    historyRetention=168
     '''
     
     template = FileTemplate(eventserver_file_template_str)
@@ -113,6 +132,12 @@ def generate_config_file(rd_server_v,db_name_v,redis_ip_v,redis_port_v,redis_use
     addr=$rd_server
     user=bkzk
     pwd=L%blKas
+    [dynamicgroup]
+    interval=300
+    [privilege]
+    enable=false
+    admins=admin
+    cache_ttl=60
     '''
     template = FileTemplate(host_file_template_str)
     result = template.substitute(dict(rd_server=rd_server_v))
@@ -245,6 +270,10 @@ def generate_config_file(rd_server_v,db_name_v,redis_ip_v,redis_port_v,redis_use
     port=$mongo_port
     maxOpenConns=3000
     maxIDleConns=1000
+    [privilege]
+    enable=false
+    admins=admin
+    cache_ttl=60
     '''
 
     template = FileTemplate(topo_file_template_str)
@@ -14,6 +14,7 @@ package options
 
 import (
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/storage/mgoclient"
 
 	"github.com/spf13/pflag"
 )
@@ -37,3 +38,18 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.ServConf.RegDiscover, "regdiscv", "", "hosts of register and discover server. e.g: 127.0.0.1:2181")
 	fs.StringVar(&s.ServConf.ExConfig, "config", "", "The config path. e.g ")
 }
+
+// Config the config of the api server
+type Config struct {
+	MongoDB mgoclient.MongoConfig
+	// AuthEnable the requests to /api/v3 must carry a valid api key when it is true
+	AuthEnable bool
+	// RateLimits the limits of each caller keyed by the request type, "" is the default of all types
+	RateLimits map[string]RateLimit
+}
+
+// RateLimit the requests a caller can send, unlimited when the QPS is not positive
+type RateLimit struct {
+	QPS   int64
+	Burst int64
+}
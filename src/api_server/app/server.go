@@ -16,11 +16,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/emicklei/go-restful"
 
 	"configcenter/src/api_server/app/options"
 	"configcenter/src/api_server/ccapi/logics/v2"
+	"configcenter/src/api_server/middleware"
 	apisvc "configcenter/src/api_server/service"
 	"configcenter/src/api_server/service/v3"
 	"configcenter/src/apimachinery"
@@ -28,8 +32,10 @@ import (
 	"configcenter/src/apimachinery/util"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/types"
 	"configcenter/src/common/version"
+	"configcenter/src/storage/mgoclient"
 )
 
 func Run(ctx context.Context, op *options.ServerOption) error {
@@ -61,6 +67,9 @@ func Run(ctx context.Context, op *options.ServerOption) error {
 	if err != nil {
 		return fmt.Errorf("new proxy discovery instance failed, err: %v", err)
 	}
+	v3Service.Auth = middleware.NewAuthenticator()
+	v3Service.Limiter = v3.NewRateLimiter()
+	v3Service.Routes = v3.NewRouteTable()
 
 	ctnr := restful.NewContainer()
 	ctnr.Router(restful.CurlyRouter{})
@@ -83,6 +92,8 @@ func Run(ctx context.Context, op *options.ServerOption) error {
 	}
 
 	apiSvr := new(APIServer)
+	apiSvr.Limiter = v3Service.Limiter
+	apiSvr.Routes = v3Service.Routes
 	engine, err := backbone.NewBackbone(ctx, op.ServConf.RegDiscover,
 		types.CC_MODULE_APISERVER,
 		op.ServConf.ExConfig,
@@ -99,6 +110,25 @@ func Run(ctx context.Context, op *options.ServerOption) error {
 	apiSvr.Core = engine
 	apiSvr.Service = v2Service
 	apiSvr.Logic = v2Service.Logics
+	for {
+		config := apiSvr.getConfig()
+		if config == nil {
+			time.Sleep(time.Second * 2)
+			blog.V(3).Info("config not found, retry 2s later")
+			continue
+		}
+		if config.AuthEnable {
+			db, err := mgoclient.NewFromConfig(config.MongoDB)
+			if err != nil {
+				return fmt.Errorf("connect mongo server failed %s", err.Error())
+			}
+			if err = db.Open(); err != nil {
+				return fmt.Errorf("connect mongo server failed %s", err.Error())
+			}
+			v3Service.Auth.SetConfig(true, db)
+		}
+		break
+	}
 	select {}
 	return nil
 }
@@ -107,10 +137,80 @@ type APIServer struct {
 	Core    *backbone.Engine
 	Service *apisvc.Service
 	Logic   *logics.Logics
+	Limiter *v3.RateLimiter
+	Routes  *v3.RouteTable
+
+	configLock sync.Mutex
+	Config     *options.Config
 }
 
 func (h *APIServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
+	h.configLock.Lock()
+	defer h.configLock.Unlock()
+	if len(current.ConfigMap) > 0 {
+		if h.Config == nil {
+			h.Config = new(options.Config)
+		}
+		h.Config.MongoDB.Address = current.ConfigMap["mongodb.host"]
+		h.Config.MongoDB.User = current.ConfigMap["mongodb.usr"]
+		h.Config.MongoDB.Password = current.ConfigMap["mongodb.pwd"]
+		h.Config.MongoDB.Database = current.ConfigMap["mongodb.database"]
+		h.Config.MongoDB.Port = current.ConfigMap["mongodb.port"]
+		h.Config.MongoDB.MaxOpenConns = current.ConfigMap["mongodb.maxOpenConns"]
+		h.Config.MongoDB.MaxIdleConns = current.ConfigMap["mongodb.maxIDleConns"]
+
+		h.Config.AuthEnable = current.ConfigMap["auth.enable"] == "true"
+
+		h.Config.RateLimits = parseRateLimits(current.ConfigMap)
+		if h.Limiter != nil {
+			h.Limiter.SetLimits(h.Config.RateLimits)
+		}
+
+		if h.Routes != nil {
+			routes, err := v3.ParseRoutes(current.ConfigMap)
+			if err == nil {
+				err = h.Routes.Set(routes)
+			}
+			if err != nil {
+				blog.Errorf("update the routes failed, keep the current routes, err: %v", err)
+			}
+		}
+	}
+}
+
+// parseRateLimits read ratelimit.qps and ratelimit.burst as the default limit,
+// and ratelimit.{type}.qps and ratelimit.{type}.burst as the limit of the request type
+func parseRateLimits(configMap map[string]string) map[string]options.RateLimit {
+	limits := map[string]options.RateLimit{}
+	for _, kind := range []v3.RequestType{"", v3.TopoType, v3.HostType, v3.ProcType, v3.EventType} {
+		prefix := "ratelimit."
+		if kind != "" {
+			prefix += string(kind) + "."
+		}
+		qps, ok := configMap[prefix+"qps"]
+		if !ok {
+			continue
+		}
+		limit := options.RateLimit{}
+		var err error
+		if limit.QPS, err = strconv.ParseInt(qps, 10, 64); err != nil {
+			blog.Errorf("invalid %sqps %s, ignore it", prefix, qps)
+			continue
+		}
+		if burst, ok := configMap[prefix+"burst"]; ok {
+			if limit.Burst, err = strconv.ParseInt(burst, 10, 64); err != nil {
+				blog.Errorf("invalid %sburst %s, use the qps", prefix, burst)
+			}
+		}
+		limits[string(kind)] = limit
+	}
+	return limits
+}
 
+func (h *APIServer) getConfig() *options.Config {
+	h.configLock.Lock()
+	defer h.configLock.Unlock()
+	return h.Config
 }
 
 func newServerInfo(op *options.ServerOption) (*types.ServerInfo, error) {
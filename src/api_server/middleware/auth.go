@@ -1,31 +1,129 @@
 /*
  * Tencent is pleased to support the open source community by making 蓝鲸 available.
  * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
- * Licensed under the MIT License (the "License"); you may not use this file except 
+ * Licensed under the MIT License (the "License"); you may not use this file except
  * in compliance with the License. You may obtain a copy of the License at
  * http://opensource.org/licenses/MIT
  * Unless required by applicable law or agreed to in writing, software distributed under
  * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
- * either express or implied. See the License for the specific language governing permissions and 
+ * either express or implied. See the License for the specific language governing permissions and
  * limitations under the License.
  */
- 
+
+
 package middleware
 
 import (
+	"net/http"
+	"sync"
+	"time"
+
 	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage"
 )
 
-func BasicAuthenticate(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	u, p, ok := req.Request.BasicAuth()
-	if !ok || u != "admin" || p != "admin" {
-		resp.AddHeader("WWW-Authenticate", "Basic realm=Protected Area")
-		resp.WriteErrorString(401, "401: Not Authorized")
+// authAppAttribute the request attribute holding the app code authenticated
+const authAppAttribute = "cc_authenticated_app"
+
+// AuthenticatedApp the app code authenticated by the api key of the request,
+// it is empty if the authentication is disabled
+func AuthenticatedApp(req *restful.Request) string {
+	appCode, _ := req.Attribute(authAppAttribute).(string)
+	return appCode
+}
+
+// Authenticator check the api key carried by the requests, and make the requests
+// act as the user and supplier account of the key
+type Authenticator struct {
+	lock   sync.RWMutex
+	enable bool
+	db     storage.DI
+}
+
+// NewAuthenticator create an Authenticator which lets all the requests pass until it is enabled
+func NewAuthenticator() *Authenticator {
+	return &Authenticator{}
+}
+
+// SetConfig enable or disable the authentication, the api keys are read from db
+func (a *Authenticator) SetConfig(enable bool, db storage.DI) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.enable = enable
+	a.db = db
+}
+
+func (a *Authenticator) getConfig() (bool, storage.DI) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.enable, a.db
+}
+
+// Filter the restful filter checks the app code and secret headers of the requests, the secret
+// is never forwarded, and the user and supplier account headers are set by the api key
+func (a *Authenticator) Filter(errFunc func() errors.CCErrorIf) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		enable, db := a.getConfig()
+		if !enable {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		header := req.Request.Header
+		defErr := errFunc().CreateDefaultCCErrorIf(util.GetLanguage(header))
+		appCode := header.Get(common.BKHTTPHeaderAppCode)
+		secret := header.Get(common.BKHTTPHeaderAppSecret)
+		header.Del(common.BKHTTPHeaderAppSecret)
+		if appCode == "" || secret == "" {
+			resp.WriteError(http.StatusUnauthorized, &metadata.RespError{Msg: defErr.Error(common.CCErrAPIServerAuthRequired)})
+			return
+		}
+		if db == nil {
+			blog.Errorf("authenticate app %s failed, the db is not ready", appCode)
+			resp.WriteError(http.StatusServiceUnavailable, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommResourceInitFailed, "mongodb")})
+			return
+		}
+
+		key, errCode, err := authenticate(db, appCode, secret)
+		if err != nil {
+			blog.Errorf("authenticate app %s failed, err: %v", appCode, err)
+			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+			return
+		}
+		if errCode != common.CCSuccess {
+			blog.Warnf("authenticate app %s from %s failed, code: %d", appCode, req.Request.RemoteAddr, errCode)
+			resp.WriteError(http.StatusUnauthorized, &metadata.RespError{Msg: defErr.Error(errCode)})
+			return
+		}
+
+		header.Set(common.BKHTTPHeaderUser, key.GetUser())
+		header.Set(common.BKHTTPOwnerID, key.OwnerID)
+		req.SetAttribute(authAppAttribute, appCode)
 		chain.ProcessFilter(req, resp)
 	}
-	chain.ProcessFilter(req, resp)
 }
 
-func ValidAuth() {
-
+// authenticate find the api key of the app matches the secret, the error code tells why there is none
+func authenticate(db storage.DI, appCode, secret string) (*metadata.APIKey, int, error) {
+	keys := []metadata.APIKey{}
+	condition := map[string]interface{}{common.BKAppCodeField: appCode}
+	if err := db.GetMutilByCondition(common.BKTableNameAPIKey, nil, condition, &keys, "", 0, 0); err != nil {
+		return nil, common.CCSuccess, err
+	}
+	for idx := range keys {
+		if !keys[idx].MatchSecret(secret) {
+			continue
+		}
+		if !keys[idx].IsValid(time.Now()) {
+			return nil, common.CCErrAPIServerAuthExpired, nil
+		}
+		return &keys[idx], common.CCSuccess, nil
+	}
+	return nil, common.CCErrAPIServerAuthFailed, nil
 }
@@ -0,0 +1,99 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+func newAuthContainer(auth *Authenticator, forwarded *http.Header, authenticated *string) *restful.Container {
+	ccErr := errors.NewFromCtx(map[string]errors.ErrorCode{})
+	ws := new(restful.WebService)
+	ws.Path("/api/v3").Filter(auth.Filter(func() errors.CCErrorIf { return ccErr }))
+	ws.Route(ws.GET("{.*}").To(func(req *restful.Request, resp *restful.Response) {
+		*forwarded = req.Request.Header
+		*authenticated = AuthenticatedApp(req)
+		resp.WriteHeader(http.StatusOK)
+	}))
+	container := restful.NewContainer()
+	container.Router(restful.CurlyRouter{})
+	container.Add(ws)
+	return container
+}
+
+func addAPIKey(t *testing.T, db *memclient.MemCli, id int64, secret string, enabled bool, expire *time.Time) {
+	key := metadata.APIKey{KeyID: id, AppCode: "app", OwnerID: "owner", User: "robot", Enabled: enabled, ExpireTime: expire}
+	require.NoError(t, key.SetSecret(secret))
+	_, err := db.Insert(common.BKTableNameAPIKey, key)
+	require.NoError(t, err)
+}
+
+func TestAuthenticator(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	past := time.Now().Add(-time.Hour)
+	addAPIKey(t, db, 1, "secret", true, nil)
+	addAPIKey(t, db, 2, "revoked", false, nil)
+	addAPIKey(t, db, 3, "expired", true, &past)
+
+	auth := NewAuthenticator()
+	forwarded := http.Header{}
+	authenticated := ""
+	container := newAuthContainer(auth, &forwarded, &authenticated)
+	do := func(appCode, secret string) int {
+		forwarded = http.Header{}
+		req := httptest.NewRequest(http.MethodGet, "/api/v3/biz/search", nil)
+		req.Header.Set(common.BKHTTPHeaderUser, "someone")
+		req.Header.Set(common.BKHTTPOwnerID, "other")
+		if appCode != "" {
+			req.Header.Set(common.BKHTTPHeaderAppCode, appCode)
+		}
+		if secret != "" {
+			req.Header.Set(common.BKHTTPHeaderAppSecret, secret)
+		}
+		rec := httptest.NewRecorder()
+		container.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// disabled, every request passes
+	assert.Equal(t, http.StatusOK, do("", ""))
+	assert.Equal(t, "someone", forwarded.Get(common.BKHTTPHeaderUser))
+	assert.Empty(t, authenticated)
+
+	auth.SetConfig(true, db)
+	assert.Equal(t, http.StatusUnauthorized, do("", ""))
+	assert.Equal(t, http.StatusUnauthorized, do("app", ""))
+	assert.Equal(t, http.StatusUnauthorized, do("app", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, do("other", "secret"))
+	assert.Equal(t, http.StatusUnauthorized, do("app", "revoked"))
+	assert.Equal(t, http.StatusUnauthorized, do("app", "expired"))
+
+	require.Equal(t, http.StatusOK, do("app", "secret"))
+	assert.Equal(t, "robot", forwarded.Get(common.BKHTTPHeaderUser))
+	assert.Equal(t, "owner", forwarded.Get(common.BKHTTPOwnerID))
+	assert.Equal(t, "app", forwarded.Get(common.BKHTTPHeaderAppCode))
+	assert.Empty(t, forwarded.Get(common.BKHTTPHeaderAppSecret))
+	assert.Equal(t, "app", authenticated)
+}
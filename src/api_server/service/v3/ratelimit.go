@@ -0,0 +1,219 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v3
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/api_server/app/options"
+	"configcenter/src/api_server/middleware"
+	"configcenter/src/apimachinery/flowctrl"
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/metric"
+	"configcenter/src/common/util"
+)
+
+const (
+	// the buckets of the callers idle longer than this are dropped
+	rateLimitIdleTimeout = 10 * time.Minute
+	rateLimitSweepPeriod = time.Minute
+)
+
+var rateLimitTypes = []RequestType{TopoType, HostType, ProcType, EventType}
+
+// RateLimiter limit the requests of each caller to each type of the backends
+type RateLimiter struct {
+	lock      sync.Mutex
+	limits    map[string]options.RateLimit
+	buckets   map[string]*callerBucket
+	counters  map[RequestType]*rateLimitCounter
+	lastSweep time.Time
+}
+
+type callerBucket struct {
+	limiter  flowctrl.RateLimiter
+	lastSeen time.Time
+}
+
+type rateLimitCounter struct {
+	accepted int64
+	rejected int64
+}
+
+// NewRateLimiter create a RateLimiter which limits nothing until the limits are set
+func NewRateLimiter() *RateLimiter {
+	counters := make(map[RequestType]*rateLimitCounter)
+	for _, kind := range rateLimitTypes {
+		counters[kind] = new(rateLimitCounter)
+	}
+	return &RateLimiter{
+		limits:    map[string]options.RateLimit{},
+		buckets:   map[string]*callerBucket{},
+		counters:  counters,
+		lastSweep: time.Now(),
+	}
+}
+
+// SetLimits replace the limits, the callers start with full buckets
+func (r *RateLimiter) SetLimits(limits map[string]options.RateLimit) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.limits = limits
+	r.buckets = map[string]*callerBucket{}
+}
+
+func (r *RateLimiter) getLimit(kind RequestType) options.RateLimit {
+	if limit, ok := r.limits[string(kind)]; ok {
+		return limit
+	}
+	return r.limits[""]
+}
+
+// Allow take a token of the caller for the type, return how long to wait when there is none
+func (r *RateLimiter) Allow(kind RequestType, caller string) (bool, time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	r.sweep(now)
+	counter, ok := r.counters[kind]
+	if !ok {
+		counter = new(rateLimitCounter)
+		r.counters[kind] = counter
+	}
+
+	limit := r.getLimit(kind)
+	if limit.QPS <= 0 {
+		counter.accepted++
+		return true, 0
+	}
+
+	key := string(kind) + ":" + caller
+	bucket, ok := r.buckets[key]
+	if !ok {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = limit.QPS
+		}
+		bucket = &callerBucket{limiter: flowctrl.NewRateLimiter(limit.QPS, burst)}
+		r.buckets[key] = bucket
+	}
+	bucket.lastSeen = now
+
+	if !bucket.limiter.TryAccept() {
+		counter.rejected++
+		return false, time.Duration(float64(time.Second) / float64(limit.QPS))
+	}
+	counter.accepted++
+	return true, 0
+}
+
+func (r *RateLimiter) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < rateLimitSweepPeriod {
+		return
+	}
+	r.lastSweep = now
+	for key, bucket := range r.buckets {
+		if now.Sub(bucket.lastSeen) > rateLimitIdleTimeout {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// Collect the accepted and rejected requests of each type, and the callers being limited
+func (r *RateLimiter) Collect() []metric.MetricInterf {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	metrics := []metric.MetricInterf{}
+	for kind, counter := range r.counters {
+		metrics = append(metrics,
+			&rateLimitMetric{
+				name:  fmt.Sprintf("ratelimit_%s_accepted_total", kind),
+				help:  fmt.Sprintf("Number of the %s requests accepted by the rate limiter.", kind),
+				value: counter.accepted,
+			},
+			&rateLimitMetric{
+				name:  fmt.Sprintf("ratelimit_%s_rejected_total", kind),
+				help:  fmt.Sprintf("Number of the %s requests rejected by the rate limiter.", kind),
+				value: counter.rejected,
+			})
+	}
+	metrics = append(metrics, &rateLimitMetric{
+		name:  "ratelimit_callers",
+		help:  "Number of the callers being limited.",
+		value: int64(len(r.buckets)),
+	})
+	return metrics
+}
+
+type rateLimitMetric struct {
+	name  string
+	help  string
+	value int64
+}
+
+func (m *rateLimitMetric) GetMeta() metric.MetricMeta {
+	return metric.MetricMeta{Name: m.name, Help: m.help}
+}
+
+func (m *rateLimitMetric) GetValue() (*metric.FloatOrString, error) {
+	return metric.FormFloatOrString(m.value)
+}
+
+func (m *rateLimitMetric) GetExtension() (*metric.MetricExtension, error) {
+	return nil, nil
+}
+
+// getCaller the app authenticated by the api key of the request, or the source ip when the
+// authentication is disabled, the app code and user headers are not trusted since they are set
+// by the callers
+func getCaller(req *restful.Request) string {
+	if appCode := middleware.AuthenticatedApp(req); appCode != "" {
+		return "app:" + appCode
+	}
+	ip, _, err := net.SplitHostPort(req.Request.RemoteAddr)
+	if err != nil {
+		ip = req.Request.RemoteAddr
+	}
+	return "ip:" + ip
+}
+
+// rateLimit write 429 and return false when the caller sends too many requests of the type
+func (s *Service) rateLimit(kind RequestType, req *restful.Request, resp *restful.Response) bool {
+	if s.Limiter == nil {
+		return true
+	}
+	caller := getCaller(req)
+	ok, wait := s.Limiter.Allow(kind, caller)
+	if ok {
+		return true
+	}
+	retryAfter := int64(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	resp.AddHeader("Retry-After", strconv.FormatInt(retryAfter, 10))
+	defErr := s.Engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(req.Request.Header))
+	resp.WriteError(http.StatusTooManyRequests, &metadata.RespError{Msg: defErr.Error(common.CCErrAPIServerTooManyRequests)})
+	return false
+}
@@ -0,0 +1,100 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/api_server/app/options"
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/errors"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter()
+	for i := 0; i < 10; i++ {
+		ok, _ := limiter.Allow(TopoType, "ip:127.0.0.1")
+		require.True(t, ok)
+	}
+
+	limiter.SetLimits(map[string]options.RateLimit{
+		"":               {QPS: 1, Burst: 2},
+		string(HostType): {QPS: 0},
+	})
+	for i := 0; i < 2; i++ {
+		ok, _ := limiter.Allow(TopoType, "app:a")
+		require.True(t, ok)
+	}
+	ok, wait := limiter.Allow(TopoType, "app:a")
+	assert.False(t, ok)
+	assert.True(t, wait > 0)
+
+	// the callers and the types are limited separately
+	ok, _ = limiter.Allow(TopoType, "app:b")
+	assert.True(t, ok)
+	ok, _ = limiter.Allow(ProcType, "app:a")
+	assert.True(t, ok)
+	for i := 0; i < 10; i++ {
+		ok, _ = limiter.Allow(HostType, "app:a")
+		assert.True(t, ok)
+	}
+
+	values := map[string]int64{}
+	for _, m := range limiter.Collect() {
+		values[m.GetMeta().Name] = m.(*rateLimitMetric).value
+	}
+	assert.EqualValues(t, 13, values["ratelimit_topo_accepted_total"])
+	assert.EqualValues(t, 1, values["ratelimit_topo_rejected_total"])
+	assert.EqualValues(t, 10, values["ratelimit_host_accepted_total"])
+	assert.EqualValues(t, 3, values["ratelimit_callers"])
+}
+
+func TestRateLimitResponse(t *testing.T) {
+	s := &Service{
+		Engine:  &backbone.Engine{CCErr: errors.NewFromCtx(map[string]errors.ErrorCode{})},
+		Limiter: NewRateLimiter(),
+	}
+	s.Limiter.SetLimits(map[string]options.RateLimit{"": {QPS: 1, Burst: 1}})
+
+	ws := new(restful.WebService)
+	ws.Route(ws.GET("/api/v3/biz/search").To(func(req *restful.Request, resp *restful.Response) {
+		if s.rateLimit(TopoType, req, resp) {
+			resp.WriteHeader(http.StatusOK)
+		}
+	}))
+	container := restful.NewContainer()
+	container.Add(ws)
+
+	// the user header is not trusted, the callers are told by the source ip
+	do := func(ip, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v3/biz/search", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set(common.BKHTTPHeaderUser, user)
+		rec := httptest.NewRecorder()
+		container.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, do("10.0.0.1", "a").Code)
+	rec := do("10.0.0.1", "b")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, do("10.0.0.2", "a").Code)
+}
@@ -18,9 +18,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/emicklei/go-restful"
 
+	"configcenter/src/api_server/middleware"
 	"configcenter/src/apimachinery/discovery"
 	"configcenter/src/common"
 	"configcenter/src/common/backbone"
@@ -37,9 +39,15 @@ type HttpClient interface {
 }
 
 type Service struct {
-	Engine *backbone.Engine
-	Client HttpClient
-	Disc   discovery.DiscoveryInterface
+	Engine  *backbone.Engine
+	Client  HttpClient
+	Disc    discovery.DiscoveryInterface
+	Auth    *middleware.Authenticator
+	Limiter *RateLimiter
+	Routes  *RouteTable
+
+	metricOnce    sync.Once
+	metricHandler http.HandlerFunc
 }
 
 const (
@@ -53,6 +61,7 @@ func (s *Service) V3WebService() *restful.WebService {
 	}
 	ws.Path(rootPath).
 		Filter(rdapi.AllGlobalFilter(getErrFun)).
+		Filter(s.Auth.Filter(getErrFun)).
 		Produces(restful.MIME_JSON)
 	ws.Route(ws.GET("{.*}").Filter(s.URLFilterChan).To(s.Get))
 	ws.Route(ws.POST("{.*}").Filter(s.URLFilterChan).To(s.Post))
@@ -134,7 +143,7 @@ func (s *Service) Do(req *restful.Request, resp *restful.Response) {
 func (s *Service) URLFilterChan(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
 	var kind RequestType
 	var err error
-	kind, err = V3URLPath(req.Request.RequestURI).FilterChain(req)
+	kind, err = s.Routes.Route(req.Request)
 	if err != nil {
 		blog.Errorf("rewrite request url[%s] failed, err: %v", req.Request.RequestURI, err)
 		if err := resp.WriteError(http.StatusBadGateway, &metadata.RespError{
@@ -148,6 +157,10 @@ func (s *Service) URLFilterChan(req *restful.Request, resp *restful.Response, ch
 		return
 	}
 
+	if !s.rateLimit(kind, req, resp) {
+		return
+	}
+
 	defer func() {
 		if err != nil {
 			blog.Errorf("proxy request url[%s] failed, err: %v", req.Request.RequestURI, err)
@@ -202,12 +215,69 @@ func (s *Service) V3Healthz() *restful.WebService {
 	ws.Filter(rdapi.AllGlobalFilter(getErrFun)).Produces(restful.MIME_JSON)
 
 	ws.Route(ws.GET("healthz").To(s.healthz))
+	ws.Route(ws.GET("metrics").To(s.metrics))
+	ws.Route(ws.GET("routes").To(s.routes))
 
 	return ws
 
 }
 
 func (s *Service) healthz(req *restful.Request, resp *restful.Response) {
+	meta := s.healthMeta()
+
+	info := metric.HealthInfo{
+		Module:     types.CC_MODULE_APISERVER,
+		HealthMeta: meta,
+		AtTime:     types.Now(),
+	}
+
+	answer := metric.HealthResponse{
+		Code:    common.CCSuccess,
+		Data:    info,
+		OK:      meta.IsHealthy,
+		Result:  meta.IsHealthy,
+		Message: meta.Message,
+	}
+	resp.WriteJson(answer, "application/json")
+}
+
+func (s *Service) metrics(req *restful.Request, resp *restful.Response) {
+	s.metricOnce.Do(func() {
+		conf := metric.Config{
+			ModuleName:    types.CC_MODULE_APISERVER,
+			ServerAddress: req.Request.Host,
+		}
+		collectors := []*metric.Collector{}
+		if s.Limiter != nil {
+			collectors = append(collectors, metric.NewCollector("ratelimit", s.Limiter))
+		}
+		for _, action := range metric.NewMetricController(conf, s.healthMeta, collectors...) {
+			if action.Path == "/metrics" {
+				s.metricHandler = action.HandlerFunc
+			}
+		}
+	})
+	s.metricHandler(resp.ResponseWriter, req.Request)
+}
+
+// routes dump the effective routes, and how the path given by the query is routed
+func (s *Service) routes(req *restful.Request, resp *restful.Response) {
+	result := RouteDump{Routes: s.Routes.Routes()}
+	if path := req.QueryParameter("path"); path != "" {
+		resolved := &RouteResolved{Path: path}
+		if route, ok := s.Routes.Match(path); ok {
+			resolved.Route = route.Name
+			resolved.Target = route.Target
+			resolved.Rewrited = route.rewrite(path)
+		} else {
+			resolved.Target = UnknownType
+		}
+		result.Resolved = resolved
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *Service) healthMeta() metric.HealthMeta {
 	meta := metric.HealthMeta{IsHealthy: true}
 
 	// zk health status
@@ -249,19 +319,5 @@ func (s *Service) healthz(req *restful.Request, resp *restful.Response) {
 			break
 		}
 	}
-
-	info := metric.HealthInfo{
-		Module:     types.CC_MODULE_APISERVER,
-		HealthMeta: meta,
-		AtTime:     types.Now(),
-	}
-
-	answer := metric.HealthResponse{
-		Code:    common.CCSuccess,
-		Data:    info,
-		OK:      meta.IsHealthy,
-		Result:  meta.IsHealthy,
-		Message: meta.Message,
-	}
-	resp.WriteJson(answer, "application/json")
+	return meta
 }
@@ -14,9 +14,13 @@ package v3
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
-
-	"github.com/emicklei/go-restful"
+	"sync"
 )
 
 type RequestType string
@@ -29,152 +33,274 @@ const (
 	EventType   RequestType = "event"
 )
 
-type V3URLPath string
-
-func (u V3URLPath) FilterChain(req *restful.Request) (RequestType, error) {
-	switch {
-	case u.WithTopo(req):
-		return TopoType, nil
-	case u.WithHost(req):
-		return HostType, nil
-	case u.WithProc(req):
-		return ProcType, nil
-	case u.WithEvent(req):
-		return EventType, nil
-	default:
-		return UnknownType, errors.New("unknown requested with backend process")
-	}
+// the roots of the backends, the path under rootPath is moved under them when a route has no rewrite
+var targetRoots = map[RequestType]string{
+	TopoType:  "/topo/v3",
+	HostType:  "/host/v3",
+	ProcType:  "/process/v3",
+	EventType: "/event/v3",
 }
 
-func (u *V3URLPath) WithTopo(req *restful.Request) (isHit bool) {
-	topoRoot := "/topo/v3"
-	from, to := rootPath, topoRoot
-	switch {
-	case strings.HasPrefix(string(*u), rootPath+"/audit/"):
-		from, to, isHit = rootPath, topoRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/biz/"):
-		from, to, isHit = rootPath+"/biz", topoRoot+"/app", true
-
-	case strings.HasPrefix(string(*u), rootPath+"/topo/"):
-		from, to, isHit = rootPath, topoRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/identifier/"):
-		from, to, isHit = rootPath, topoRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/inst/"):
-		from, to, isHit = rootPath, topoRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/module/"):
-		from, to, isHit = rootPath, topoRoot, true
-
-		// Attention:
-		// do not change the check sequences.
-	case string(*u) == rootPath+"/object":
-		from, to, isHit = rootPath, topoRoot, true
-
-	case string(*u) == rootPath+"/objects":
-		from, to, isHit = rootPath, topoRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/object/attr"):
-		from, to, isHit = rootPath+"/object/attr", topoRoot+"/objectattr", true
-
-	case strings.HasPrefix(string(*u), rootPath+"/object/"):
-		from, to, isHit = rootPath, topoRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/objects/"):
-		from, to, isHit = rootPath, topoRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/objectatt/"):
-		from, to, isHit = rootPath, topoRoot, true
+// the match types of the routes
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+	MatchRegex  = "regex"
+)
 
-	case strings.HasPrefix(string(*u), rootPath+"/set/"):
-		from, to, isHit = rootPath, topoRoot, true
+// Route route the requests matched to the target, and rewrite the path by replacing the
+// From prefix with To. The path of a regex route is rewrited by expanding To with the groups.
+type Route struct {
+	Name   string      `json:"name"`
+	Match  string      `json:"match"`
+	Path   string      `json:"path"`
+	Target RequestType `json:"target"`
+	From   string      `json:"from,omitempty"`
+	To     string      `json:"to"`
+
+	regex *regexp.Regexp
+}
 
+func (r *Route) compile() error {
+	if _, ok := targetRoots[r.Target]; !ok {
+		return fmt.Errorf("route %s has unknown target %s", r.Name, r.Target)
+	}
+	switch r.Match {
+	case MatchExact, MatchPrefix:
+		if r.From == "" && r.To == "" {
+			r.From, r.To = rootPath, targetRoots[r.Target]
+		}
+		if !strings.HasPrefix(r.Path, r.From) {
+			return fmt.Errorf("route %s rewrites %s, but the path %s is not under it", r.Name, r.From, r.Path)
+		}
+	case MatchRegex:
+		regex, err := regexp.Compile(r.Path)
+		if err != nil {
+			return fmt.Errorf("route %s has invalid regex %s, err: %v", r.Name, r.Path, err)
+		}
+		r.regex = regex
+		r.From = ""
+		if r.To == "" {
+			return fmt.Errorf("route %s has no rewrite", r.Name)
+		}
 	default:
-		isHit = false
+		return fmt.Errorf("route %s has unknown match type %s", r.Name, r.Match)
 	}
+	return nil
+}
 
-	if isHit {
-		u.revise(req, from, to)
-		return true
+func (r *Route) rewrite(path string) string {
+	if r.Match == MatchRegex {
+		return r.regex.ReplaceAllString(path, r.To)
 	}
-	return false
+	return r.To + path[len(r.From):]
 }
 
-func (u *V3URLPath) WithHost(req *restful.Request) (isHit bool) {
-	hostRoot := "/host/v3"
-	from, to := rootPath, hostRoot
-
-	switch {
-	case strings.HasPrefix(string(*u), rootPath+"/host/"):
-		from, to, isHit = rootPath, hostRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/hosts/"):
-		from, to, isHit = rootPath, hostRoot, true
-
-	case string(*u) == (rootPath + "/userapi"):
-		from, to, isHit = rootPath, hostRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/userapi/"):
-		from, to, isHit = rootPath, hostRoot, true
-
-	case string(*u) == (rootPath + "/usercustom"):
-		from, to, isHit = rootPath, hostRoot, true
-
-	case strings.HasPrefix(string(*u), rootPath+"/usercustom/"):
-		from, to, isHit = rootPath, hostRoot, true
-
-	default:
-		isHit = false
+// rewriteEscaped rewrite the escaped path, so that the escaped characters such as %2F are kept,
+// the path is the unescaped one which the route is matched with
+func (r *Route) rewriteEscaped(path, escaped string) string {
+	if r.Match == MatchRegex {
+		if r.regex.MatchString(escaped) {
+			return r.regex.ReplaceAllString(escaped, r.To)
+		}
+		return (&url.URL{Path: r.rewrite(path)}).EscapedPath()
 	}
+	return r.To + skipEscaped(escaped, len(r.From))
+}
 
-	if isHit {
-		u.revise(req, from, to)
-		return true
+// skipEscaped returns the escaped path after the first n unescaped bytes
+func skipEscaped(escaped string, n int) string {
+	idx := 0
+	for ; n > 0 && idx < len(escaped); n-- {
+		if escaped[idx] == '%' && idx+2 < len(escaped) {
+			idx += 3
+			continue
+		}
+		idx++
 	}
-	return false
+	return escaped[idx:]
 }
 
-func (u *V3URLPath) WithEvent(req *restful.Request) (isHit bool) {
-	eventRoot := "/event/v3"
-	from, to := rootPath, eventRoot
+// RouteTable the routes of the v3 proxy. The exact routes are matched first, then the prefix
+// routes with the longest path, then the regex routes in the order of their names, so the
+// order the routes are defined in does not matter.
+type RouteTable struct {
+	lock     sync.RWMutex
+	routes   []Route
+	exacts   map[string]*Route
+	prefixes []*Route
+	regexes  []*Route
+}
 
-	switch {
-	case strings.HasPrefix(string(*u), rootPath+"/event/"):
-		from, to, isHit = rootPath+"/event", eventRoot, true
+// NewRouteTable create a RouteTable with the default routes
+func NewRouteTable() *RouteTable {
+	table := new(RouteTable)
+	if err := table.Set(nil); err != nil {
+		panic(err)
+	}
+	return table
+}
 
-	default:
-		isHit = false
+// Set replace the routes by the default routes and the routes given, the routes given
+// replace the default routes with the same name. Nothing changes when any route is invalid.
+func (t *RouteTable) Set(routes []Route) error {
+	merged := map[string]Route{}
+	for _, route := range defaultRoutes {
+		merged[route.Name] = route
+	}
+	for _, route := range routes {
+		merged[route.Name] = route
 	}
 
-	if isHit {
-		u.revise(req, from, to)
-		return true
+	all := make([]Route, 0, len(merged))
+	exacts := map[string]*Route{}
+	prefixes := []*Route{}
+	regexes := []*Route{}
+	for _, route := range merged {
+		all = append(all, route)
 	}
-	return false
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	for idx := range all {
+		route := &all[idx]
+		if err := route.compile(); err != nil {
+			return err
+		}
+		switch route.Match {
+		case MatchExact:
+			if exist, ok := exacts[route.Path]; ok {
+				return fmt.Errorf("route %s and %s have the same path %s", exist.Name, route.Name, route.Path)
+			}
+			exacts[route.Path] = route
+		case MatchPrefix:
+			prefixes = append(prefixes, route)
+		case MatchRegex:
+			regexes = append(regexes, route)
+		}
+	}
+	sort.SliceStable(prefixes, func(i, j int) bool { return len(prefixes[i].Path) > len(prefixes[j].Path) })
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.routes, t.exacts, t.prefixes, t.regexes = all, exacts, prefixes, regexes
+	return nil
 }
 
-func (u *V3URLPath) WithProc(req *restful.Request) (isHit bool) {
-	procRoot := "/process/v3"
-	from, to := rootPath, procRoot
+// Routes return the effective routes in the order of their names
+func (t *RouteTable) Routes() []Route {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return append([]Route{}, t.routes...)
+}
 
-	switch {
-	case strings.HasPrefix(string(*u), rootPath+"/proc/"):
-		from, to, isHit = rootPath+"/proc", procRoot, true
+// Match find the route of the path
+func (t *RouteTable) Match(path string) (Route, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if route, ok := t.exacts[path]; ok {
+		return *route, true
+	}
+	for _, route := range t.prefixes {
+		if strings.HasPrefix(path, route.Path) {
+			return *route, true
+		}
+	}
+	for _, route := range t.regexes {
+		if route.regex.MatchString(path) {
+			return *route, true
+		}
+	}
+	return Route{}, false
+}
 
-	default:
-		isHit = false
+// Route rewrite the request to the backend, and return its target. The route is matched with
+// the unescaped path, and the escaped path is rewritten, so that an escaped / in a path segment
+// is not turned into a separator
+func (t *RouteTable) Route(req *http.Request) (RequestType, error) {
+	route, ok := t.Match(req.URL.Path)
+	if !ok {
+		return UnknownType, errors.New("unknown requested with backend process")
 	}
+	escaped := route.rewriteEscaped(req.URL.Path, req.URL.EscapedPath())
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return UnknownType, err
+	}
+	req.URL.Path, req.URL.RawPath = path, escaped
+	req.RequestURI = req.URL.RequestURI()
+	return route.Target, nil
+}
 
-	if isHit {
-		u.revise(req, from, to)
-		return true
+// ParseRoutes parse the routes from the config, each route is a line in the route section:
+//
+//	name = match path target [from to]
+//
+// the path under /api/v3 is moved under the root of the target when from and to are omitted,
+// and the regex routes have the template to expand instead of them:
+//
+//	name = regex pattern target template
+func ParseRoutes(configMap map[string]string) ([]Route, error) {
+	routes := []Route{}
+	for key, value := range configMap {
+		if !strings.HasPrefix(key, "route.") {
+			continue
+		}
+		route := Route{Name: strings.TrimPrefix(key, "route.")}
+		fields := strings.Fields(value)
+		switch {
+		case len(fields) == 3:
+		case len(fields) == 4 && fields[0] == MatchRegex:
+			route.To = fields[3]
+		case len(fields) == 5 && fields[0] != MatchRegex:
+			route.From, route.To = fields[3], fields[4]
+		default:
+			return nil, fmt.Errorf("invalid route %s: %s", route.Name, value)
+		}
+		route.Match, route.Path, route.Target = fields[0], fields[1], RequestType(fields[2])
+		if err := route.compile(); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
 	}
-	return false
+	return routes, nil
+}
+
+// RouteDump the effective routes, and how a path is routed when it is given
+type RouteDump struct {
+	Routes   []Route        `json:"routes"`
+	Resolved *RouteResolved `json:"resolved,omitempty"`
+}
+
+// RouteResolved how a path is routed
+type RouteResolved struct {
+	Path     string      `json:"path"`
+	Route    string      `json:"route"`
+	Target   RequestType `json:"target"`
+	Rewrited string      `json:"rewrited"`
 }
 
-func (u V3URLPath) revise(req *restful.Request, from, to string) {
-	req.Request.RequestURI = to + req.Request.RequestURI[len(from):]
-	req.Request.URL.Path = to + req.Request.URL.Path[len(from):]
+var defaultRoutes = []Route{
+	{Name: "topo.audit", Match: MatchPrefix, Path: rootPath + "/audit/", Target: TopoType},
+	{Name: "topo.biz", Match: MatchPrefix, Path: rootPath + "/biz/", Target: TopoType, From: rootPath + "/biz", To: "/topo/v3/app"},
+	{Name: "topo.topo", Match: MatchPrefix, Path: rootPath + "/topo/", Target: TopoType},
+	{Name: "topo.identifier", Match: MatchPrefix, Path: rootPath + "/identifier/", Target: TopoType},
+	{Name: "topo.inst", Match: MatchPrefix, Path: rootPath + "/inst/", Target: TopoType},
+	{Name: "topo.module", Match: MatchPrefix, Path: rootPath + "/module/", Target: TopoType},
+	{Name: "topo.object", Match: MatchExact, Path: rootPath + "/object", Target: TopoType},
+	{Name: "topo.objects", Match: MatchExact, Path: rootPath + "/objects", Target: TopoType},
+	{Name: "topo.objectattr", Match: MatchPrefix, Path: rootPath + "/object/attr", Target: TopoType, From: rootPath + "/object/attr", To: "/topo/v3/objectattr"},
+	{Name: "topo.object.sub", Match: MatchPrefix, Path: rootPath + "/object/", Target: TopoType},
+	{Name: "topo.objects.sub", Match: MatchPrefix, Path: rootPath + "/objects/", Target: TopoType},
+	{Name: "topo.objectatt", Match: MatchPrefix, Path: rootPath + "/objectatt/", Target: TopoType},
+	{Name: "topo.set", Match: MatchPrefix, Path: rootPath + "/set/", Target: TopoType},
+
+	{Name: "host.host", Match: MatchPrefix, Path: rootPath + "/host/", Target: HostType},
+	{Name: "host.hosts", Match: MatchPrefix, Path: rootPath + "/hosts/", Target: HostType},
+	{Name: "host.userapi", Match: MatchExact, Path: rootPath + "/userapi", Target: HostType},
+	{Name: "host.userapi.sub", Match: MatchPrefix, Path: rootPath + "/userapi/", Target: HostType},
+	{Name: "host.usercustom", Match: MatchExact, Path: rootPath + "/usercustom", Target: HostType},
+	{Name: "host.usercustom.sub", Match: MatchPrefix, Path: rootPath + "/usercustom/", Target: HostType},
+
+	{Name: "proc.proc", Match: MatchPrefix, Path: rootPath + "/proc/", Target: ProcType, From: rootPath + "/proc", To: "/process/v3"},
+
+	{Name: "event.event", Match: MatchPrefix, Path: rootPath + "/event/", Target: EventType, From: rootPath + "/event", To: "/event/v3"},
 }
@@ -0,0 +1,139 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v3
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func assertRoutes(t *testing.T, table *RouteTable, cases []struct {
+	url    string
+	target RequestType
+	uri    string
+}) {
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", c.url, nil)
+		kind, err := table.Route(req)
+		if c.target == UnknownType {
+			if err == nil {
+				t.Errorf("%s: routed to %s %s, want unknown", c.url, kind, req.RequestURI)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: route failed, err: %v", c.url, err)
+			continue
+		}
+		if kind != c.target || req.RequestURI != c.uri {
+			t.Errorf("%s: routed to %s %s, want %s %s", c.url, kind, req.RequestURI, c.target, c.uri)
+		}
+	}
+}
+
+func TestDefaultRoutes(t *testing.T) {
+	assertRoutes(t, NewRouteTable(), []struct {
+		url    string
+		target RequestType
+		uri    string
+	}{
+		{"/api/v3/biz/search/0", TopoType, "/topo/v3/app/search/0"},
+		{"/api/v3/audit/search", TopoType, "/topo/v3/audit/search"},
+		{"/api/v3/topo/inst/0/2", TopoType, "/topo/v3/topo/inst/0/2"},
+		{"/api/v3/inst/search/0/set", TopoType, "/topo/v3/inst/search/0/set"},
+		{"/api/v3/module/2/3", TopoType, "/topo/v3/module/2/3"},
+		{"/api/v3/set/2", TopoType, "/topo/v3/set/2"},
+		{"/api/v3/object", TopoType, "/topo/v3/object"},
+		{"/api/v3/objects", TopoType, "/topo/v3/objects"},
+		{"/api/v3/object/search", TopoType, "/topo/v3/object/search"},
+		{"/api/v3/object/attr/search", TopoType, "/topo/v3/objectattr/search"},
+		{"/api/v3/objects/topo", TopoType, "/topo/v3/objects/topo"},
+		{"/api/v3/identifier/host/search", TopoType, "/topo/v3/identifier/host/search"},
+		{"/api/v3/hosts/search?page=1", HostType, "/host/v3/hosts/search?page=1"},
+		{"/api/v3/host/add", HostType, "/host/v3/host/add"},
+		{"/api/v3/userapi", HostType, "/host/v3/userapi"},
+		{"/api/v3/userapi/search/2", HostType, "/host/v3/userapi/search/2"},
+		{"/api/v3/usercustom", HostType, "/host/v3/usercustom"},
+		{"/api/v3/proc/search/0/2", ProcType, "/process/v3/search/0/2"},
+		{"/api/v3/event/subscribe/search/0/2", EventType, "/event/v3/subscribe/search/0/2"},
+		{"/api/v3/unknown/search", UnknownType, ""},
+		{"/api/v3/bizz", UnknownType, ""},
+	})
+}
+
+func TestConfiguredRoutes(t *testing.T) {
+	routes, err := ParseRoutes(map[string]string{
+		"route.topo.biz":     "prefix /api/v3/biz/ topo /api/v3/biz /topo/v3/biz",
+		"route.host.dynamic": "prefix /api/v3/dynamic/ host",
+		"route.proc.regex":   `regex ^/api/v3/process/(\w+)/(.*)$ proc /process/v3/$1/$2`,
+		"mongodb.host":       "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("parse routes failed, err: %v", err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("parse %d routes, want 3", len(routes))
+	}
+
+	table := NewRouteTable()
+	if err := table.Set(routes); err != nil {
+		t.Fatalf("set routes failed, err: %v", err)
+	}
+	assertRoutes(t, table, []struct {
+		url    string
+		target RequestType
+		uri    string
+	}{
+		{"/api/v3/biz/search/0", TopoType, "/topo/v3/biz/search/0"},
+		{"/api/v3/dynamic/search", HostType, "/host/v3/dynamic/search"},
+		{"/api/v3/process/search/0/2", ProcType, "/process/v3/search/0/2"},
+		{"/api/v3/host/add", HostType, "/host/v3/host/add"},
+		// the escaped characters are kept
+		{"/api/v3/biz/search/a%2Fb", TopoType, "/topo/v3/biz/search/a%2Fb"},
+		{"/api/v3/bi%7A/search/0", TopoType, "/topo/v3/biz/search/0"},
+		{"/api/v3/process/search/a%2Fb/2", ProcType, "/process/v3/search/a%2Fb/2"},
+		{"/api/v3/host/search?name=a%2Fb", HostType, "/host/v3/host/search?name=a%2Fb"},
+	})
+
+	// the invalid routes keep the current routes
+	if err := table.Set([]Route{{Name: "bad", Match: MatchPrefix, Path: "/api/v3/bad/", Target: "nowhere"}}); err == nil {
+		t.Fatal("set route with unknown target should fail")
+	}
+	if route, ok := table.Match("/api/v3/dynamic/search"); !ok || route.Name != "host.dynamic" {
+		t.Errorf("the routes are changed by the invalid routes, got %v", route)
+	}
+
+	// the routes removed from the config fall back to the default routes
+	if err := table.Set(nil); err != nil {
+		t.Fatalf("set routes failed, err: %v", err)
+	}
+	assertRoutes(t, table, []struct {
+		url    string
+		target RequestType
+		uri    string
+	}{
+		{"/api/v3/biz/search/0", TopoType, "/topo/v3/app/search/0"},
+		{"/api/v3/dynamic/search", UnknownType, ""},
+	})
+
+	for _, value := range []string{
+		"prefix /api/v3/x/",
+		"fuzzy /api/v3/x/ topo",
+		"regex ^/api/v3/(x$ topo /topo/v3/$1",
+		"prefix /api/v3/x/ topo /api/v3/y /topo/v3",
+	} {
+		if _, err := ParseRoutes(map[string]string{"route.x": value}); err == nil {
+			t.Errorf("parse invalid route %q should fail", value)
+		}
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"configcenter/src/common/metadata"
 )
@@ -103,3 +105,80 @@ func (e *eventServer) Rebook(ctx context.Context, ownerID string, appID string,
 		Into(resp)
 	return
 }
+
+func (e *eventServer) SearchDeadLetter(ctx context.Context, ownerID string, appID string, subscribeID string, h http.Header, dat metadata.ParamDeadLetterSearch) (resp *metadata.Response, err error) {
+	resp = new(metadata.Response)
+	subPath := fmt.Sprintf("/subscribe/%s/%s/%s/deadletter/search", ownerID, appID, subscribeID)
+
+	err = e.client.Post().
+		WithContext(ctx).
+		Body(dat).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (e *eventServer) ReplayDeadLetter(ctx context.Context, ownerID string, appID string, subscribeID string, h http.Header, dat metadata.ParamDeadLetterReplay) (resp *metadata.Response, err error) {
+	resp = new(metadata.Response)
+	subPath := fmt.Sprintf("/subscribe/%s/%s/%s/deadletter/replay", ownerID, appID, subscribeID)
+
+	err = e.client.Post().
+		WithContext(ctx).
+		Body(dat).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (e *eventServer) PurgeDeadLetter(ctx context.Context, ownerID string, appID string, subscribeID string, h http.Header) (resp *metadata.Response, err error) {
+	resp = new(metadata.Response)
+	subPath := fmt.Sprintf("/subscribe/%s/%s/%s/deadletter", ownerID, appID, subscribeID)
+
+	err = e.client.Delete().
+		WithContext(ctx).
+		Body(nil).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (e *eventServer) PullEvents(ctx context.Context, h http.Header, cursor int64, eventNames []string, limit int) (resp *metadata.Response, err error) {
+	resp = new(metadata.Response)
+	subPath := "/events"
+
+	req := e.client.Get().
+		WithContext(ctx).
+		WithParam("cursor", strconv.FormatInt(cursor, 10)).
+		SubResource(subPath).
+		WithHeaders(h)
+	if len(eventNames) > 0 {
+		req = req.WithParam("types", strings.Join(eventNames, ","))
+	}
+	if limit > 0 {
+		req = req.WithParam("limit", strconv.Itoa(limit))
+	}
+	err = req.Do().Into(resp)
+	return
+}
+
+func (e *eventServer) SearchDeliveries(ctx context.Context, ownerID string, appID string, subscribeID string, h http.Header, start int64, limit int64) (resp *metadata.Response, err error) {
+	resp = new(metadata.Response)
+	subPath := fmt.Sprintf("/subscribe/%s/%s/%s/deliveries", ownerID, appID, subscribeID)
+
+	req := e.client.Get().
+		WithContext(ctx).
+		WithParam("start", strconv.FormatInt(start, 10)).
+		SubResource(subPath).
+		WithHeaders(h)
+	if limit > 0 {
+		req = req.WithParam("limit", strconv.FormatInt(limit, 10))
+	}
+	err = req.Do().Into(resp)
+	return
+}
@@ -75,6 +75,20 @@ func (m *mod) DelDefaultModuleHostConfig(ctx context.Context, h http.Header, dat
 	return
 }
 
+func (m *mod) TransferHostModule(ctx context.Context, h http.Header, dat *metadata.HostsModuleRelation) (resp *metadata.BaseResp, err error) {
+	resp = new(metadata.BaseResp)
+	subPath := "/meta/hosts/modules/transfer"
+
+	err = m.client.Post().
+		WithContext(ctx).
+		Body(dat).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
 func (m *mod) MoveHost2ResourcePool(ctx context.Context, h http.Header, dat *metadata.ParamData) (resp *metadata.BaseResp, err error) {
 	resp = new(metadata.BaseResp)
 	subPath := "/meta/hosts/resource"
@@ -90,6 +90,48 @@ func (u *user) GetUserConfigDetail(ctx context.Context, businessID string, id st
 	return
 }
 
+func (u *user) GetDynamicUserConfigs(ctx context.Context, h http.Header) (resp *metadata.DynamicGroupsResult, err error) {
+	resp = new(metadata.DynamicGroupsResult)
+	subPath := "/userapi/dynamic"
+
+	err = u.client.Get().
+		WithContext(ctx).
+		Body(nil).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (u *user) GetUserConfigSnapshot(ctx context.Context, businessID string, id string, h http.Header) (resp *metadata.DynamicGroupSnapshotResult, err error) {
+	resp = new(metadata.DynamicGroupSnapshotResult)
+	subPath := fmt.Sprintf("/userapi/snapshot/%s/%s", businessID, id)
+
+	err = u.client.Get().
+		WithContext(ctx).
+		Body(nil).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (u *user) UpdateUserConfigSnapshot(ctx context.Context, businessID string, id string, h http.Header, dat *metadata.DynamicGroupSnapshotParams) (resp *metadata.DynamicGroupDeltaResult, err error) {
+	resp = new(metadata.DynamicGroupDeltaResult)
+	subPath := fmt.Sprintf("/userapi/snapshot/%s/%s", businessID, id)
+
+	err = u.client.Put().
+		WithContext(ctx).
+		Body(dat).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
 func (u *user) AddUserCustom(ctx context.Context, user string, h http.Header, dat map[string]interface{}) (resp *metadata.BaseResp, err error) {
 	resp = new(metadata.BaseResp)
 	subPath := fmt.Sprintf("/usercustom/%s", user)
@@ -26,6 +26,9 @@ type UserInterface interface {
 	DeleteUserConfig(ctx context.Context, businessID string, id string, h http.Header) (resp *metadata.BaseResp, err error)
 	GetUserConfig(ctx context.Context, h http.Header, opt *metadata.QueryInput) (resp *metadata.GetUserConfigResult, err error)
 	GetUserConfigDetail(ctx context.Context, businessID string, id string, h http.Header) (resp *metadata.GetUserConfigDetailResult, err error)
+	GetDynamicUserConfigs(ctx context.Context, h http.Header) (resp *metadata.DynamicGroupsResult, err error)
+	GetUserConfigSnapshot(ctx context.Context, businessID string, id string, h http.Header) (resp *metadata.DynamicGroupSnapshotResult, err error)
+	UpdateUserConfigSnapshot(ctx context.Context, businessID string, id string, h http.Header, dat *metadata.DynamicGroupSnapshotParams) (resp *metadata.DynamicGroupDeltaResult, err error)
 
 	AddUserCustom(ctx context.Context, user string, h http.Header, dat map[string]interface{}) (resp *metadata.BaseResp, err error)
 	UpdateUserCustomByID(ctx context.Context, user string, id string, h http.Header, dat map[string]interface{}) (resp *metadata.BaseResp, err error)
@@ -43,6 +43,10 @@ type MetaInterface interface {
 	DeleteObjectAssociation(ctx context.Context, objID int64, h http.Header, dat map[string]interface{}) (resp *metadata.DeleteResult, err error)
 	CreateObjectAssociation(ctx context.Context, h http.Header, dat *metadata.Association) (resp *metadata.CreateResult, err error)
 	UpdateObjectAssociation(ctx context.Context, objID int64, h http.Header, dat map[string]interface{}) (resp *metadata.UpdateResult, err error)
+	SelectAssociationTypes(ctx context.Context, h http.Header, dat map[string]interface{}) (resp *metadata.QueryAssociationTypeResult, err error)
+	DeleteAssociationType(ctx context.Context, id int64, h http.Header) (resp *metadata.DeleteResult, err error)
+	CreateAssociationType(ctx context.Context, h http.Header, dat *metadata.AssociationType) (resp *metadata.CreateAssociationTypeResult, err error)
+	UpdateAssociationType(ctx context.Context, id int64, h http.Header, dat map[string]interface{}) (resp *metadata.UpdateResult, err error)
 	SelectObjectAttByID(ctx context.Context, attID int64, h http.Header) (resp *metadata.QueryObjectAttributeResult, err error)
 	SelectObjectAttWithParams(ctx context.Context, h http.Header, dat map[string]interface{}) (resp *metadata.QueryObjectAttributeResult, err error)
 	DeleteObjectAttByID(ctx context.Context, attID int64, h http.Header, dat map[string]interface{}) (resp *metadata.DeleteResult, err error)
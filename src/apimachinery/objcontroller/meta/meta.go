@@ -124,6 +124,58 @@ func (t *meta) UpdateObjectAssociation(ctx context.Context, objID int64, h http.
 	return
 }
 
+func (t *meta) SelectAssociationTypes(ctx context.Context, h http.Header, dat map[string]interface{}) (resp *metatype.QueryAssociationTypeResult, err error) {
+	subPath := "/meta/asst_types"
+	resp = new(metatype.QueryAssociationTypeResult)
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(dat).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (t *meta) DeleteAssociationType(ctx context.Context, id int64, h http.Header) (resp *metatype.DeleteResult, err error) {
+	subPath := fmt.Sprintf("/meta/asst_type/%d", id)
+	resp = new(metatype.DeleteResult)
+	err = t.client.Delete().
+		WithContext(ctx).
+		Body(nil).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (t *meta) CreateAssociationType(ctx context.Context, h http.Header, dat *metatype.AssociationType) (resp *metatype.CreateAssociationTypeResult, err error) {
+	subPath := "/meta/asst_type"
+	resp = new(metatype.CreateAssociationTypeResult)
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(dat).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (t *meta) UpdateAssociationType(ctx context.Context, id int64, h http.Header, dat map[string]interface{}) (resp *metatype.UpdateResult, err error) {
+	subPath := fmt.Sprintf("/meta/asst_type/%d", id)
+	resp = new(metatype.UpdateResult)
+	err = t.client.Put().
+		WithContext(ctx).
+		Body(dat).
+		SubResource(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
 func (t *meta) SelectObjectAttByID(ctx context.Context, objID int64, h http.Header) (resp *metatype.QueryObjectAttributeResult, err error) {
 	resp = new(metatype.QueryObjectAttributeResult)
 
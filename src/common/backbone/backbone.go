@@ -36,7 +36,7 @@ func NewBackbone(ctx context.Context, zkAddr string, procName string, confPath s
 	}
 
 	handler := &cc.CCHandler{
-		OnProcessUpdate:  procHandler,
+		OnProcessUpdate:  engine.onProcessUpdate(procHandler),
 		OnLanguageUpdate: engine.onLanguageUpdate,
 		OnErrorUpdate:    engine.onErrorUpdate,
 	}
@@ -58,20 +58,33 @@ func New(c *Config, disc ServiceDiscoverInterface) (*Engine, error) {
 		return nil, err
 	}
 
-	return &Engine{
+	engine := &Engine{
 		CoreAPI:  c.CoreAPI,
 		SvcDisc:  disc,
 		Language: language.NewFromCtx(language.EmptyLanguageSetting),
 		CCErr:    errors.NewFromCtx(errors.EmptyErrorsSetting),
-	}, nil
+	}
+	engine.Authorizer = NewAuthorizer(engine)
+	return engine, nil
 }
 
 type Engine struct {
 	sync.Mutex
-	CoreAPI  apimachinery.ClientSetInterface
-	SvcDisc  ServiceDiscoverInterface
-	Language language.CCLanguageIf
-	CCErr    errors.CCErrorIf
+	CoreAPI    apimachinery.ClientSetInterface
+	SvcDisc    ServiceDiscoverInterface
+	Language   language.CCLanguageIf
+	CCErr      errors.CCErrorIf
+	Authorizer *Authorizer
+}
+
+// onProcessUpdate load the privilege config before handing the process config to the server
+func (e *Engine) onProcessUpdate(procHandler cc.ProcHandlerFunc) cc.ProcHandlerFunc {
+	return func(previous, current cc.ProcessConfig) {
+		e.Authorizer.SetConfig(ParsePrivilegeConfig(current.ConfigMap))
+		if nil != procHandler {
+			procHandler(previous, current)
+		}
+	}
 }
 
 func (e *Engine) onLanguageUpdate(previous, current map[string]language.LanguageMap) {
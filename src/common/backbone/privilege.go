@@ -0,0 +1,611 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backbone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// the actions of the object privileges in the model config of the user groups
+const (
+	PrivilegeCreate = "create"
+	PrivilegeUpdate = "update"
+	PrivilegeDelete = "delete"
+	PrivilegeSearch = "search"
+)
+
+// the business operations granted to the business roles
+const (
+	PrivilegeHostUpdate = "hostupdate"
+	PrivilegeHostTrans  = "hosttrans"
+	PrivilegeTopoUpdate = "topoupdate"
+	PrivilegeCustomAPI  = "customapi"
+
+	// PrivilegeResource the system privilege of the host resource pool,
+	// it is required by the business operations whose business is unknown
+	PrivilegeResource = "resource"
+)
+
+// the back config privileges, the model and permission management are granted to the admins only by the ui
+const (
+	PrivilegeModelConfig = "model"
+	PrivilegePermission  = "permission"
+)
+
+const (
+	// defaultPrivilegeCacheTTL used when privilege.cache_ttl is not configured
+	defaultPrivilegeCacheTTL = time.Minute
+	// privilegeCacheMax the expired privileges are purged when the cache grows over it
+	privilegeCacheMax = 1024
+)
+
+// PrivilegeRule the privilege required by a route
+type PrivilegeRule struct {
+	// ObjectID the object of the route, or the path parameter holding the object id quoted by braces, such as {obj_id}
+	ObjectID string
+	// Action the object action, create, update, delete or search
+	Action string
+	// BizParam the path parameter holding the business id
+	BizParam string
+	// BizField the field of the request body holding the business id, used if BizParam is empty,
+	// it must be the field which the handler operates on, the resource privilege is required if neither is set
+	BizField string
+	// BizAction the business operation, such as hostupdate and hosttrans
+	BizAction string
+	// System the system privilege, such as model and permission
+	System string
+}
+
+// ObjectPrivilege the rule of the object action
+func ObjectPrivilege(objID, action string) PrivilegeRule {
+	return PrivilegeRule{ObjectID: objID, Action: action}
+}
+
+// BizPrivilege the rule of the business operation, the business id is in the path parameter
+func BizPrivilege(bizParam, action string) PrivilegeRule {
+	return PrivilegeRule{BizParam: bizParam, BizAction: action}
+}
+
+// ResourcePrivilege the rule of the business operation whose business is not known from the request,
+// such as the operations on the hosts chosen by the host ids, the resource privilege is required
+func ResourcePrivilege(action string) PrivilegeRule {
+	return PrivilegeRule{BizAction: action}
+}
+
+// BizFieldPrivilege the rule of the business operation, the business id is in the field of the request body
+func BizFieldPrivilege(bizField, action string) PrivilegeRule {
+	return PrivilegeRule{BizField: bizField, BizAction: action}
+}
+
+// SystemPrivilege the rule of the system privilege, such as the model and permission management
+func SystemPrivilege(name string) PrivilegeRule {
+	return PrivilegeRule{System: name}
+}
+
+// PrivilegeConfig the privilege enforcement of the scene servers.
+// the request user is read from the BK_User header as is, so the scene servers must only be reachable
+// through the api server and the web server which authenticate the callers
+type PrivilegeConfig struct {
+	Enable bool
+	// Admins the users who skip the privilege checks
+	Admins   []string
+	CacheTTL time.Duration
+}
+
+// systemUsers the users of the cmdb services themselves, e.g. the data collection, they skip the privilege checks
+var systemUsers = []string{common.CCSystemOperatorUserName, common.CCSystemCollectorUserName}
+
+// IsUnchecked returns true if the privilege of the user is not checked
+func (cfg PrivilegeConfig) IsUnchecked(user string) bool {
+	return !cfg.Enable || util.InStrArr(cfg.Admins, user) || util.InStrArr(systemUsers, user)
+}
+
+// ParsePrivilegeConfig parse the privilege.enable, privilege.admins and privilege.cache_ttl config items
+func ParsePrivilegeConfig(configMap map[string]string) PrivilegeConfig {
+	cfg := PrivilegeConfig{CacheTTL: defaultPrivilegeCacheTTL}
+	cfg.Enable, _ = strconv.ParseBool(configMap["privilege.enable"])
+
+	for _, admin := range strings.Split(configMap["privilege.admins"], ",") {
+		if admin = strings.TrimSpace(admin); "" != admin {
+			cfg.Admins = append(cfg.Admins, admin)
+		}
+	}
+
+	if val := configMap["privilege.cache_ttl"]; "" != val {
+		seconds, err := strconv.ParseInt(val, 10, 64)
+		if nil != err || seconds < 0 {
+			blog.Errorf("invalid privilege.cache_ttl %s, use default %v", val, defaultPrivilegeCacheTTL)
+		} else {
+			cfg.CacheTTL = time.Duration(seconds) * time.Second
+		}
+	}
+	return cfg
+}
+
+// UserPrivilege the privileges merged from the user groups of the user
+type UserPrivilege struct {
+	// Objects the actions of the objects
+	Objects map[string][]string
+	// System the system privileges, such as resource
+	System []string
+	// Fields the rights of the protected fields, the fields not in it are not restricted
+	Fields map[string]map[string][]string
+}
+
+// NewUserPrivilege merge the privileges of the user groups
+func NewUserPrivilege(groups []metadata.Privilege) *UserPrivilege {
+	privilege := &UserPrivilege{Objects: map[string][]string{}, System: []string{}, Fields: map[string]map[string][]string{}}
+	for _, group := range groups {
+		for _, objects := range group.ModelConfig {
+			for objID, actions := range objects {
+				privilege.Objects[objID] = util.RemoveDuplicatesAndEmpty(append(privilege.Objects[objID], actions...))
+			}
+		}
+		if nil != group.SysConfig {
+			privilege.System = append(privilege.System, group.SysConfig.Globalbusi...)
+			privilege.System = append(privilege.System, group.SysConfig.BackConfig...)
+		}
+		for objID, fields := range group.FieldConfig {
+			if _, exists := privilege.Fields[objID]; !exists {
+				privilege.Fields[objID] = map[string][]string{}
+			}
+			for propertyID, rights := range fields {
+				privilege.Fields[objID][propertyID] = util.RemoveDuplicatesAndEmpty(append(privilege.Fields[objID][propertyID], rights...))
+			}
+		}
+	}
+	privilege.System = util.RemoveDuplicatesAndEmpty(privilege.System)
+	return privilege
+}
+
+// HasObjectPrivilege return true if the user can do the action on the object, the update privilege includes create
+func (u *UserPrivilege) HasObjectPrivilege(objID, action string) bool {
+	actions := u.Objects[objID]
+	if util.InStrArr(actions, action) {
+		return true
+	}
+	return PrivilegeCreate == action && util.InStrArr(actions, PrivilegeUpdate)
+}
+
+// HasSystemPrivilege return true if the user has the system privilege
+func (u *UserPrivilege) HasSystemPrivilege(name string) bool {
+	return util.InStrArr(u.System, name)
+}
+
+// CanReadField return true if the field is not protected, or the user can read or write it
+func (u *UserPrivilege) CanReadField(objID, propertyID string) bool {
+	rights, protected := u.Fields[objID][propertyID]
+	return !protected || util.InStrArr(rights, metadata.FieldPrivilegeRead) || util.InStrArr(rights, metadata.FieldPrivilegeWrite)
+}
+
+// CanWriteField return true if the field is not protected, or the user can write it
+func (u *UserPrivilege) CanWriteField(objID, propertyID string) bool {
+	rights, protected := u.Fields[objID][propertyID]
+	return !protected || util.InStrArr(rights, metadata.FieldPrivilegeWrite)
+}
+
+// RemoveUnreadableFields remove the fields which the user can not read from the data
+func (u *UserPrivilege) RemoveUnreadableFields(objID string, data map[string]interface{}) {
+	for propertyID := range u.Fields[objID] {
+		if _, exists := data[propertyID]; exists && !u.CanReadField(objID, propertyID) {
+			delete(data, propertyID)
+		}
+	}
+}
+
+// UnwritableFields return the fields of the data which the user can not write and whose values differ from the origin,
+// the protected fields are unchanged if they are submitted with the current values,
+// and the nil values are not set when there is no origin
+func (u *UserPrivilege) UnwritableFields(objID string, data, origin map[string]interface{}) []string {
+	fields := []string{}
+	for propertyID := range u.Fields[objID] {
+		val, exists := data[propertyID]
+		if !exists || u.CanWriteField(objID, propertyID) {
+			continue
+		}
+		if nil == origin && nil == val {
+			continue
+		}
+		if nil != origin && isSameFieldValue(val, origin[propertyID]) {
+			continue
+		}
+		fields = append(fields, propertyID)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// isSameFieldValue compare the submitted value with the stored one, the numbers are decoded as float64 from the request
+func isSameFieldValue(val, origin interface{}) bool {
+	if nil == val || "" == val {
+		return nil == origin || "" == origin
+	}
+	if nil == origin {
+		return false
+	}
+	return fmt.Sprint(val) == fmt.Sprint(origin)
+}
+
+// BizOperations the business operations of the user in a business
+type BizOperations struct {
+	// Roles the business roles of the user
+	Roles []string
+	// Operations the operations granted to the roles
+	Operations []string
+}
+
+// HasOperation return true if the user can do the operation, the maintainers can do all the operations
+func (b *BizOperations) HasOperation(operation string) bool {
+	return util.InStrArr(b.Roles, common.BKMaintainersField) || util.InStrArr(b.Operations, operation)
+}
+
+type privilegeCacheItem struct {
+	value  interface{}
+	expire time.Time
+}
+
+// Authorizer check the privileges of the request users, the resolved privileges are cached for the cache ttl
+type Authorizer struct {
+	engine *Engine
+	lock   sync.RWMutex
+	config PrivilegeConfig
+	cache  map[string]privilegeCacheItem
+}
+
+// NewAuthorizer create the authorizer, the privileges are not checked until it is enabled by the config
+func NewAuthorizer(engine *Engine) *Authorizer {
+	return &Authorizer{
+		engine: engine,
+		config: PrivilegeConfig{CacheTTL: defaultPrivilegeCacheTTL},
+		cache:  map[string]privilegeCacheItem{},
+	}
+}
+
+// SetConfig update the config, and drop the cached privileges
+func (a *Authorizer) SetConfig(cfg PrivilegeConfig) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.config = cfg
+	a.cache = map[string]privilegeCacheItem{}
+}
+
+// Config return the current config
+func (a *Authorizer) Config() PrivilegeConfig {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.config
+}
+
+func (a *Authorizer) getCache(key string) (interface{}, bool) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	item, exists := a.cache[key]
+	if !exists || time.Now().After(item.expire) {
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (a *Authorizer) setCache(key string, value interface{}) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	now := time.Now()
+	if len(a.cache) >= privilegeCacheMax {
+		for cacheKey, item := range a.cache {
+			if now.After(item.expire) {
+				delete(a.cache, cacheKey)
+			}
+		}
+	}
+	a.cache[key] = privilegeCacheItem{value: value, expire: now.Add(a.config.CacheTTL)}
+}
+
+// GetUserPrivilege resolve the privileges of the user groups which the user belongs to
+func (a *Authorizer) GetUserPrivilege(header http.Header, ownerID, user string) (*UserPrivilege, error) {
+
+	key := fmt.Sprintf("user:%s:%s", ownerID, user)
+	if cached, exists := a.getCache(key); exists {
+		return cached.(*UserPrivilege), nil
+	}
+
+	client := a.engine.CoreAPI.ObjectController().Privilege()
+	cond := map[string]interface{}{common.BKUserListField: map[string]interface{}{common.BKDBLIKE: user}}
+	rsp, err := client.SearchUserGroup(context.Background(), ownerID, header, cond)
+	if nil != err {
+		return nil, fmt.Errorf("search the user groups failed, err: %v", err)
+	}
+	if !rsp.Result {
+		return nil, fmt.Errorf("search the user groups failed, err: %s", rsp.ErrMsg)
+	}
+
+	groups := []metadata.Privilege{}
+	for _, group := range rsp.Data {
+		// the like condition matches the user names containing the user
+		if !util.InStrArr(strings.FieldsFunc(group.UserList, func(r rune) bool { return ';' == r || ',' == r }), user) {
+			continue
+		}
+
+		grpRsp, err := client.GetUserGroupPrivi(context.Background(), ownerID, group.GroupID, header)
+		if nil != err {
+			return nil, fmt.Errorf("get the privilege of the user group %s failed, err: %v", group.GroupID, err)
+		}
+		if !grpRsp.Result {
+			return nil, fmt.Errorf("get the privilege of the user group %s failed, err: %s", group.GroupID, grpRsp.ErrMsg)
+		}
+		if nil != grpRsp.Data.Privilege {
+			groups = append(groups, *grpRsp.Data.Privilege)
+		}
+	}
+
+	privilege := NewUserPrivilege(groups)
+	a.setCache(key, privilege)
+	return privilege, nil
+}
+
+// GetBizOperations resolve the roles of the user in the business and the operations granted to the roles,
+// the roles are the user attributes of the business which contain the user
+func (a *Authorizer) GetBizOperations(header http.Header, ownerID, user string, bizID int64) (*BizOperations, error) {
+
+	key := fmt.Sprintf("biz:%s:%s:%d", ownerID, user, bizID)
+	if cached, exists := a.getCache(key); exists {
+		return cached.(*BizOperations), nil
+	}
+
+	attrCond := map[string]interface{}{
+		common.BKObjIDField:        common.BKInnerObjIDApp,
+		common.BKPropertyTypeField: common.FieldTypeUser,
+		common.BKOwnerIDField:      ownerID,
+	}
+	attrRsp, err := a.engine.CoreAPI.ObjectController().Meta().SelectObjectAttWithParams(context.Background(), header, attrCond)
+	if nil != err {
+		return nil, fmt.Errorf("search the business roles failed, err: %v", err)
+	}
+	if !attrRsp.Result {
+		return nil, fmt.Errorf("search the business roles failed, err: %s", attrRsp.ErrMsg)
+	}
+
+	bizCond := map[string]interface{}{common.BKAppIDField: bizID, common.BKOwnerIDField: ownerID}
+	bizRsp, err := a.engine.CoreAPI.ObjectController().Instance().SearchObjects(context.Background(), common.BKInnerObjIDApp, header, &metadata.QueryInput{Condition: bizCond, Limit: 1})
+	if nil != err {
+		return nil, fmt.Errorf("search the business %d failed, err: %v", bizID, err)
+	}
+	if !bizRsp.Result {
+		return nil, fmt.Errorf("search the business %d failed, err: %s", bizID, bizRsp.ErrMsg)
+	}
+
+	operations := &BizOperations{Roles: []string{}, Operations: []string{}}
+	for _, biz := range bizRsp.Data.Info {
+		for _, attr := range attrRsp.Data {
+			users, _ := biz.String(attr.PropertyID)
+			if util.InStrArr(strings.Split(users, ","), user) {
+				operations.Roles = append(operations.Roles, attr.PropertyID)
+			}
+		}
+	}
+
+	for _, role := range operations.Roles {
+		roleRsp, err := a.engine.CoreAPI.ObjectController().Privilege().GetRolePri(context.Background(), ownerID, common.BKInnerObjIDApp, role, header)
+		if nil != err {
+			return nil, fmt.Errorf("get the privilege of the business role %s failed, err: %v", role, err)
+		}
+		if !roleRsp.Result {
+			return nil, fmt.Errorf("get the privilege of the business role %s failed, err: %s", role, roleRsp.ErrMsg)
+		}
+
+		// the data is an empty object if the role has no privilege
+		if items, ok := roleRsp.Data.([]interface{}); ok {
+			for _, item := range items {
+				if operation, ok := item.(string); ok {
+					operations.Operations = append(operations.Operations, operation)
+				}
+			}
+		}
+	}
+	operations.Operations = util.RemoveDuplicatesAndEmpty(operations.Operations)
+
+	a.setCache(key, operations)
+	return operations, nil
+}
+
+// Authorize check whether the request user has the privilege of the rule
+func (a *Authorizer) Authorize(req *restful.Request, rule PrivilegeRule) error {
+
+	cfg := a.Config()
+	ownerID, user := util.GetActionOnwerIDAndUser(req)
+	if cfg.IsUnchecked(user) {
+		return nil
+	}
+
+	defErr := a.engine.CCErr.CreateDefaultCCErrorIf(util.GetActionLanguage(req))
+	header := req.Request.Header
+
+	if "" != rule.System {
+		privilege, err := a.GetUserPrivilege(header, ownerID, user)
+		if nil != err {
+			blog.Errorf("failed to get the privilege of the user %s, %v", user, err)
+			return defErr.Errorf(common.CCErrCommNotAuthItem, user)
+		}
+
+		if !privilege.HasSystemPrivilege(rule.System) {
+			return defErr.Errorf(common.CCErrCommNoPrivilege, user, PrivilegeUpdate, rule.System)
+		}
+	}
+
+	if "" != rule.Action {
+		objID := rule.ObjectID
+		if strings.HasPrefix(objID, "{") && strings.HasSuffix(objID, "}") {
+			objID = req.PathParameter(strings.Trim(objID, "{}"))
+		}
+
+		privilege, err := a.GetUserPrivilege(header, ownerID, user)
+		if nil != err {
+			blog.Errorf("failed to get the privilege of the user %s, %v", user, err)
+			return defErr.Errorf(common.CCErrCommNotAuthItem, user)
+		}
+
+		if !privilege.HasObjectPrivilege(objID, rule.Action) {
+			return defErr.Errorf(common.CCErrCommNoPrivilege, user, rule.Action, objID)
+		}
+	}
+
+	if "" != rule.BizAction {
+		bizID, exists := requestBizID(req, rule)
+		if !exists {
+			privilege, err := a.GetUserPrivilege(header, ownerID, user)
+			if nil != err {
+				blog.Errorf("failed to get the privilege of the user %s, %v", user, err)
+				return defErr.Errorf(common.CCErrCommNotAuthItem, user)
+			}
+
+			if !privilege.HasSystemPrivilege(PrivilegeResource) {
+				return defErr.Errorf(common.CCErrCommNoPrivilege, user, rule.BizAction, PrivilegeResource)
+			}
+			return nil
+		}
+
+		operations, err := a.GetBizOperations(header, ownerID, user, bizID)
+		if nil != err {
+			blog.Errorf("failed to get the business %d privilege of the user %s, %v", bizID, user, err)
+			return defErr.Errorf(common.CCErrCommNotAuthItem, user)
+		}
+
+		if !operations.HasOperation(rule.BizAction) {
+			return defErr.Errorf(common.CCErrCommNoPrivilege, user, rule.BizAction, common.BKAppIDField+":"+strconv.FormatInt(bizID, 10))
+		}
+	}
+
+	return nil
+}
+
+// GetRequestPrivilege get the privilege of the request user to check the field rights,
+// it returns nil if the privilege is not checked for the user
+func (a *Authorizer) GetRequestPrivilege(header http.Header) (*UserPrivilege, error) {
+
+	cfg := a.Config()
+	ownerID, user := util.GetOwnerIDAndUser(header)
+	if cfg.IsUnchecked(user) {
+		return nil, nil
+	}
+
+	privilege, err := a.GetUserPrivilege(header, ownerID, user)
+	if nil != err {
+		blog.Errorf("failed to get the privilege of the user %s, %v", user, err)
+		return nil, a.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header)).Errorf(common.CCErrCommNotAuthItem, user)
+	}
+	return privilege, nil
+}
+
+// FilterReadableFields remove the fields which the request user can not read from the datas of the object
+func (a *Authorizer) FilterReadableFields(header http.Header, objID string, datas ...map[string]interface{}) error {
+
+	privilege, err := a.GetRequestPrivilege(header)
+	if nil != err || nil == privilege {
+		return err
+	}
+
+	for _, data := range datas {
+		privilege.RemoveUnreadableFields(objID, data)
+	}
+	return nil
+}
+
+// CheckWritableFields return CCErrCommNoFieldPrivilege if the request user modifies the fields which can not be written,
+// every submitted protected field except the nil ones is treated as modified if no origin data is given
+func (a *Authorizer) CheckWritableFields(header http.Header, objID string, data map[string]interface{}, origins ...map[string]interface{}) error {
+
+	privilege, err := a.GetRequestPrivilege(header)
+	if nil != err || nil == privilege {
+		return err
+	}
+
+	if 0 == len(origins) {
+		origins = append(origins, nil)
+	}
+
+	fields := []string{}
+	for _, origin := range origins {
+		fields = append(fields, privilege.UnwritableFields(objID, data, origin)...)
+	}
+	fields = util.RemoveDuplicatesAndEmpty(fields)
+	if 0 != len(fields) {
+		blog.Errorf("the user %s has no privilege to modify the fields %v of the object %s, rid: %s", util.GetUser(header), fields, objID, util.GetHTTPCCRequestID(header))
+		return a.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header)).Errorf(common.CCErrCommNoFieldPrivilege, util.GetUser(header), strings.Join(fields, ","))
+	}
+	return nil
+}
+
+// requestBizID read the business id from the path parameter or the field of the request body which the handler uses,
+// the bk_biz_id header is never trusted because it could differ from the business the handler operates on
+func requestBizID(req *restful.Request, rule PrivilegeRule) (int64, bool) {
+
+	if "" != rule.BizParam {
+		bizID, err := strconv.ParseInt(req.PathParameter(rule.BizParam), 10, 64)
+		return bizID, nil == err
+	}
+
+	if "" == rule.BizField || nil == req.Request.Body {
+		return 0, false
+	}
+
+	body, err := ioutil.ReadAll(req.Request.Body)
+	req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if nil != err {
+		return 0, false
+	}
+
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(body, &data); nil != err {
+		return 0, false
+	}
+
+	bizID, err := util.GetInt64ByInterface(data[rule.BizField])
+	return bizID, nil == err && 0 != bizID
+}
+
+// PrivilegeFilter the route filter checking the privilege rule, the requests pass if the authorizer is nil
+func PrivilegeFilter(getAuthorizer func() *Authorizer, rule PrivilegeRule) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+
+		if authorizer := getAuthorizer(); nil != authorizer {
+			if err := authorizer.Authorize(req, rule); nil != err {
+				blog.Errorf("the request %s %s is refused, %v, rid: %s", req.Request.Method, req.Request.URL.Path, err, util.GetHTTPCCRequestID(req.Request.Header))
+				status := http.StatusInternalServerError
+				if ccErr, ok := err.(errors.CCErrorCoder); ok && common.CCErrCommNoPrivilege == ccErr.GetCode() {
+					status = http.StatusForbidden
+				}
+				resp.WriteError(status, &metadata.RespError{Msg: err})
+				return
+			}
+		}
+
+		chain.ProcessFilter(req, resp)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backbone
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+)
+
+func TestParsePrivilegeConfig(t *testing.T) {
+	cfg := ParsePrivilegeConfig(map[string]string{})
+	assert.False(t, cfg.Enable)
+	assert.Empty(t, cfg.Admins)
+	assert.Equal(t, defaultPrivilegeCacheTTL, cfg.CacheTTL)
+
+	cfg = ParsePrivilegeConfig(map[string]string{
+		"privilege.enable":    "true",
+		"privilege.admins":    "admin, ops,",
+		"privilege.cache_ttl": "30",
+	})
+	assert.True(t, cfg.Enable)
+	assert.Equal(t, []string{"admin", "ops"}, cfg.Admins)
+	assert.Equal(t, 30*time.Second, cfg.CacheTTL)
+
+	assert.True(t, cfg.IsUnchecked("ops"))
+	assert.True(t, cfg.IsUnchecked(common.CCSystemCollectorUserName))
+	assert.True(t, cfg.IsUnchecked(common.CCSystemOperatorUserName))
+	assert.False(t, cfg.IsUnchecked("guest"))
+
+	cfg = ParsePrivilegeConfig(map[string]string{"privilege.cache_ttl": "-1"})
+	assert.Equal(t, defaultPrivilegeCacheTTL, cfg.CacheTTL)
+	assert.True(t, cfg.IsUnchecked("guest"), "nobody is checked when the privilege is disabled")
+}
+
+func TestUserPrivilege(t *testing.T) {
+	privilege := NewUserPrivilege([]metadata.Privilege{
+		{
+			ModelConfig: map[string]map[string][]string{
+				"bk_network": {"switch": {PrivilegeSearch}},
+			},
+			SysConfig: &metadata.SysConfigStruct{Globalbusi: []string{PrivilegeResource}},
+		},
+		{
+			SysConfig: &metadata.SysConfigStruct{BackConfig: []string{PrivilegeModelConfig}},
+			ModelConfig: map[string]map[string][]string{
+				"bk_network": {"switch": {PrivilegeUpdate, PrivilegeSearch}},
+			},
+		},
+	})
+
+	assert.Equal(t, []string{PrivilegeSearch, PrivilegeUpdate}, privilege.Objects["switch"])
+	assert.True(t, privilege.HasObjectPrivilege("switch", PrivilegeUpdate))
+	assert.True(t, privilege.HasObjectPrivilege("switch", PrivilegeCreate))
+	assert.False(t, privilege.HasObjectPrivilege("switch", PrivilegeDelete))
+	assert.False(t, privilege.HasObjectPrivilege("router", PrivilegeSearch))
+	assert.True(t, privilege.HasSystemPrivilege(PrivilegeResource))
+	assert.True(t, privilege.HasSystemPrivilege(PrivilegeModelConfig))
+	assert.False(t, privilege.HasSystemPrivilege(PrivilegePermission))
+}
+
+func TestBizOperations(t *testing.T) {
+	operations := &BizOperations{Roles: []string{"bk_biz_developer"}, Operations: []string{PrivilegeHostTrans}}
+	assert.True(t, operations.HasOperation(PrivilegeHostTrans))
+	assert.False(t, operations.HasOperation(PrivilegeHostUpdate))
+
+	operations.Roles = append(operations.Roles, common.BKMaintainersField)
+	assert.True(t, operations.HasOperation(PrivilegeHostUpdate))
+}
+
+func TestFieldPrivilege(t *testing.T) {
+	privilege := NewUserPrivilege([]metadata.Privilege{
+		{
+			FieldConfig: map[string]map[string][]string{
+				common.BKInnerObjIDHost: {"bk_asset_id": {metadata.FieldPrivilegeRead}, "bk_sn": {}},
+			},
+		},
+		{
+			FieldConfig: map[string]map[string][]string{
+				common.BKInnerObjIDHost: {"bk_comment": {metadata.FieldPrivilegeWrite}},
+			},
+		},
+	})
+
+	assert.True(t, privilege.CanReadField(common.BKInnerObjIDHost, "bk_asset_id"))
+	assert.False(t, privilege.CanWriteField(common.BKInnerObjIDHost, "bk_asset_id"))
+	assert.False(t, privilege.CanReadField(common.BKInnerObjIDHost, "bk_sn"))
+	assert.True(t, privilege.CanReadField(common.BKInnerObjIDHost, "bk_comment"))
+	assert.True(t, privilege.CanWriteField(common.BKInnerObjIDHost, "bk_comment"))
+	assert.True(t, privilege.CanWriteField(common.BKInnerObjIDHost, "bk_host_name"))
+	assert.True(t, privilege.CanWriteField(common.BKInnerObjIDApp, "bk_sn"))
+
+	data := map[string]interface{}{"bk_host_id": 1, "bk_asset_id": "a01", "bk_sn": "s01"}
+	privilege.RemoveUnreadableFields(common.BKInnerObjIDHost, data)
+	assert.Equal(t, map[string]interface{}{"bk_host_id": 1, "bk_asset_id": "a01"}, data)
+
+	origin := map[string]interface{}{"bk_host_id": 1, "bk_asset_id": "a01", "bk_sn": "s01", "bk_comment": "c"}
+	assert.Empty(t, privilege.UnwritableFields(common.BKInnerObjIDHost, map[string]interface{}{"bk_asset_id": "a01", "bk_comment": "d"}, origin))
+	assert.Equal(t, []string{"bk_asset_id", "bk_sn"}, privilege.UnwritableFields(common.BKInnerObjIDHost, map[string]interface{}{"bk_asset_id": "a02", "bk_sn": ""}, origin))
+	assert.Equal(t, []string{"bk_asset_id"}, privilege.UnwritableFields(common.BKInnerObjIDHost, map[string]interface{}{"bk_asset_id": "a01"}, nil))
+	assert.Empty(t, privilege.UnwritableFields(common.BKInnerObjIDHost, map[string]interface{}{"bk_asset_id": nil}, nil))
+}
+
+func TestRequestBizID(t *testing.T) {
+	newRequest := func(body string) *restful.Request {
+		httpReq := httptest.NewRequest("POST", "/hosts/modules", strings.NewReader(body))
+		httpReq.Header.Set(common.BKAppIDField, "1")
+		return restful.NewRequest(httpReq)
+	}
+
+	// the header is ignored, the business is read from the body field which the handler uses
+	req := newRequest(`{"bk_biz_id": 2, "appId": "3"}`)
+	bizID, exists := requestBizID(req, BizFieldPrivilege(common.BKAppIDField, PrivilegeHostTrans))
+	assert.True(t, exists)
+	assert.Equal(t, int64(2), bizID)
+	body, _ := ioutil.ReadAll(req.Request.Body)
+	assert.Equal(t, `{"bk_biz_id": 2, "appId": "3"}`, string(body))
+
+	bizID, exists = requestBizID(newRequest(`{"bk_biz_id": 2, "appId": "3"}`), BizFieldPrivilege("appId", PrivilegeHostTrans))
+	assert.True(t, exists)
+	assert.Equal(t, int64(3), bizID)
+
+	_, exists = requestBizID(newRequest(`{"bk_host_id": "1,2"}`), BizFieldPrivilege(common.BKAppIDField, PrivilegeHostUpdate))
+	assert.False(t, exists)
+
+	_, exists = requestBizID(newRequest(`{"bk_biz_id": 2}`), ResourcePrivilege(PrivilegeHostUpdate))
+	assert.False(t, exists)
+}
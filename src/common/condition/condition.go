@@ -39,6 +39,7 @@ type Condition interface {
 	Field(fieldName string) Field
 	Parse(data types.MapStr) error
 	ToMapStr() types.MapStr
+	Match(data types.MapStr) bool
 }
 
 // Condition the condition definition
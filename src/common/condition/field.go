@@ -29,6 +29,7 @@ type Field interface {
 	Gt(val interface{}) Condition
 	Gte(val interface{}) Condition
 	ToMapStr() types.MapStr
+	Match(data types.MapStr) bool
 }
 
 // Field the field object
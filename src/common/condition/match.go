@@ -0,0 +1,178 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package condition
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+
+	types "configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+)
+
+// Match check whether the data matches all the fields of the condition,
+// it works like the db query but runs in memory
+func (cli *condition) Match(data types.MapStr) bool {
+	for _, item := range cli.fields {
+		if !item.Match(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match check whether the data matches the field
+func (cli *field) Match(data types.MapStr) bool {
+
+	if BKDBOR == cli.fieldName {
+		return matchOr(cli.fieldValue, data)
+	}
+
+	val, exists := data[cli.fieldName]
+
+	if len(cli.fields) > 0 {
+		subData, err := toMapStr(val)
+		if nil != err || !exists {
+			return false
+		}
+		for _, item := range cli.fields {
+			if !item.Match(subData) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch cli.opeartor {
+	case BKDBNE:
+		return !exists || !equal(val, cli.fieldValue)
+	case BKDBIN:
+		return exists && inSlice(val, cli.fieldValue)
+	case BKDBNIN:
+		return !exists || !inSlice(val, cli.fieldValue)
+	case BKDBLT:
+		ret, ok := compare(val, cli.fieldValue)
+		return exists && ok && ret < 0
+	case BKDBLTE:
+		ret, ok := compare(val, cli.fieldValue)
+		return exists && ok && ret <= 0
+	case BKDBGT:
+		ret, ok := compare(val, cli.fieldValue)
+		return exists && ok && ret > 0
+	case BKDBGTE:
+		ret, ok := compare(val, cli.fieldValue)
+		return exists && ok && ret >= 0
+	case BKDBLIKE:
+		pattern, err := regexp.Compile(util.GetStrByInterface(cli.fieldValue))
+		return exists && nil == err && pattern.MatchString(util.GetStrByInterface(val))
+	case BKDBOR:
+		return matchOr(cli.fieldValue, types.MapStr{cli.fieldName: val})
+	default:
+		return exists && equal(val, cli.fieldValue)
+	}
+}
+
+// matchOr the conds should be a slice of conditions, returns true when any of them matches
+func matchOr(conds interface{}, data types.MapStr) bool {
+	items, err := util.GetMapInterfaceByInerface(conds)
+	if nil != err {
+		return false
+	}
+	for _, item := range items {
+		subCond, err := toMapStr(item)
+		if nil != err {
+			continue
+		}
+		cond := CreateCondition()
+		if err := cond.Parse(subCond); nil != err {
+			continue
+		}
+		if cond.Match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func toMapStr(val interface{}) (types.MapStr, error) {
+	if tmp, ok := val.(types.MapStr); ok {
+		return tmp, nil
+	}
+	return types.NewFromInterface(val)
+}
+
+func inSlice(val, items interface{}) bool {
+	values, err := util.GetMapInterfaceByInerface(items)
+	if nil != err {
+		return false
+	}
+	for _, item := range values {
+		if equal(val, item) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	if ret, ok := compare(a, b); ok {
+		return 0 == ret
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare compares two numbers or two strings, the ok is false when they are not comparable
+func compare(a, b interface{}) (ret int, ok bool) {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if okA && okB {
+		switch {
+		case sa < sb:
+			return -1, true
+		case sa > sb:
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func toFloat(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int, int8, int16, int32, int64:
+		return float64(reflect.ValueOf(v).Int()), true
+	case uint, uint8, uint16, uint32, uint64:
+		return float64(reflect.ValueOf(v).Uint()), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, nil == err
+	}
+	return 0, false
+}
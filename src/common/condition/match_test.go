@@ -0,0 +1,61 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package condition_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"configcenter/src/common/condition"
+	"configcenter/src/common/mapstr"
+)
+
+func TestConditionMatch(t *testing.T) {
+	data := mapstr.MapStr{
+		"bk_host_id":   float64(1),
+		"bk_host_name": "db-master-01",
+		"bk_os_type":   "1",
+		"bk_cpu":       8,
+	}
+
+	cases := []struct {
+		cond   string
+		expect bool
+	}{
+		{`{"bk_os_type":"1"}`, true},
+		{`{"bk_os_type":"2"}`, false},
+		{`{"bk_host_id":1, "bk_cpu":{"$gte":8}}`, true},
+		{`{"bk_cpu":{"$lt":8}}`, false},
+		{`{"bk_host_id":{"$in":[2,3]}}`, false},
+		{`{"bk_host_id":{"$nin":[2,3]}}`, true},
+		{`{"bk_host_name":{"$regex":"^db-"}}`, true},
+		{`{"bk_host_name":{"$ne":"db-master-01"}}`, false},
+		{`{"bk_not_exists":{"$ne":"x"}}`, true},
+		{`{"$or":[{"bk_os_type":"2"},{"bk_cpu":{"$gt":4}}]}`, true},
+		{`{"$or":[{"bk_os_type":"2"},{"bk_cpu":{"$gt":16}}]}`, false},
+	}
+
+	for _, item := range cases {
+		condData := mapstr.MapStr{}
+		if err := json.Unmarshal([]byte(item.cond), &condData); nil != err {
+			t.Fatalf("unmarshal %s failed, error info is %s", item.cond, err.Error())
+		}
+		cond := condition.CreateCondition()
+		if err := cond.Parse(condData); nil != err {
+			t.Fatalf("parse %s failed, error info is %s", item.cond, err.Error())
+		}
+		if cond.Match(data) != item.expect {
+			t.Errorf("condition %s expect match %v, got %v", item.cond, item.expect, !item.expect)
+		}
+	}
+}
@@ -128,6 +128,12 @@ const (
 	// BKDBOR the db operator
 	BKDBOR = "$or"
 
+	// BKDBAND the db operator
+	BKDBAND = "$and"
+
+	// BKDBNOR the db operator
+	BKDBNOR = "$nor"
+
 	// BKDBLIKE the db operator
 	BKDBLIKE = "$regex"
 
@@ -193,6 +199,12 @@ const (
 	// BKHostNameField the host name field
 	BKHostNameField = "bk_host_name"
 
+	// BKLastReportTimeField the time the agent of the host reported the last snapshot
+	BKLastReportTimeField = "bk_last_report_time"
+
+	// BKAgentOfflineTimeField the time since the agent of the host stopped reporting
+	BKAgentOfflineTimeField = "bk_agent_offline_time"
+
 	// BKAppNameField the app name field
 	BKAppNameField = "bk_biz_name"
 
@@ -290,6 +302,9 @@ const (
 	// BKAsstInstIDField the property inst id field
 	BKAsstInstIDField = "bk_asst_inst_id"
 
+	// BKAsstTypeIDField the association type id field
+	BKAsstTypeIDField = "bk_asst_type_id"
+
 	// BKOptionField the option field
 	BKOptionField = "option"
 
@@ -473,6 +488,14 @@ const (
 	LastTimeField = "last_time"
 )
 
+const (
+	// BKAppCodeField the app code field of the api key
+	BKAppCodeField = "bk_app_code"
+
+	// BKKeyIDField the id field of the api key
+	BKKeyIDField = "bk_key_id"
+)
+
 const (
 	// ValidCreate valid create
 	ValidCreate = "create"
@@ -660,6 +683,11 @@ const (
 	BKSessionLanugageKey    = "language"
 
 	BKHTTPCCRequestID = "rid"
+
+	// BKHTTPHeaderAppCode the app code of the api key
+	BKHTTPHeaderAppCode = "BK_App_Code"
+	// BKHTTPHeaderAppSecret the secret of the api key
+	BKHTTPHeaderAppSecret = "BK_App_Secret"
 )
 
 const (
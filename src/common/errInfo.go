@@ -136,7 +136,21 @@ const (
 	CCErrProxyRequestFailed      = 1199036
 	CCErrRewriteRequestUriFailed = 1199037
 
+	// CCErrCommNoPrivilege the user has no privilege of the operation
+	CCErrCommNoPrivilege = 1199038
+
+	// CCErrCommNoFieldPrivilege the user has no privilege to modify the protected fields
+	CCErrCommNoFieldPrivilege = 1199039
+
 	// apiserver 1100XXX
+	// CCErrAPIServerAuthRequired the app code or secret is missing
+	CCErrAPIServerAuthRequired = 1100000
+	// CCErrAPIServerAuthFailed the app code or secret is invalid
+	CCErrAPIServerAuthFailed = 1100001
+	// CCErrAPIServerAuthExpired the api key is expired or disabled
+	CCErrAPIServerAuthExpired = 1100002
+	// CCErrAPIServerTooManyRequests the caller sends too many requests
+	CCErrAPIServerTooManyRequests = 1100003
 
 	// toposerver 1101XXX
 	// CCErrTopoInstCreateFailed unable to create the instance
@@ -250,6 +264,16 @@ const (
 	CCErrTopoInstHasBeenAssociation = 1101036
 	// it is forbidden to delete , that has some insts
 	CCErrTopoObjectHasSomeInstsForbiddenToDelete = 1101037
+	// CCErrTopoAsstTypeNotFound the association type does not exist
+	CCErrTopoAsstTypeNotFound = 1101038
+	// CCErrTopoAsstTypeInUse the association type is used by the object associations
+	CCErrTopoAsstTypeInUse = 1101039
+	// CCErrTopoAsstTypeObjectMismatch the association type is defined between other objects
+	CCErrTopoAsstTypeObjectMismatch = 1101040
+	// CCErrTopoInstAsstMappingViolated the instance association violates the mapping of the association type
+	CCErrTopoInstAsstMappingViolated = 1101041
+	// CCErrTopoInstDeleteBlocked the instances are still referenced by the dependents
+	CCErrTopoInstDeleteBlocked = 1101042
 
 	CCErrTopoAppDeleteFailed                       = 1001031
 	CCErrTopoAppUpdateFailed                       = 1001032
@@ -315,6 +339,16 @@ const (
 	CCErrEventSubscribeTelnetFailed = 1103005
 	// CCErrEventOperateSuccessBUtSentEventFailed failed to sent event
 	CCErrEventPushEventFailed = 1103006
+	// CCErrEventDeadLetterSelectFailed failed to select the dead letters
+	CCErrEventDeadLetterSelectFailed = 1103007
+	// CCErrEventDeadLetterReplayFailed failed to replay the dead letters
+	CCErrEventDeadLetterReplayFailed = 1103008
+	// CCErrEventDeadLetterPurgeFailed failed to purge the dead letters
+	CCErrEventDeadLetterPurgeFailed = 1103009
+	// CCErrEventHistorySelectFailed failed to select the event histories
+	CCErrEventHistorySelectFailed = 1103010
+	// CCErrEventDeliverySelectFailed failed to select the delivery records
+	CCErrEventDeliverySelectFailed = 1103011
 
 	// host 1104XXX
 	CCErrHostModuleRelationAddFailed = 1104000
@@ -322,6 +356,10 @@ const (
 	// migrate 1105XXX
 	//  CCErrCommMigrateFailed failed to migrate
 	CCErrCommMigrateFailed = 1105000
+	// CCErrCommMigratePlanFailed failed to make the migrate plan
+	CCErrCommMigratePlanFailed = 1105001
+	// CCErrCommMigrateRollbackFailed failed to rollback the migration
+	CCErrCommMigrateRollbackFailed = 1105002
 
 	// hostcontroller 1106XXX
 	CCErrHostSelectInst                  = 1106000
@@ -420,6 +458,8 @@ const (
 	CCErrHostAPPNotFoundFail           = 1110048
 	CCErrHostGetModuleFail             = 1110049
 	CCErrHostAgentStatusFail           = 1110050
+	CCErrHostDynamicGroupEvalFailed    = 1110051
+	CCErrHostDynamicGroupSnapshotFail  = 1110052
 
 	//web  1111XXX
 	CCErrWebFileNoFound      = 1111001
@@ -30,6 +30,9 @@ type EventContext struct {
 	RequestTime commontypes.Time
 	ownerID     string
 	CacheCli    *redis.Client
+	// pending the events buffered in a transaction, they are sent when it commits
+	pending [][]byte
+	inTx    bool
 }
 
 func NewEventContextByReq(pheader http.Header, cacheCli *redis.Client) *EventContext {
@@ -72,6 +75,10 @@ func (c *EventContext) InsertEvent(eventType, objType, action string, curData in
 	if err != nil {
 		return err
 	}
+	if c.inTx {
+		c.pending = append(c.pending, value)
+		return nil
+	}
 
 	err = c.CacheCli.LPush(common.EventCacheEventQueueKey, value).Err()
 	if err != nil {
@@ -79,3 +86,29 @@ func (c *EventContext) InsertEvent(eventType, objType, action string, curData in
 	}
 	return nil
 }
+
+// Begin buffer the events inserted after it until Commit, so that
+// no events are sent for the db writes which are rolled back
+func (c *EventContext) Begin() {
+	c.inTx = true
+	c.pending = nil
+}
+
+// Commit send the buffered events
+func (c *EventContext) Commit() error {
+	c.inTx = false
+	pending := c.pending
+	c.pending = nil
+	for _, value := range pending {
+		if err := c.CacheCli.LPush(common.EventCacheEventQueueKey, value).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback drop the buffered events
+func (c *EventContext) Rollback() {
+	c.inTx = false
+	c.pending = nil
+}
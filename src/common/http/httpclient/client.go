@@ -220,6 +220,27 @@ func (client *HttpClient) RequestEx(url, method string, header http.Header, data
 	return rsp.StatusCode, body, err
 }
 
+// RequestStream do the request and return the response without reading the body,
+// so the body can be read as a stream, the caller must close the body
+func (client *HttpClient) RequestStream(url, method string, header http.Header, data []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Close = true
+
+	if header != nil {
+		req.Header = header
+	}
+
+	for key, value := range client.header {
+		req.Header.Set(key, value)
+	}
+
+	return client.httpCli.Do(req)
+}
+
 func (client *HttpClient) DoWithTimeout(timeout time.Duration, req *http.Request) (*http.Response, error) {
 	ctx, cancel := context.WithTimeout(req.Context(), timeout)
 	defer cancel()
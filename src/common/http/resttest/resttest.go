@@ -0,0 +1,56 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package resttest provides the helpers to test the restful services in memory
+package resttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/errors"
+)
+
+// NewEngine returns the engine with an empty error set, it is enough for the services under test
+func NewEngine() *backbone.Engine {
+	return &backbone.Engine{CCErr: errors.NewFromCtx(map[string]errors.ErrorCode{})}
+}
+
+// NewContainer returns the container serving the web services
+func NewContainer(services ...*restful.WebService) *restful.Container {
+	container := restful.NewContainer()
+	for _, ws := range services {
+		container.Add(ws)
+	}
+	return container
+}
+
+// DoRequest send the json body as the admin of the default supplier, decode the response into result and return the http status
+func DoRequest(t *testing.T, container *restful.Container, method, url string, body interface{}, result interface{}) int {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, url, bytes.NewReader(data))
+	req.Header.Set("Content-Type", restful.MIME_JSON)
+	req.Header.Set(common.BKHTTPOwnerID, "0")
+	req.Header.Set(common.BKHTTPHeaderUser, "admin")
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), result), recorder.Body.String())
+	return recorder.Code
+}
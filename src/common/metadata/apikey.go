@@ -0,0 +1,99 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+)
+
+// APIKey the credential of an app calling the api server, only the hash of the secret is saved
+type APIKey struct {
+	KeyID      int64      `json:"bk_key_id" bson:"bk_key_id"`
+	AppCode    string     `json:"bk_app_code" bson:"bk_app_code"`
+	Secret     string     `json:"-" bson:"secret"`
+	Salt       string     `json:"-" bson:"salt"`
+	OwnerID    string     `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	User       string     `json:"bk_username" bson:"bk_username"`
+	Enabled    bool       `json:"enabled" bson:"enabled"`
+	ExpireTime *time.Time `json:"expire_time,omitempty" bson:"expire_time"`
+	Creator    string     `json:"creator" bson:"creator"`
+	CreateTime time.Time  `json:"create_time" bson:"create_time"`
+	LastTime   time.Time  `json:"last_time" bson:"last_time"`
+}
+
+// NewAPIKeySecret generate a random secret
+func NewAPIKeySecret() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// SetSecret save the salted hash of the secret
+func (k *APIKey) SetSecret(secret string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	k.Salt = hex.EncodeToString(salt)
+	k.Secret = hashAPIKeySecret(k.Salt, secret)
+	return nil
+}
+
+// MatchSecret check the secret against the saved hash
+func (k *APIKey) MatchSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.Secret), []byte(hashAPIKeySecret(k.Salt, secret))) == 1
+}
+
+// IsValid the key is enabled and not expired
+func (k *APIKey) IsValid(now time.Time) bool {
+	return k.Enabled && (k.ExpireTime == nil || now.Before(*k.ExpireTime))
+}
+
+// GetUser the user the requests of the key act as, the app code when it is not set
+func (k *APIKey) GetUser() string {
+	if k.User != "" {
+		return k.User
+	}
+	return k.AppCode
+}
+
+func hashAPIKeySecret(salt, secret string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParamAPIKeyCreate the api key to create, never expire when the expire time is not set
+type ParamAPIKeyCreate struct {
+	AppCode    string     `json:"bk_app_code"`
+	OwnerID    string     `json:"bk_supplier_account"`
+	User       string     `json:"bk_username"`
+	ExpireTime *time.Time `json:"expire_time"`
+}
+
+// RspAPIKeyCreate the created api key, the secret is only returned here
+type RspAPIKeyCreate struct {
+	APIKey    `json:",inline"`
+	AppSecret string `json:"bk_app_secret"`
+}
+
+// RspAPIKeySearch the api keys
+type RspAPIKeySearch struct {
+	Count int      `json:"count"`
+	Info  []APIKey `json:"info"`
+}
@@ -12,7 +12,12 @@
 
 package metadata
 
-import types "configcenter/src/common/mapstr"
+import (
+	"fmt"
+	"regexp"
+
+	types "configcenter/src/common/mapstr"
+)
 
 const (
 	// AssociationFieldObjectID the association data field definition
@@ -27,8 +32,106 @@ const (
 	AssociationFieldAssociationObjectID = "bk_asst_obj_id"
 	// AssociationFieldAssociationName the association data field definition
 	AssociationFieldAssociationName = "bk_asst_name"
+	// AssociationFieldAssociationTypeID the association data field definition
+	AssociationFieldAssociationTypeID = "bk_asst_type_id"
+)
+
+const (
+	// AssociationMappingOneToOne one source instance links at most one target instance and the target is linked by at most one source
+	AssociationMappingOneToOne = "1:1"
+	// AssociationMappingOneToMany one source instance links many target instances, every target is linked by at most one source
+	AssociationMappingOneToMany = "1:n"
+	// AssociationMappingManyToMany no limit on both sides
+	AssociationMappingManyToMany = "n:n"
 )
 
+const (
+	// AssociationOnDeleteRestrict forbid to delete the instance which is still associated by others
+	AssociationOnDeleteRestrict = "restrict"
+	// AssociationOnDeleteUnlink remove the associations when the associated instance is deleted
+	AssociationOnDeleteUnlink = "unlink"
+)
+
+var asstTypeIDRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+// AssociationType define a named relation kind between two objects
+type AssociationType struct {
+	ID           int64  `field:"id" json:"id" bson:"id"`
+	AsstTypeID   string `field:"bk_asst_type_id" json:"bk_asst_type_id" bson:"bk_asst_type_id"`
+	AsstTypeName string `field:"bk_asst_type_name" json:"bk_asst_type_name" bson:"bk_asst_type_name"`
+	SrcDes       string `field:"src_des" json:"src_des" bson:"src_des"`
+	DestDes      string `field:"dest_des" json:"dest_des" bson:"dest_des"`
+	ObjectID     string `field:"bk_obj_id" json:"bk_obj_id" bson:"bk_obj_id"`
+	AsstObjID    string `field:"bk_asst_obj_id" json:"bk_asst_obj_id" bson:"bk_asst_obj_id"`
+	Mapping      string `field:"mapping" json:"mapping" bson:"mapping"`
+	OnDelete     string `field:"on_delete" json:"on_delete" bson:"on_delete"`
+	OwnerID      string `field:"bk_supplier_account" json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator      string `field:"creator" json:"creator" bson:"creator"`
+}
+
+// Parse load the data from mapstr attribute into association type instance
+func (cli *AssociationType) Parse(data types.MapStr) (*AssociationType, error) {
+
+	err := SetValueToStructByTags(cli, data)
+	if nil != err {
+		return nil, err
+	}
+
+	return cli, err
+}
+
+// ToMapStr to mapstr
+func (cli *AssociationType) ToMapStr() types.MapStr {
+	return SetValueToMapStrByTags(cli)
+}
+
+// Validate fill the default values and check the association type definition,
+// the returned error names the invalid field
+func (cli *AssociationType) Validate() error {
+	if !asstTypeIDRegexp.MatchString(cli.AsstTypeID) {
+		return fmt.Errorf(AssociationFieldAssociationTypeID)
+	}
+	if 0 == len(cli.ObjectID) {
+		return fmt.Errorf(AssociationFieldObjectID)
+	}
+	if 0 == len(cli.AsstObjID) {
+		return fmt.Errorf(AssociationFieldAssociationObjectID)
+	}
+
+	if 0 == len(cli.Mapping) {
+		cli.Mapping = AssociationMappingManyToMany
+	}
+	switch cli.Mapping {
+	case AssociationMappingOneToOne, AssociationMappingOneToMany, AssociationMappingManyToMany:
+	default:
+		return fmt.Errorf("mapping")
+	}
+
+	if 0 == len(cli.OnDelete) {
+		cli.OnDelete = AssociationOnDeleteRestrict
+	}
+	switch cli.OnDelete {
+	case AssociationOnDeleteRestrict, AssociationOnDeleteUnlink:
+	default:
+		return fmt.Errorf("on_delete")
+	}
+
+	if 0 == len(cli.AsstTypeName) {
+		cli.AsstTypeName = cli.AsstTypeID
+	}
+	return nil
+}
+
+// MultiTargets return true if one source instance could link many target instances
+func (cli *AssociationType) MultiTargets() bool {
+	return AssociationMappingOneToOne != cli.Mapping
+}
+
+// MultiSources return true if one target instance could be linked by many source instances
+func (cli *AssociationType) MultiSources() bool {
+	return AssociationMappingManyToMany == cli.Mapping
+}
+
 // Association define object association struct
 type Association struct {
 	ID               int64  `field:"id" json:"id" bson:"id"`
@@ -38,6 +141,7 @@ type Association struct {
 	AsstObjID        string `field:"bk_asst_obj_id" json:"bk_asst_obj_id" bson:"bk_asst_obj_id"`
 	AsstName         string `field:"bk_asst_name" json:"bk_asst_name" bson:"bk_asst_name"`
 	ObjectAttID      string `field:"bk_object_att_id" json:"bk_object_att_id" bson:"bk_object_att_id"`
+	AsstTypeID       string `field:"bk_asst_type_id" json:"bk_asst_type_id,omitempty" bson:"bk_asst_type_id,omitempty"`
 	ClassificationID string `field:"bk_classification_id" bson:"-"`
 	ObjectIcon       string `field:"bk_obj_icon" bson:"-"`
 	ObjectName       string `field:"bk_obj_name" bson:"-"`
@@ -66,6 +170,7 @@ type InstAsst struct {
 	ObjectID     string `field:"bk_obj_id" json:"bk_obj_id"`
 	AsstInstID   int64  `field:"bk_asst_inst_id" json:"bk_asst_inst_id"`
 	AsstObjectID string `field:"bk_asst_obj_id" json:"bk_asst_obj_id"`
+	AsstTypeID   string `field:"bk_asst_type_id" json:"bk_asst_type_id,omitempty"`
 }
 type InstNameAsst struct {
 	ID         string                 `json:"id"`
@@ -93,6 +198,107 @@ func (cli *InstAsst) ToMapStr() types.MapStr {
 	return SetValueToMapStrByTags(cli)
 }
 
+const (
+	// InstGraphDirectionOut follow the associations from the source instances to the target instances
+	InstGraphDirectionOut = "out"
+	// InstGraphDirectionIn follow the associations from the target instances to the source instances
+	InstGraphDirectionIn = "in"
+	// InstGraphDirectionBoth follow the associations in both directions
+	InstGraphDirectionBoth = "both"
+
+	// AssociationTypeMainline the association type of the edges between the mainline instances,
+	// the child instance is the source and the parent instance is the target, the host is the child of its modules
+	AssociationTypeMainline = "bk_mainline"
+
+	// InstGraphMaxDepth the max hops of the instance graph traversal
+	InstGraphMaxDepth = 10
+	// InstGraphMaxNodes the max nodes of the instance graph, the traversal stops when it is reached
+	InstGraphMaxNodes = 2000
+)
+
+// InstGraphQuery the traversal query across the instance associations
+type InstGraphQuery struct {
+	ObjectID     string                  `json:"bk_obj_id"`
+	Condition    types.MapStr            `json:"condition"`
+	AsstTypeIDs  []string                `json:"bk_asst_type_ids"`
+	ObjectIDs    []string                `json:"bk_obj_ids"`
+	Direction    string                  `json:"direction"`
+	Depth        int                     `json:"depth"`
+	WithMainline bool                    `json:"with_mainline"`
+	NodeFilter   map[string]types.MapStr `json:"node_filter"`
+}
+
+// Validate fill the default values and check the query, the returned error names the invalid field
+func (q *InstGraphQuery) Validate() error {
+	if 0 == len(q.ObjectID) {
+		return fmt.Errorf(AssociationFieldObjectID)
+	}
+
+	if 0 == len(q.Direction) {
+		q.Direction = InstGraphDirectionOut
+	}
+	switch q.Direction {
+	case InstGraphDirectionOut, InstGraphDirectionIn, InstGraphDirectionBoth:
+	default:
+		return fmt.Errorf("direction")
+	}
+
+	if 0 == q.Depth {
+		q.Depth = 1
+	}
+	if q.Depth < 0 || q.Depth > InstGraphMaxDepth {
+		return fmt.Errorf("depth")
+	}
+	return nil
+}
+
+// FollowOut return true if the associations from the source instances should be followed
+func (q *InstGraphQuery) FollowOut() bool {
+	return InstGraphDirectionIn != q.Direction
+}
+
+// FollowIn return true if the associations to the target instances should be followed
+func (q *InstGraphQuery) FollowIn() bool {
+	return InstGraphDirectionOut != q.Direction
+}
+
+// AllowObject return true if the instances of the object could be added into the graph
+func (q *InstGraphQuery) AllowObject(objID string) bool {
+	if 0 == len(q.ObjectIDs) || objID == q.ObjectID {
+		return true
+	}
+	for _, item := range q.ObjectIDs {
+		if item == objID {
+			return true
+		}
+	}
+	return false
+}
+
+// InstGraphNode the instance in the graph, depth is the hops from the start instances
+type InstGraphNode struct {
+	ObjectID string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+	InstName string `json:"bk_inst_name"`
+	Depth    int    `json:"depth"`
+}
+
+// InstGraphEdge the association between two instances in the graph
+type InstGraphEdge struct {
+	ObjectID     string `json:"bk_obj_id"`
+	InstID       int64  `json:"bk_inst_id"`
+	AsstObjectID string `json:"bk_asst_obj_id"`
+	AsstInstID   int64  `json:"bk_asst_inst_id"`
+	AsstTypeID   string `json:"bk_asst_type_id"`
+}
+
+// InstGraph the graph document of the instance graph traversal
+type InstGraph struct {
+	Nodes     []InstGraphNode `json:"nodes"`
+	Edges     []InstGraphEdge `json:"edges"`
+	Truncated bool            `json:"truncated"`
+}
+
 // MainlineObjectTopo the mainline object topo
 type MainlineObjectTopo struct {
 	ObjID      string `field:"bk_obj_id" json:"bk_obj_id"`
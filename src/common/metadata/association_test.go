@@ -0,0 +1,81 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssociationTypeValidate(t *testing.T) {
+	asstType := AssociationType{AsstTypeID: "runs_on", ObjectID: "app", AsstObjID: "host"}
+	require.NoError(t, asstType.Validate())
+	assert.Equal(t, AssociationMappingManyToMany, asstType.Mapping)
+	assert.Equal(t, AssociationOnDeleteRestrict, asstType.OnDelete)
+	assert.Equal(t, "runs_on", asstType.AsstTypeName)
+	assert.True(t, asstType.MultiTargets())
+	assert.True(t, asstType.MultiSources())
+
+	asstType.Mapping = AssociationMappingOneToMany
+	require.NoError(t, asstType.Validate())
+	assert.True(t, asstType.MultiTargets())
+	assert.False(t, asstType.MultiSources())
+
+	asstType.Mapping = AssociationMappingOneToOne
+	require.NoError(t, asstType.Validate())
+	assert.False(t, asstType.MultiTargets())
+	assert.False(t, asstType.MultiSources())
+
+	invalid := []AssociationType{
+		{AsstTypeID: "1runs", ObjectID: "app", AsstObjID: "host"},
+		{AsstTypeID: "runs_on", AsstObjID: "host"},
+		{AsstTypeID: "runs_on", ObjectID: "app"},
+		{AsstTypeID: "runs_on", ObjectID: "app", AsstObjID: "host", Mapping: "n:1"},
+		{AsstTypeID: "runs_on", ObjectID: "app", AsstObjID: "host", OnDelete: "cascade"},
+	}
+	for _, item := range invalid {
+		assert.Error(t, item.Validate(), "%#v", item)
+	}
+}
+
+func TestInstGraphQueryValidate(t *testing.T) {
+	query := InstGraphQuery{ObjectID: "db"}
+	require.NoError(t, query.Validate())
+	assert.Equal(t, InstGraphDirectionOut, query.Direction)
+	assert.Equal(t, 1, query.Depth)
+	assert.True(t, query.FollowOut())
+	assert.False(t, query.FollowIn())
+
+	query.Direction = InstGraphDirectionBoth
+	require.NoError(t, query.Validate())
+	assert.True(t, query.FollowOut())
+	assert.True(t, query.FollowIn())
+
+	assert.True(t, query.AllowObject("host"))
+	query.ObjectIDs = []string{"host"}
+	assert.True(t, query.AllowObject("host"))
+	assert.True(t, query.AllowObject("db"))
+	assert.False(t, query.AllowObject("switch"))
+
+	invalid := []InstGraphQuery{
+		{},
+		{ObjectID: "db", Direction: "up"},
+		{ObjectID: "db", Depth: -1},
+		{ObjectID: "db", Depth: InstGraphMaxDepth + 1},
+	}
+	for _, item := range invalid {
+		assert.Error(t, item.Validate(), "%#v", item)
+	}
+}
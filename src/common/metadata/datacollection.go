@@ -0,0 +1,145 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"configcenter/src/common"
+)
+
+const (
+	// HostSnapTransformFirst take the first value of the array
+	HostSnapTransformFirst = "first"
+	// HostSnapTransformSum sum the numbers of the array
+	HostSnapTransformSum = "sum"
+	// HostSnapTransformScale divide the number by the scale, e.g. 1024 to convert KB to MB
+	HostSnapTransformScale = "scale"
+	// HostSnapTransformRegexReplace replace the matches of the pattern in the string
+	HostSnapTransformRegexReplace = "regex_replace"
+)
+
+const (
+	HostSnapValueTypeString = "string"
+	HostSnapValueTypeInt    = "int"
+	HostSnapValueTypeFloat  = "float"
+)
+
+// HostSnapFieldRule how a host attribute is filled from the host snapshot reported by the agent,
+// the value at the json path is transformed step by step and converted to the value type
+type HostSnapFieldRule struct {
+	PropertyID string              `json:"bk_property_id" bson:"bk_property_id"`
+	Path       string              `json:"path" bson:"path"`
+	Transforms []HostSnapTransform `json:"transforms" bson:"transforms"`
+	ValueType  string              `json:"value_type" bson:"value_type"`
+	Enable     bool                `json:"enable" bson:"enable"`
+	OwnerID    string              `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Builtin    bool                `json:"builtin" bson:"-"`
+	Modifier   string              `json:"modifier,omitempty" bson:"modifier"`
+	LastTime   *time.Time          `json:"last_time,omitempty" bson:"last_time"`
+}
+
+// HostSnapTransform a step to transform the value of the host snapshot
+type HostSnapTransform struct {
+	Type    string  `json:"type" bson:"type"`
+	Scale   float64 `json:"scale,omitempty" bson:"scale,omitempty"`
+	Pattern string  `json:"pattern,omitempty" bson:"pattern,omitempty"`
+	Replace string  `json:"replace,omitempty" bson:"replace,omitempty"`
+}
+
+// hostSnapIdentityFields the host fields which the snapshot is matched with, no rule can fill them
+var hostSnapIdentityFields = []string{
+	common.BKHostIDField,
+	common.BKHostInnerIPField,
+	common.BKCloudIDField,
+	common.BKOwnerIDField,
+}
+
+// Validate check the rule, the path is not required when the rule is disabled,
+// a disabled rule stops the attribute from being filled
+func (r *HostSnapFieldRule) Validate() error {
+	if "" == r.PropertyID {
+		return fmt.Errorf("bk_property_id is required")
+	}
+	for _, field := range hostSnapIdentityFields {
+		if field == r.PropertyID {
+			return fmt.Errorf("%s identifies the host, it can not be filled from the snapshot", field)
+		}
+	}
+	if !r.Enable {
+		return nil
+	}
+	if "" == r.Path {
+		return fmt.Errorf("path is required")
+	}
+	switch r.ValueType {
+	case "", HostSnapValueTypeString, HostSnapValueTypeInt, HostSnapValueTypeFloat:
+	default:
+		return fmt.Errorf("value_type %s is not supported", r.ValueType)
+	}
+	for idx, transform := range r.Transforms {
+		switch transform.Type {
+		case HostSnapTransformFirst, HostSnapTransformSum:
+		case HostSnapTransformScale:
+			if 0 == transform.Scale {
+				return fmt.Errorf("transforms[%d] scale can not be 0", idx)
+			}
+		case HostSnapTransformRegexReplace:
+			if _, err := regexp.Compile(transform.Pattern); nil != err {
+				return fmt.Errorf("transforms[%d] pattern is invalid, %v", idx, err)
+			}
+		default:
+			return fmt.Errorf("transforms[%d] type %s is not supported", idx, transform.Type)
+		}
+	}
+	return nil
+}
+
+// HostSnapFieldRulesResult the host snapshot field rules
+type HostSnapFieldRulesResult struct {
+	BaseResp `json:",inline"`
+	Data     []HostSnapFieldRule `json:"data"`
+}
+
+// HostSnapHistory the host attributes collected from the snapshots in a time bucket, data is
+// the values at the first snapshot in the bucket, and the changes after it are appended to changes
+type HostSnapHistory struct {
+	HostID     int64                  `json:"bk_host_id" bson:"bk_host_id"`
+	OwnerID    string                 `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	BucketTime time.Time              `json:"bucket_time" bson:"bucket_time"`
+	LastTime   time.Time              `json:"last_time" bson:"last_time"`
+	Data       map[string]interface{} `json:"data" bson:"data"`
+	Changes    []HostSnapChange       `json:"changes" bson:"changes"`
+}
+
+// HostSnapChange a host attribute changed by the snapshot
+type HostSnapChange struct {
+	Time       time.Time   `json:"time" bson:"time"`
+	PropertyID string      `json:"bk_property_id" bson:"bk_property_id"`
+	PreValue   interface{} `json:"pre_value" bson:"pre_value"`
+	CurValue   interface{} `json:"cur_value" bson:"cur_value"`
+}
+
+// HostSnapHistoryParams the time range of the host snapshot history
+type HostSnapHistoryParams struct {
+	StartTime *time.Time `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+}
+
+// HostSnapHistoryResult the host snapshot history
+type HostSnapHistoryResult struct {
+	BaseResp `json:",inline"`
+	Data     []HostSnapHistory `json:"data"`
+}
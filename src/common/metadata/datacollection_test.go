@@ -0,0 +1,50 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestHostSnapFieldRuleValidate(t *testing.T) {
+	cases := []struct {
+		rule  HostSnapFieldRule
+		valid bool
+	}{
+		{HostSnapFieldRule{PropertyID: "bk_mem"}, true},
+		{HostSnapFieldRule{Path: "data.mem.meminfo.total", Enable: true}, false},
+		{HostSnapFieldRule{PropertyID: "bk_mem", Enable: true}, false},
+		{HostSnapFieldRule{PropertyID: "bk_mem", Path: "data.mem.meminfo.total", ValueType: "bool", Enable: true}, false},
+		{HostSnapFieldRule{PropertyID: "bk_mem", Path: "data.mem.meminfo.total", Enable: true,
+			Transforms: []HostSnapTransform{{Type: HostSnapTransformScale}}}, false},
+		{HostSnapFieldRule{PropertyID: "kernel", Path: "data.system.info.kernelVersion", Enable: true,
+			Transforms: []HostSnapTransform{{Type: HostSnapTransformRegexReplace, Pattern: "("}}}, false},
+		{HostSnapFieldRule{PropertyID: "bk_mem", Path: "data.mem.meminfo.total", Enable: true,
+			Transforms: []HostSnapTransform{{Type: "avg"}}}, false},
+		{HostSnapFieldRule{PropertyID: "bk_disk", Path: "data.disk.usage.#.total", ValueType: HostSnapValueTypeInt, Enable: true,
+			Transforms: []HostSnapTransform{{Type: HostSnapTransformSum}, {Type: HostSnapTransformScale, Scale: 1024}}}, true},
+		{HostSnapFieldRule{PropertyID: "bk_host_innerip", Path: "data.net.interface.0.addrs.0.addr", Enable: true}, false},
+		{HostSnapFieldRule{PropertyID: "bk_cloud_id", Path: "cloudid", ValueType: HostSnapValueTypeInt, Enable: true}, false},
+		{HostSnapFieldRule{PropertyID: "bk_host_id", Path: "hostid"}, false},
+		{HostSnapFieldRule{PropertyID: "bk_supplier_account", Path: "ownerid", Enable: true}, false},
+	}
+	for idx, c := range cases {
+		err := c.rule.Validate()
+		if c.valid && err != nil {
+			t.Errorf("case %d: expect valid but got %v", idx, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("case %d: expect invalid", idx)
+		}
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"strings"
 	"time"
 
+	"gopkg.in/mgo.v2/bson"
+
 	"configcenter/src/common/types"
 )
 
@@ -56,24 +58,208 @@ type RspSubscriptionTestCallback struct {
 
 // Subscription define
 type Subscription struct {
-	SubscriptionID   int64       `bson:"subscription_id" json:"subscription_id"`
-	SubscriptionName string      `bson:"subscription_name" json:"subscription_name"`
-	SystemName       string      `bson:"system_name" json:"system_name"`
-	CallbackURL      string      `bson:"callback_url" json:"callback_url"`
-	ConfirmMode      string      `bson:"confirm_mode" json:"confirm_mode"`
-	ConfirmPattern   string      `bson:"confirm_pattern" json:"confirm_pattern"`
-	TimeOut          int64       `bson:"time_out" json:"time_out"`                   // second
-	SubscriptionForm string      `bson:"subscription_form" json:"subscription_form"` // json format
-	Operator         string      `bson:"operator" json:"operator"`
-	OwnerID          string      `bson:"bk_supplier_account" json:"bk_supplier_account"`
-	LastTime         *types.Time `bson:"last_time" json:"last_time"`
-	Statistics       *Statistics `bson:"-" json:"statistics"`
+	SubscriptionID   int64        `bson:"subscription_id" json:"subscription_id"`
+	SubscriptionName string       `bson:"subscription_name" json:"subscription_name"`
+	SystemName       string       `bson:"system_name" json:"system_name"`
+	CallbackURL      string       `bson:"callback_url" json:"callback_url"`
+	ConfirmMode      string       `bson:"confirm_mode" json:"confirm_mode"`
+	ConfirmPattern   string       `bson:"confirm_pattern" json:"confirm_pattern"`
+	TimeOut          int64        `bson:"time_out" json:"time_out"`                   // second
+	SubscriptionForm string       `bson:"subscription_form" json:"subscription_form"` // json format
+	Operator         string       `bson:"operator" json:"operator"`
+	OwnerID          string       `bson:"bk_supplier_account" json:"bk_supplier_account"`
+	LastTime         *types.Time  `bson:"last_time" json:"last_time"`
+	RetryPolicy      *RetryPolicy `bson:"retry_policy" json:"retry_policy"`
+	// Secret the key to sign the callbacks, keep the current one when update with an empty secret
+	Secret string `bson:"secret" json:"secret,omitempty"`
+	// ClearSecret remove the secrets when update, the callbacks are not signed any more
+	ClearSecret bool `bson:"-" json:"clear_secret,omitempty"`
+	// SecretGracePeriod how long the previous secret is still valid after the secret rotated, second
+	SecretGracePeriod int64 `bson:"secret_grace_period" json:"secret_grace_period"`
+	// PreviousSecret the rotated secret, callbacks are also signed by it before PreviousSecretExpire
+	PreviousSecret       string      `bson:"previous_secret" json:"previous_secret,omitempty"`
+	PreviousSecretExpire *types.Time `bson:"previous_secret_expire" json:"previous_secret_expire,omitempty"`
+	// DeliveryType how the events are delivered, DeliveryTypeHTTP by default
+	DeliveryType string `bson:"delivery_type" json:"delivery_type"`
+	// DeliveryTarget where the events are delivered, e.g. the stream name of DeliveryTypeRedisStream,
+	// the CallbackURL is used for DeliveryTypeHTTP
+	DeliveryTarget string `bson:"delivery_target" json:"delivery_target"`
+	// BatchSize the max events sent in one callback as a json array, the events are sent one by one when it is not greater than 1
+	BatchSize int `bson:"batch_size" json:"batch_size"`
+	// FlushInterval how long a batch waits for more events before it is sent, millisecond
+	FlushInterval int64 `bson:"flush_interval" json:"flush_interval"`
+	// Filter narrow down the events of SubscriptionForm, all the events are sent when it is nil
+	Filter     *SubscriptionFilter `bson:"filter" json:"filter"`
+	Statistics *Statistics         `bson:"-" json:"statistics"`
+}
+
+// RetryPolicy define how a failed callback should be retried
+type RetryPolicy struct {
+	MaxAttempts    int     `bson:"max_attempts" json:"max_attempts"`
+	InitialBackoff int64   `bson:"initial_backoff" json:"initial_backoff"` // millisecond
+	MaxBackoff     int64   `bson:"max_backoff" json:"max_backoff"`         // millisecond
+	Jitter         float64 `bson:"jitter" json:"jitter"`                   // 0 ~ 1, the random part of every backoff
+}
+
+// RetryMaxAttempts the upper limit of the max attempts of the retry policy
+const RetryMaxAttempts = 20
+
+// Validate returns the invalid field of the retry policy, the empty policy is valid
+func (r *RetryPolicy) Validate() (string, bool) {
+	if r == nil {
+		return "", true
+	}
+	if r.MaxAttempts < 0 || r.MaxAttempts > RetryMaxAttempts {
+		return "retry_policy.max_attempts", false
+	}
+	if r.InitialBackoff < 0 {
+		return "retry_policy.initial_backoff", false
+	}
+	if r.MaxBackoff < 0 || (r.MaxBackoff > 0 && r.MaxBackoff < r.InitialBackoff) {
+		return "retry_policy.max_backoff", false
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return "retry_policy.jitter", false
+	}
+	return "", true
+}
+
+// GetMaxAttempts returns the max attempts of a callback, at least 1
+func (r *RetryPolicy) GetMaxAttempts() int {
+	if r == nil || r.MaxAttempts <= 0 {
+		return 1
+	}
+	return r.MaxAttempts
+}
+
+// GetBackoff returns the exponential backoff before the retry of the given attempt,
+// attempt starts from 1, jitter is not included
+func (r *RetryPolicy) GetBackoff(attempt int) time.Duration {
+	if r == nil || r.InitialBackoff <= 0 || attempt <= 0 {
+		return 0
+	}
+	backoff := time.Duration(r.InitialBackoff) * time.Millisecond
+	maxBackoff := time.Duration(r.MaxBackoff) * time.Millisecond
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if maxBackoff > 0 && backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// DeadLetter define the event which still fails after all the retries
+type DeadLetter struct {
+	DistInst `json:",inline"`
+	// Batch all the events which failed together when the subscription sends in batch,
+	// the DistInst is the first one of them
+	Batch      []DistInst `json:"batch,omitempty"`
+	Attempts   int        `json:"attempts"`
+	Error      string     `json:"error"`
+	FailedTime types.Time `json:"failed_time"`
+}
+
+type ParamDeadLetterSearch struct {
+	Page BasePage `json:"page"`
+}
+
+type RspDeadLetterSearch struct {
+	Count int64        `json:"count"`
+	Info  []DeadLetter `json:"info"`
+}
+
+type ParamDeadLetterReplay struct {
+	// DistIDs the distribution ids of the dead letters to replay, replay all when empty
+	DistIDs []int64 `json:"distribution_ids"`
+}
+
+type RspDeadLetterReplay struct {
+	Count int64 `json:"count"`
+}
+
+// SubscriptionFilter define which events should be sent to the subscriber, every non empty item should match
+type SubscriptionFilter struct {
+	BizIDs []int64  `json:"bk_biz_ids"`
+	ObjIDs []string `json:"bk_obj_ids"`
+	// ChangedFields the update events are sent only when any of these fields changed
+	ChangedFields []string `json:"changed_fields"`
+	// Condition the db style condition which the event data should match, e.g. {"bk_os_type":"1"}
+	Condition map[string]interface{} `json:"condition"`
+}
+
+// subscriptionFilterBSON the storage format of SubscriptionFilter,
+// the condition is saved as json because the db does not accept keys start with "$"
+type subscriptionFilterBSON struct {
+	BizIDs        []int64  `bson:"bk_biz_ids"`
+	ObjIDs        []string `bson:"bk_obj_ids"`
+	ChangedFields []string `bson:"changed_fields"`
+	Condition     string   `bson:"condition"`
+}
+
+// GetBSON implement bson.Getter interface
+func (f SubscriptionFilter) GetBSON() (interface{}, error) {
+	out := subscriptionFilterBSON{
+		BizIDs:        f.BizIDs,
+		ObjIDs:        f.ObjIDs,
+		ChangedFields: f.ChangedFields,
+	}
+	if len(f.Condition) > 0 {
+		cond, err := json.Marshal(f.Condition)
+		if err != nil {
+			return nil, err
+		}
+		out.Condition = string(cond)
+	}
+	return out, nil
+}
+
+// SetBSON implement bson.Setter interface
+func (f *SubscriptionFilter) SetBSON(raw bson.Raw) error {
+	in := subscriptionFilterBSON{}
+	if err := raw.Unmarshal(&in); err != nil {
+		return err
+	}
+	f.BizIDs = in.BizIDs
+	f.ObjIDs = in.ObjIDs
+	f.ChangedFields = in.ChangedFields
+	f.Condition = nil
+	if in.Condition != "" {
+		return json.Unmarshal([]byte(in.Condition), &f.Condition)
+	}
+	return nil
 }
 
 // Report define sending statistic
 type Statistics struct {
 	Total   int64 `json:"total"`
 	Failure int64 `json:"failure"`
+	// P50Latency P95Latency the latency percentiles of the recent deliveries, millisecond
+	P50Latency int64 `json:"p50_latency"`
+	P95Latency int64 `json:"p95_latency"`
+}
+
+// DeliveryRecord define one delivery attempt of the subscription
+type DeliveryRecord struct {
+	DistributionID int64 `json:"distribution_id"`
+	// EventIDs the events delivered, more than one when the subscription sends in batch
+	EventIDs   []int64 `json:"event_ids"`
+	Attempt    int     `json:"attempt"`
+	Status     string  `json:"status"`
+	HTTPStatus int     `json:"http_status"`
+	Duration   int64   `json:"duration"` // millisecond
+	// Response the truncated response body of the receiver
+	Response     string     `json:"response"`
+	Error        string     `json:"error"`
+	DeliveryTime types.Time `json:"delivery_time"`
+}
+
+type RspDeliverySearch struct {
+	Count int64            `json:"count"`
+	Info  []DeliveryRecord `json:"info"`
 }
 
 func (Subscription) TableName() string {
@@ -91,6 +277,17 @@ func (s Subscription) GetCacheKey() string {
 		ConfirmPattern:   s.ConfirmPattern,
 		SubscriptionForm: s.SubscriptionForm,
 		TimeOut:          s.TimeOut,
+		RetryPolicy:      s.RetryPolicy,
+		DeliveryType:     s.GetDeliveryType(),
+		DeliveryTarget:   s.DeliveryTarget,
+		BatchSize:        s.GetBatchSize(),
+		FlushInterval:    s.FlushInterval,
+		Secret:           s.Secret,
+		PreviousSecret:   s.PreviousSecret,
+	}
+	if s.PreviousSecretExpire != nil {
+		expire := *s.PreviousSecretExpire
+		ns.PreviousSecretExpire = &expire
 	}
 	b, _ := json.Marshal(ns)
 	return string(b)
@@ -100,6 +297,54 @@ func (s Subscription) GetTimeout() time.Duration {
 	return time.Second * time.Duration(s.TimeOut)
 }
 
+// GetDeliveryType returns the delivery type, DeliveryTypeHTTP when it is not set
+func (s Subscription) GetDeliveryType() string {
+	if s.DeliveryType == "" {
+		return DeliveryTypeHTTP
+	}
+	return s.DeliveryType
+}
+
+// GetBatchSize returns the max events sent in one callback, at least 1
+func (s Subscription) GetBatchSize() int {
+	if s.BatchSize <= 1 {
+		return 1
+	}
+	return s.BatchSize
+}
+
+// IsBatch returns whether the events are sent in batch
+func (s Subscription) IsBatch() bool {
+	return s.GetBatchSize() > 1
+}
+
+// GetFlushInterval returns how long a batch waits for more events, DefaultFlushInterval when it is not set
+func (s Subscription) GetFlushInterval() time.Duration {
+	if s.FlushInterval <= 0 {
+		return DefaultFlushInterval
+	}
+	return time.Duration(s.FlushInterval) * time.Millisecond
+}
+
+// GetSigningSecrets returns the secrets which should sign the callback at the given time,
+// the previous secret is included only in its grace period
+func (s Subscription) GetSigningSecrets(now time.Time) []string {
+	secrets := []string{}
+	if s.Secret != "" {
+		secrets = append(secrets, s.Secret)
+	}
+	if s.PreviousSecret != "" && s.PreviousSecretExpire != nil && now.Before(s.PreviousSecretExpire.Time) {
+		secrets = append(secrets, s.PreviousSecret)
+	}
+	return secrets
+}
+
+// HideSecrets clear the secrets, so that they would not be returned to the caller
+func (s *Subscription) HideSecrets() {
+	s.Secret = ""
+	s.PreviousSecret = ""
+}
+
 type EventInst struct {
 	ID          int64       `json:"event_id,omitempty"`
 	EventType   string      `json:"event_type"`
@@ -117,12 +362,12 @@ func (e *EventInst) MarshalBinary() (data []byte, err error) {
 }
 
 type EventData struct {
-	CurData interface{} `json:"cur_data"`
-	PreData interface{} `json:"pre_data"`
+	CurData interface{} `bson:"cur_data" json:"cur_data"`
+	PreData interface{} `bson:"pre_data" json:"pre_data"`
 }
 
 func (e *EventInst) GetType() string {
-	if e.EventType == EventTypeRelation {
+	if e.EventType == EventTypeRelation || e.EventType == EventTypeDynamicGroup {
 		return e.ObjType
 	}
 	return e.ObjType + e.Action
@@ -144,11 +389,59 @@ type DistInstCtx struct {
 	Raw string
 }
 
+// EventHistory the distributed event persisted in db, so that it could be pulled by cursor
+type EventHistory struct {
+	// Cursor the monotonic id of the persisted events
+	Cursor int64 `bson:"cursor" json:"cursor"`
+	// EventName the name of the event, same as the names in SubscriptionForm, e.g. hostupdate
+	EventName   string      `bson:"event_name" json:"event_name"`
+	EventID     int64       `bson:"event_id" json:"event_id"`
+	EventType   string      `bson:"event_type" json:"event_type"`
+	Action      string      `bson:"action" json:"action"`
+	ActionTime  time.Time   `bson:"action_time" json:"action_time"`
+	ObjType     string      `bson:"obj_type" json:"obj_type"`
+	Data        []EventData `bson:"data" json:"data"`
+	OwnerID     string      `bson:"bk_supplier_account" json:"bk_supplier_account"`
+	RequestID   string      `bson:"request_id" json:"request_id"`
+	RequestTime time.Time   `bson:"request_time" json:"request_time"`
+	CreateTime  time.Time   `bson:"create_time" json:"create_time"`
+}
+
+// NewEventHistory create the event history of the dist
+func NewEventHistory(cursor int64, dist *DistInst) *EventHistory {
+	return &EventHistory{
+		Cursor:      cursor,
+		EventName:   dist.GetType(),
+		EventID:     dist.EventInst.ID,
+		EventType:   dist.EventType,
+		Action:      dist.Action,
+		ActionTime:  dist.ActionTime.Time,
+		ObjType:     dist.ObjType,
+		Data:        dist.Data,
+		OwnerID:     dist.OwnerID,
+		RequestID:   dist.RequestID,
+		RequestTime: dist.RequestTime.Time,
+		CreateTime:  time.Now().UTC(),
+	}
+}
+
+// RspEventPull the events after the cursor, Cursor is the last one of the events,
+// it is the same as the request cursor when no more events
+type RspEventPull struct {
+	Cursor int64          `json:"cursor"`
+	Info   []EventHistory `json:"info"`
+}
+
 // EventAction
 const (
 	EventActionCreate = "create"
 	EventActionUpdate = "update"
 	EventActionDelete = "delete"
+
+	// EventActionJoin the host joins the dynamic group
+	EventActionJoin = "join"
+	// EventActionLeave the host leaves the dynamic group
+	EventActionLeave = "leave"
 )
 
 // EventType define
@@ -159,8 +452,40 @@ const (
 	EventTypeInstData           = "instdata"
 	EventTypeRelation           = "relation"
 	EventTypeResourcePoolModule = "resource"
+	// EventTypeDynamicGroup the membership changes of the dynamic groups
+	EventTypeDynamicGroup = "dynamicgroup"
 )
 
+// the http headers of a signed callback, the signature is the hex encoded HMAC-SHA256
+// of "{timestamp}.{body}", multiple signatures are split by comma while the secret is rotating
+const (
+	EventCallbackTimestampHeader = "X-Bk-Cmdb-Timestamp"
+	EventCallbackSignatureHeader = "X-Bk-Cmdb-Signature"
+
+	// EventCallbackSignaturePrefix prefix of every signature
+	EventCallbackSignaturePrefix = "sha256="
+
+	// DefaultSecretGracePeriod the default grace period of the previous secret, second
+	DefaultSecretGracePeriod = 24 * 60 * 60
+)
+
+// DeliveryType define
+const (
+	// DeliveryTypeHTTP post the events to the CallbackURL
+	DeliveryTypeHTTP = "http"
+	// DeliveryTypeRedisStream add the events to the redis stream named by DeliveryTarget
+	DeliveryTypeRedisStream = "redis_stream"
+)
+
+// DeliveryStatus define
+const (
+	DeliveryStatusSuccess = "success"
+	DeliveryStatusFailure = "failure"
+)
+
+// DefaultFlushInterval the default time a batch waits for more events
+const DefaultFlushInterval = time.Second
+
 // ConfirmMode define
 type ConfirmMode string
 
@@ -0,0 +1,130 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"configcenter/src/common/types"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var nilPolicy *RetryPolicy
+	if nilPolicy.GetMaxAttempts() != 1 {
+		t.Errorf("nil policy should attempt once, got %d", nilPolicy.GetMaxAttempts())
+	}
+	if nilPolicy.GetBackoff(1) != 0 {
+		t.Errorf("nil policy should not backoff, got %v", nilPolicy.GetBackoff(1))
+	}
+
+	policy := &RetryPolicy{MaxAttempts: 5, InitialBackoff: 100, MaxBackoff: 500}
+	expects := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		500 * time.Millisecond,
+		500 * time.Millisecond,
+	}
+	for index, expect := range expects {
+		if backoff := policy.GetBackoff(index + 1); backoff != expect {
+			t.Errorf("attempt %d expect backoff %v, got %v", index+1, expect, backoff)
+		}
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		policy *RetryPolicy
+		field  string
+	}{
+		{nil, ""},
+		{&RetryPolicy{}, ""},
+		{&RetryPolicy{MaxAttempts: 5, InitialBackoff: 100, MaxBackoff: 500, Jitter: 0.2}, ""},
+		{&RetryPolicy{MaxAttempts: 5, InitialBackoff: 100}, ""},
+		{&RetryPolicy{MaxAttempts: -1}, "retry_policy.max_attempts"},
+		{&RetryPolicy{MaxAttempts: RetryMaxAttempts + 1}, "retry_policy.max_attempts"},
+		{&RetryPolicy{InitialBackoff: -1}, "retry_policy.initial_backoff"},
+		{&RetryPolicy{MaxBackoff: -1}, "retry_policy.max_backoff"},
+		{&RetryPolicy{InitialBackoff: 500, MaxBackoff: 100}, "retry_policy.max_backoff"},
+		{&RetryPolicy{Jitter: 1.5}, "retry_policy.jitter"},
+		{&RetryPolicy{Jitter: -0.1}, "retry_policy.jitter"},
+	}
+	for index, test := range tests {
+		field, ok := test.policy.Validate()
+		if field != test.field || ok != (test.field == "") {
+			t.Errorf("case %d expect invalid field %q, got %q, %v", index, test.field, field, ok)
+		}
+	}
+}
+
+func TestSubscriptionSigningSecrets(t *testing.T) {
+	now := time.Now()
+	sub := Subscription{}
+	if secrets := sub.GetSigningSecrets(now); len(secrets) != 0 {
+		t.Errorf("subscription without secret should not be signed, got %v", secrets)
+	}
+
+	expire := types.Time{Time: now.Add(time.Minute)}
+	sub = Subscription{Secret: "new", PreviousSecret: "old", PreviousSecretExpire: &expire}
+	if secrets := sub.GetSigningSecrets(now); len(secrets) != 2 || secrets[0] != "new" || secrets[1] != "old" {
+		t.Errorf("both secrets should be valid in grace period, got %v", secrets)
+	}
+	if secrets := sub.GetSigningSecrets(now.Add(time.Hour)); len(secrets) != 1 || secrets[0] != "new" {
+		t.Errorf("previous secret should be expired, got %v", secrets)
+	}
+}
+
+func TestSubscriptionFilterBSON(t *testing.T) {
+	sub := Subscription{
+		SubscriptionID: 1,
+		Filter: &SubscriptionFilter{
+			BizIDs:    []int64{2},
+			Condition: map[string]interface{}{"bk_cpu": map[string]interface{}{"$gt": float64(4)}},
+		},
+	}
+	out, err := bson.Marshal(sub)
+	if err != nil {
+		t.Fatalf("marshal subscription failed: %v", err)
+	}
+	result := Subscription{}
+	if err := bson.Unmarshal(out, &result); err != nil {
+		t.Fatalf("unmarshal subscription failed: %v", err)
+	}
+	if result.Filter == nil || !reflect.DeepEqual(sub.Filter.BizIDs, result.Filter.BizIDs) ||
+		!reflect.DeepEqual(sub.Filter.Condition, result.Filter.Condition) {
+		t.Errorf("expect filter %#v, got %#v", sub.Filter, result.Filter)
+	}
+}
+
+func TestSubscriptionBatch(t *testing.T) {
+	sub := Subscription{}
+	if sub.IsBatch() || sub.GetBatchSize() != 1 {
+		t.Errorf("empty subscription should not send in batch, got batch size %d", sub.GetBatchSize())
+	}
+	if sub.GetFlushInterval() != DefaultFlushInterval {
+		t.Errorf("flush interval should be %v, got %v", DefaultFlushInterval, sub.GetFlushInterval())
+	}
+
+	sub.BatchSize = 50
+	sub.FlushInterval = 200
+	if !sub.IsBatch() || sub.GetBatchSize() != 50 {
+		t.Errorf("subscription should send in batch of 50, got batch size %d", sub.GetBatchSize())
+	}
+	if sub.GetFlushInterval() != 200*time.Millisecond {
+		t.Errorf("flush interval should be 200ms, got %v", sub.GetFlushInterval())
+	}
+}
@@ -140,6 +140,10 @@ type UserConfig struct {
 	AppID      int64     `json:"bk_biz_id" bson:"bk_biz_id"`
 	CreateUser string    `json:"create_user" bson:"create_user"`
 	ModifyUser string    `json:"modify_user" bson:"modify_user"`
+	// Dynamic the hosts of the query are evaluated on a schedule as a dynamic group
+	Dynamic bool `json:"dynamic" bson:"dynamic"`
+	// EvalInterval the interval of the evaluation in seconds, the default interval is used when it is 0
+	EvalInterval int64 `json:"eval_interval" bson:"eval_interval"`
 }
 
 type UserConfigResult struct {
@@ -194,11 +198,84 @@ type UserConfigMeta struct {
 	ModifyUser string    `json:"modify_user" bson:"modify_user,omitempty"`
 	UpdateTime time.Time `json:"last_time" bson:"last_time,omitempty"`
 	OwnerID    string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+
+	Dynamic      *bool      `json:"dynamic,omitempty" bson:"dynamic,omitempty"`
+	EvalInterval *int64     `json:"eval_interval,omitempty" bson:"eval_interval,omitempty"`
+	EvalTime     *time.Time `json:"eval_time,omitempty" bson:"eval_time,omitempty"`
+}
+
+// IsDynamic whether the query is a dynamic group
+func (u UserConfigMeta) IsDynamic() bool {
+	return nil != u.Dynamic && *u.Dynamic
+}
+
+// IsEvalDue whether the dynamic group should be evaluated at the time
+func (u UserConfigMeta) IsEvalDue(now time.Time, defaultInterval time.Duration) bool {
+	if nil == u.EvalTime {
+		return true
+	}
+	interval := defaultInterval
+	if nil != u.EvalInterval && 0 < *u.EvalInterval {
+		interval = time.Duration(*u.EvalInterval) * time.Second
+	}
+	return !now.Before(u.EvalTime.Add(interval))
 }
 
 type AddConfigQuery struct {
-	AppID      int64  `json:"bk_biz_id,omitempty"`
-	Info       string `json:"info,omitempty"`
-	Name       string `json:"name,omitempty"`
-	CreateUser string `json:"create_user,omitempty"`
+	AppID        int64  `json:"bk_biz_id,omitempty"`
+	Info         string `json:"info,omitempty"`
+	Name         string `json:"name,omitempty"`
+	CreateUser   string `json:"create_user,omitempty"`
+	Dynamic      bool   `json:"dynamic,omitempty"`
+	EvalInterval int64  `json:"eval_interval,omitempty"`
+}
+
+// DynamicGroupSnapshot the hosts of the dynamic group at its last evaluation
+type DynamicGroupSnapshot struct {
+	ID       string    `json:"id" bson:"id"`
+	AppID    int64     `json:"bk_biz_id" bson:"bk_biz_id"`
+	HostIDs  []int64   `json:"bk_host_id" bson:"bk_host_id"`
+	EvalTime time.Time `json:"eval_time" bson:"eval_time"`
+	OwnerID  string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+type DynamicGroupSnapshotResult struct {
+	BaseResp `json:",inline"`
+	Data     DynamicGroupSnapshot `json:"data"`
+}
+
+// DynamicGroupSnapshotParams the hosts of the dynamic group evaluated
+type DynamicGroupSnapshotParams struct {
+	HostIDs []int64 `json:"bk_host_id"`
+	// Scheduled the evaluation is run by the schedule of every host server, the snapshot is saved only
+	// if the eval_time of the group is still PreEvalTime, so that one evaluation of them is saved
+	Scheduled   bool       `json:"scheduled,omitempty"`
+	PreEvalTime *time.Time `json:"pre_eval_time,omitempty"`
+}
+
+// DynamicGroupDelta the hosts which joined and left the dynamic group in an evaluation
+type DynamicGroupDelta struct {
+	Joined []int64 `json:"joined"`
+	Left   []int64 `json:"left"`
+	// Skipped the scheduled evaluation is not saved since the group is evaluated by another host server
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+type DynamicGroupDeltaResult struct {
+	BaseResp `json:",inline"`
+	Data     DynamicGroupDelta `json:"data"`
+}
+
+type DynamicGroupsResult struct {
+	BaseResp `json:",inline"`
+	Data     []UserConfigMeta `json:"data"`
+}
+
+// DynamicGroupMember the data of the events of the dynamic group, a host joins
+// the group when it is the cur_data, and leaves the group when it is the pre_data
+type DynamicGroupMember struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	AppID  int64  `json:"bk_biz_id"`
+	HostID int64  `json:"bk_host_id"`
 }
@@ -13,6 +13,10 @@
 package metadata
 
 import (
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
 	"configcenter/src/common/mapstr"
 )
 
@@ -91,6 +95,22 @@ type HostCommonSearch struct {
 	Condition []SearchCondition `json:"condition"`
 	Page      BasePage          `json:"page"`
 	Pattern   string            `json:"pattern,omitempty"`
+	// Filter the nested condition, ANDed with the conditions above
+	Filter *SearchConditionTree `json:"filter,omitempty"`
+	// NotReportedHours only the hosts whose agents have not reported in the hours, 0 means no limit
+	NotReportedHours int64 `json:"not_reported_hours,omitempty"`
+}
+
+// NotReportedCondition the condition of the hosts whose agents have not reported since the hours
+// before now, including the hosts which never reported
+func NotReportedCondition(hours int64, now time.Time) map[string]interface{} {
+	before := now.UTC().Add(-time.Duration(hours) * time.Hour)
+	return map[string]interface{}{
+		common.BKDBOR: []interface{}{
+			map[string]interface{}{common.BKLastReportTimeField: map[string]interface{}{common.BKDBLT: before}},
+			map[string]interface{}{common.BKLastReportTimeField: nil},
+		},
+	}
 }
 
 //ip search info
@@ -107,6 +127,92 @@ type SearchCondition struct {
 	ObjectID  string          `json:"bk_obj_id"`
 }
 
+// the operators of the groups in the SearchConditionTree
+const (
+	SearchTreeAnd = "and"
+	SearchTreeOr  = "or"
+	SearchTreeNot = "not"
+
+	// SearchTreeMaxDepth the max depth of the SearchConditionTree
+	SearchTreeMaxDepth = 10
+)
+
+// SearchConditionTree the nested condition of the host search. A group has the operator, and
+// combines its children with and, or, or not for the only child. A leaf has no operator, and
+// matches the hosts of the instances of the object which match all its condition items.
+type SearchConditionTree struct {
+	Operator  string                `json:"operator,omitempty"`
+	Children  []SearchConditionTree `json:"children,omitempty"`
+	ObjectID  string                `json:"bk_obj_id,omitempty"`
+	Condition []ConditionItem       `json:"condition,omitempty"`
+}
+
+// Validate check the operators and the leaves of the tree
+func (t *SearchConditionTree) Validate() error {
+	return t.validate(1)
+}
+
+func (t *SearchConditionTree) validate(depth int) error {
+	if depth > SearchTreeMaxDepth {
+		return fmt.Errorf("the condition is deeper than %d", SearchTreeMaxDepth)
+	}
+	switch t.Operator {
+	case "":
+		if t.ObjectID == "" {
+			return fmt.Errorf("the condition has neither operator nor bk_obj_id")
+		}
+		if len(t.Children) > 0 {
+			return fmt.Errorf("the condition of %s has children but no operator", t.ObjectID)
+		}
+		return nil
+	case SearchTreeAnd, SearchTreeOr:
+		if len(t.Children) == 0 {
+			return fmt.Errorf("the %s condition has no children", t.Operator)
+		}
+	case SearchTreeNot:
+		if len(t.Children) != 1 {
+			return fmt.Errorf("the not condition has %d children, but it needs exactly one", len(t.Children))
+		}
+	default:
+		return fmt.Errorf("unknown condition operator %s", t.Operator)
+	}
+	if t.ObjectID != "" || len(t.Condition) > 0 {
+		return fmt.Errorf("the %s condition can not have bk_obj_id or condition items", t.Operator)
+	}
+	for idx := range t.Children {
+		if err := t.Children[idx].validate(depth + 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildCondition translate the tree to the mongo condition, the groups are translated to
+// $and, $or and $nor, and the leaves by the leaf func
+func (t *SearchConditionTree) BuildCondition(leafCond func(leaf *SearchConditionTree) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	if t.Operator == "" {
+		return leafCond(t)
+	}
+
+	children := make([]interface{}, 0, len(t.Children))
+	for idx := range t.Children {
+		cond, err := t.Children[idx].BuildCondition(leafCond)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, cond)
+	}
+
+	switch t.Operator {
+	case SearchTreeAnd:
+		return map[string]interface{}{common.BKDBAND: children}, nil
+	case SearchTreeOr:
+		return map[string]interface{}{common.BKDBOR: children}, nil
+	default:
+		return map[string]interface{}{common.BKDBNOR: children}, nil
+	}
+}
+
 type SearchHost struct {
 	Count int             `json:"count"`
 	Info  []mapstr.MapStr `json:"info"`
@@ -0,0 +1,111 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+)
+
+func TestSearchConditionTreeBuild(t *testing.T) {
+	// hosts in set A or linux hosts, and not in the idle module
+	var tree SearchConditionTree
+	err := json.Unmarshal([]byte(`{
+		"operator": "and",
+		"children": [
+			{"operator": "or", "children": [
+				{"bk_obj_id": "set", "condition": [{"field": "bk_set_name", "operator": "$eq", "value": "A"}]},
+				{"bk_obj_id": "host", "condition": [{"field": "bk_os_type", "operator": "$eq", "value": "linux"}]}
+			]},
+			{"operator": "not", "children": [
+				{"bk_obj_id": "module", "condition": [{"field": "bk_module_name", "operator": "$eq", "value": "idle"}]}
+			]}
+		]
+	}`), &tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("validate failed, err: %v", err)
+	}
+
+	hostIDs := map[string][]int64{common.BKInnerObjIDSet: {1, 2}, common.BKInnerObjIDModule: {2, 3}}
+	cond, err := tree.BuildCondition(func(leaf *SearchConditionTree) (map[string]interface{}, error) {
+		if leaf.ObjectID == common.BKInnerObjIDHost {
+			return map[string]interface{}{leaf.Condition[0].Field: leaf.Condition[0].Value}, nil
+		}
+		return map[string]interface{}{
+			common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs[leaf.ObjectID]},
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("build failed, err: %v", err)
+	}
+
+	expect := map[string]interface{}{
+		common.BKDBAND: []interface{}{
+			map[string]interface{}{common.BKDBOR: []interface{}{
+				map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: []int64{1, 2}}},
+				map[string]interface{}{"bk_os_type": "linux"},
+			}},
+			map[string]interface{}{common.BKDBNOR: []interface{}{
+				map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: []int64{2, 3}}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(cond, expect) {
+		t.Errorf("got condition %v, want %v", cond, expect)
+	}
+}
+
+func TestSearchConditionTreeValidate(t *testing.T) {
+	invalid := []SearchConditionTree{
+		{},
+		{Operator: "xor", Children: []SearchConditionTree{{ObjectID: "host"}}},
+		{Operator: SearchTreeAnd},
+		{Operator: SearchTreeNot, Children: []SearchConditionTree{{ObjectID: "host"}, {ObjectID: "set"}}},
+		{Operator: SearchTreeOr, ObjectID: "host", Children: []SearchConditionTree{{ObjectID: "host"}}},
+		{ObjectID: "host", Children: []SearchConditionTree{{ObjectID: "host"}}},
+	}
+	for _, tree := range invalid {
+		if err := tree.Validate(); err == nil {
+			t.Errorf("%+v should be invalid", tree)
+		}
+	}
+
+	deep := SearchConditionTree{ObjectID: "host"}
+	for i := 0; i < SearchTreeMaxDepth; i++ {
+		deep = SearchConditionTree{Operator: SearchTreeNot, Children: []SearchConditionTree{deep}}
+	}
+	if err := deep.Validate(); err == nil {
+		t.Errorf("the tree deeper than %d should be invalid", SearchTreeMaxDepth)
+	}
+}
+
+func TestNotReportedCondition(t *testing.T) {
+	now := time.Date(2018, 10, 19, 12, 0, 0, 0, time.UTC)
+	cond := NotReportedCondition(24, now)
+	expect := map[string]interface{}{
+		common.BKDBOR: []interface{}{
+			map[string]interface{}{common.BKLastReportTimeField: map[string]interface{}{common.BKDBLT: now.Add(-24 * time.Hour)}},
+			map[string]interface{}{common.BKLastReportTimeField: nil},
+		},
+	}
+	if !reflect.DeepEqual(cond, expect) {
+		t.Errorf("got condition %v, want %v", cond, expect)
+	}
+}
@@ -132,6 +132,18 @@ type QueryObjectAssociationResult struct {
 	Data     []Association `json:"data"`
 }
 
+// CreateAssociationTypeResult create association type result
+type CreateAssociationTypeResult struct {
+	BaseResp `json:",inline"`
+	Data     AssociationType `json:"data"`
+}
+
+// QueryAssociationTypeResult query association type result
+type QueryAssociationTypeResult struct {
+	BaseResp `json:",inline"`
+	Data     []AssociationType `json:"data"`
+}
+
 // InstResult inst item result
 type InstResult struct {
 	Count int            `json:"count"`
@@ -26,8 +26,15 @@ type PermissionGroupListResult struct {
 	Data     []UserGroup `json:"data"`
 }
 
+// the rights of the fields in the field config of the user groups
+const (
+	FieldPrivilegeRead  = "read"
+	FieldPrivilegeWrite = "write"
+)
+
 type Gprivilege struct {
 	ModelConfig    map[string]map[string][]string `json:"model_config" bson:"model_config"`
+	FieldConfig    map[string]map[string][]string `json:"field_config" bson:"field_config"`
 	SysConfig      SysConfigStruct                `json:"sys_config,omitempty" bson:"sys_config"`
 	IsHostCrossBiz bool                           `json:"is_host_cross_biz" bson:"is_host_cross_biz"`
 }
@@ -35,6 +42,9 @@ type Gprivilege struct {
 type Privilege struct {
 	ModelConfig map[string]map[string][]string `json:"model_config,omitempty" bson:"model_config"`
 	SysConfig   *SysConfigStruct               `json:"sys_config,omitempty" bson:"sys_config"`
+	// FieldConfig the rights of the protected fields, object id => property id => read or write,
+	// the fields not in it are not restricted by the group
+	FieldConfig map[string]map[string][]string `json:"field_config,omitempty" bson:"field_config"`
 }
 
 type SysConfigStruct struct {
@@ -110,6 +120,7 @@ type PrivilegeUserGroup struct {
 	GroupID         string       `field:"group_id" json:"group_id" bson:"bk_supplier_account"`
 	ModelConfig     types.MapStr `field:"model_config" json:"model_config" bson:"model_config"`
 	SystemConfig    types.MapStr `field:"sys_config" json:"sys_config" bson:"sys_config"`
+	FieldConfig     types.MapStr `field:"field_config" json:"field_config" bson:"field_config"`
 }
 
 // Parse load the data from mapstr object into object instance
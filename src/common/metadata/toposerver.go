@@ -12,6 +12,12 @@
 
 package metadata
 
+import (
+	"fmt"
+
+	"configcenter/src/common/util"
+)
+
 type SearchInstResult struct {
 	BaseResp `json",inline"`
 	Data     InstResult `json:"data"`
@@ -35,3 +41,92 @@ type MainlineObjectTopoResult struct {
 	BaseResp `json:",inline"`
 	Data     []MainlineObjectTopo `json:"data"`
 }
+
+const (
+	// DeleteCascadeRestrict refuse to delete the instances if the impact analysis is blocking
+	DeleteCascadeRestrict = "restrict"
+	// DeleteCascadeUnlink remove the association references and the process bindings of the deleted instances,
+	// the deletion is still refused if the instances have hosts
+	DeleteCascadeUnlink = "unlink"
+)
+
+const (
+	// InstDependentHost the hosts in the deleted modules
+	InstDependentHost = "host"
+	// InstDependentProcess the processes bound to the deleted modules by module name
+	InstDependentProcess = "process"
+	// InstDependentAssociation the instances which reference the deleted instances by association attributes
+	InstDependentAssociation = "association"
+	// InstDependentUserAPI the saved host queries whose conditions use the deleted instances
+	InstDependentUserAPI = "userapi"
+)
+
+// ValidDeleteCascade return true if the policy is a valid cascade policy of the delete apis
+func ValidDeleteCascade(policy string) bool {
+	return DeleteCascadeRestrict == policy || DeleteCascadeUnlink == policy
+}
+
+// InstRef the instance reference
+type InstRef struct {
+	ObjectID string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+	InstName string `json:"bk_inst_name"`
+}
+
+// InstDependent the record which depends on a deleted instance
+type InstDependent struct {
+	InstID      int64  `json:"bk_inst_id,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	BizID       int64  `json:"bk_biz_id,omitempty"`
+	RefObjectID string `json:"ref_obj_id"`
+	RefInstID   int64  `json:"ref_inst_id"`
+	Reference   string `json:"reference,omitempty"`
+}
+
+// InstDependentGroup the dependents grouped by object
+type InstDependentGroup struct {
+	ObjectID string          `json:"bk_obj_id"`
+	Kind     string          `json:"kind"`
+	Blocking bool            `json:"blocking"`
+	Items    []InstDependent `json:"items"`
+}
+
+// InstDeleteImpact the impact analysis of deleting the instances,
+// the deleted instances include the mainline children of the requested instances
+type InstDeleteImpact struct {
+	ObjectID   string               `json:"bk_obj_id"`
+	InstIDs    []int64              `json:"bk_inst_ids"`
+	Deleted    []InstRef            `json:"deleted"`
+	Blocking   bool                 `json:"blocking"`
+	Dependents []InstDependentGroup `json:"dependents"`
+}
+
+// AddDependent add the dependent into the group of the object, kind and blocking verdict
+func (i *InstDeleteImpact) AddDependent(objID, kind string, blocking bool, item InstDependent) {
+	if blocking {
+		i.Blocking = true
+	}
+
+	for idx := range i.Dependents {
+		group := &i.Dependents[idx]
+		if group.ObjectID == objID && group.Kind == kind && group.Blocking == blocking {
+			group.Items = append(group.Items, item)
+			return
+		}
+	}
+
+	i.Dependents = append(i.Dependents, InstDependentGroup{ObjectID: objID, Kind: kind, Blocking: blocking, Items: []InstDependent{item}})
+}
+
+// BlockedBy return the blocking groups as "object(count)" except the ignored kinds
+func (i *InstDeleteImpact) BlockedBy(ignoreKinds ...string) []string {
+	blocked := []string{}
+	for _, group := range i.Dependents {
+		if !group.Blocking || util.InStrArr(ignoreKinds, group.Kind) {
+			continue
+		}
+		blocked = append(blocked, fmt.Sprintf("%s(%d)", group.ObjectID, len(group.Items)))
+	}
+	return blocked
+}
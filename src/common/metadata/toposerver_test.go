@@ -0,0 +1,43 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstDeleteImpact(t *testing.T) {
+	impact := InstDeleteImpact{ObjectID: "module", InstIDs: []int64{1}}
+	assert.False(t, impact.Blocking)
+	assert.Empty(t, impact.BlockedBy())
+
+	impact.AddDependent("process", InstDependentProcess, false, InstDependent{InstID: 10, RefObjectID: "module", RefInstID: 1})
+	assert.False(t, impact.Blocking)
+	assert.Empty(t, impact.BlockedBy())
+
+	impact.AddDependent("host", InstDependentHost, true, InstDependent{InstID: 2, RefObjectID: "module", RefInstID: 1})
+	impact.AddDependent("host", InstDependentHost, true, InstDependent{InstID: 3, RefObjectID: "module", RefInstID: 1})
+	impact.AddDependent("db", InstDependentAssociation, true, InstDependent{InstID: 4, RefObjectID: "module", RefInstID: 1})
+	impact.AddDependent("db", InstDependentAssociation, false, InstDependent{InstID: 5, RefObjectID: "module", RefInstID: 1})
+	assert.True(t, impact.Blocking)
+	assert.Len(t, impact.Dependents, 4)
+	assert.Len(t, impact.Dependents[1].Items, 2)
+	assert.Equal(t, []string{"host(2)", "db(1)"}, impact.BlockedBy())
+	assert.Equal(t, []string{"host(2)"}, impact.BlockedBy(InstDependentAssociation))
+
+	assert.True(t, ValidDeleteCascade(DeleteCascadeRestrict))
+	assert.True(t, ValidDeleteCascade(DeleteCascadeUnlink))
+	assert.False(t, ValidDeleteCascade("cascade"))
+}
@@ -33,6 +33,10 @@ type HostCommonSearch struct {
 	Condition []SearchCondition `json:"condition"`
 	Page      PageInfo          `json:"page"`
 	Pattern   string            `json:"pattern,omitempty"`
+	// Filter the nested condition, ANDed with the conditions above
+	Filter *metadata.SearchConditionTree `json:"filter,omitempty"`
+	// NotReportedHours only the hosts whose agents have not reported in the hours, 0 means no limit
+	NotReportedHours int64 `json:"not_reported_hours,omitempty"`
 }
 
 //ip search info
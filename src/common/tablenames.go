@@ -65,7 +65,13 @@ const (
 	BKTableNameUserCustom       = "cc_UserCustom"
 	BKTableNameIdentifier       = "cc_idgenerator"
 	BKTableNameObjAsst          = "cc_ObjAsst"
+	BKTableNameAsstType         = "cc_AsstType"
 	BKTableNameTopoGraphics     = "cc_TopoGraphics"
+	BKTableNameEventHistory     = "cc_EventHistory"
+	BKTableNameAPIKey           = "cc_APIKey"
+	BKTableNameDynamicGroupSnap = "cc_DynamicGroupSnapshot"
+	BKTableNameHostSnapRule     = "cc_HostSnapFieldRule"
+	BKTableNameHostSnapHistory  = "cc_HostSnapHistory"
 
 	BKTableNameNetcollectDevice   = "cc_Netcollect_Device"
 	BKTableNameNetcollectProperty = "cc_Netcollect_Property"
@@ -101,7 +107,13 @@ var AllTables = []string{
 	BKTableNameUserCustom,
 	BKTableNameIdentifier,
 	BKTableNameObjAsst,
+	BKTableNameAsstType,
 	BKTableNameTopoGraphics,
+	BKTableNameEventHistory,
+	BKTableNameAPIKey,
+	BKTableNameDynamicGroupSnap,
+	BKTableNameHostSnapRule,
+	BKTableNameHostSnapHistory,
 }
 
 // GetInstTableName returns inst data table name
@@ -14,6 +14,7 @@ package command
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -69,13 +70,28 @@ func importBKBiz(db storage.DI, opt *option) error {
 			return fmt.Errorf("different topo mainline found, your expecting import topo is [%s], but the existing topo is [%s]",
 				strings.Join(tar.Mainline, "->"), strings.Join(cur.Mainline, "->"))
 		}
+	}
+
+	// the whole import is done in one transaction, so that a failure halfway
+	// does not leave the blueking business with a partial topo
+	return db.WithTransaction(func(db storage.DI) error {
+		return importBKTopo(db, opt, cur, tar)
+	})
+}
 
+// errWalkBreak stops a topo walk without failing it
+var errWalkBreak = errors.New("break")
+
+func importBKTopo(db storage.DI, opt *option, cur, tar *Topo) error {
+	if tar.BizTopo != nil {
 		// walk blueking biz and get difference
 		ipt := newImporter(db, opt)
-		ipt.walk(true, tar.BizTopo)
+		if err := ipt.walk(true, tar.BizTopo); err != nil {
+			return err
+		}
 
 		// walk to create new node
-		tar.BizTopo.walk(func(node *Node) error {
+		err := tar.BizTopo.walk(func(node *Node) error {
 			if node.mark == actionCreate {
 				fmt.Printf("--- \033[34m%s %s %+v\033[0m\n", node.mark, node.ObjID, node.Data)
 				if !opt.dryrun {
@@ -103,6 +119,9 @@ func importBKBiz(db storage.DI, opt *option) error {
 			}
 			return nil
 		})
+		if err != nil {
+			return err
+		}
 
 		// walk to delete unuse node
 		for objID, sdeletes := range ipt.sdelete {
@@ -114,13 +133,13 @@ func importBKBiz(db storage.DI, opt *option) error {
 					return err
 				}
 
-				cur.BizTopo.walk(func(node *Node) error {
+				err = cur.BizTopo.walk(func(node *Node) error {
 					nodeID, err := node.getInstID()
 					if nil != err {
 						return err
 					}
 					if node.ObjID == objID && nodeID == instID {
-						node.walk(func(child *Node) error {
+						err := node.walk(func(child *Node) error {
 							childID, err := child.getInstID()
 							if nil != err {
 								return err
@@ -135,7 +154,7 @@ func importBKBiz(db storage.DI, opt *option) error {
 									return fmt.Errorf("get host count error: %s", err.Error())
 								}
 								if count > 0 {
-									return fmt.Errorf("there are %d hosts binded to module %v, please unbind them first and try again ", node.Data[common.BKModuleNameField], err.Error())
+									return fmt.Errorf("there are %d hosts binded to module %v, please unbind them first and try again ", count, child.Data[common.BKModuleNameField])
 								}
 							}
 
@@ -157,11 +176,16 @@ func importBKBiz(db storage.DI, opt *option) error {
 							}
 							return nil
 						})
-						return fmt.Errorf("break")
+						if err != nil {
+							return err
+						}
+						return errWalkBreak
 					}
 					return nil
 				})
-
+				if err != nil && err != errWalkBreak {
+					return err
+				}
 			}
 		}
 	}
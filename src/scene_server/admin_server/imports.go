@@ -22,4 +22,11 @@ import (
 	_ "configcenter/src/scene_server/admin_server/upgrader/x08.09.13.01"
 	_ "configcenter/src/scene_server/admin_server/upgrader/x08.09.17.01"
 	_ "configcenter/src/scene_server/admin_server/upgrader/x08.09.18.01"
+	_ "configcenter/src/scene_server/admin_server/upgrader/x08.09.26.01"
+	_ "configcenter/src/scene_server/admin_server/upgrader/x08.10.08.01"
+	_ "configcenter/src/scene_server/admin_server/upgrader/x08.10.15.01"
+	_ "configcenter/src/scene_server/admin_server/upgrader/x08.10.17.01"
+	_ "configcenter/src/scene_server/admin_server/upgrader/x08.10.18.01"
+	_ "configcenter/src/scene_server/admin_server/upgrader/x08.10.19.01"
+	_ "configcenter/src/scene_server/admin_server/upgrader/x08.10.20.01"
 )
@@ -0,0 +1,137 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// createAPIKey create an api key for the app, the secret is only returned by the creation
+func (s *Service) createAPIKey(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+
+	param := new(metadata.ParamAPIKeyCreate)
+	if err := json.NewDecoder(req.Request.Body).Decode(param); err != nil {
+		blog.Errorf("create api key failed with decode body err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if param.AppCode == "" {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsNeedSet, "bk_app_code")})
+		return
+	}
+	if param.OwnerID == "" {
+		param.OwnerID = common.BKDefaultOwnerID
+	}
+	now := time.Now()
+	if param.ExpireTime != nil && !param.ExpireTime.After(now) {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "expire_time")})
+		return
+	}
+
+	secret, err := metadata.NewAPIKeySecret()
+	if err != nil {
+		blog.Errorf("create api key failed, generate secret err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBInsertFailed)})
+		return
+	}
+	key := metadata.APIKey{
+		AppCode:    param.AppCode,
+		OwnerID:    param.OwnerID,
+		User:       param.User,
+		Enabled:    true,
+		ExpireTime: param.ExpireTime,
+		Creator:    util.GetUser(pheader),
+		CreateTime: now,
+		LastTime:   now,
+	}
+	if err := key.SetSecret(secret); err != nil {
+		blog.Errorf("create api key failed, hash secret err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBInsertFailed)})
+		return
+	}
+	key.KeyID, err = s.db.GetIncID(common.BKTableNameAPIKey)
+	if err != nil {
+		blog.Errorf("create api key failed, get id err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBInsertFailed)})
+		return
+	}
+	if _, err := s.db.Insert(common.BKTableNameAPIKey, key); err != nil {
+		blog.Errorf("create api key failed, insert err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBInsertFailed)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.RspAPIKeyCreate{APIKey: key, AppSecret: secret}))
+}
+
+// searchAPIKey list the api keys, filter by the bk_app_code query parameter when it is set
+func (s *Service) searchAPIKey(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+
+	condition := map[string]interface{}{}
+	if appCode := req.QueryParameter(common.BKAppCodeField); appCode != "" {
+		condition[common.BKAppCodeField] = appCode
+	}
+	keys := []metadata.APIKey{}
+	if err := s.db.GetMutilByCondition(common.BKTableNameAPIKey, nil, condition, &keys, common.BKKeyIDField, 0, 0); err != nil {
+		blog.Errorf("search api key failed, err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.RspAPIKeySearch{Count: len(keys), Info: keys}))
+}
+
+// revokeAPIKey disable the api key, the key is kept for audit
+func (s *Service) revokeAPIKey(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+
+	keyID, err := strconv.ParseInt(req.PathParameter(common.BKKeyIDField), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, common.BKKeyIDField)})
+		return
+	}
+	condition := map[string]interface{}{common.BKKeyIDField: keyID}
+	cnt, err := s.db.GetCntByCondition(common.BKTableNameAPIKey, condition)
+	if err != nil {
+		blog.Errorf("revoke api key %d failed, err: %v", keyID, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+	if cnt == 0 {
+		resp.WriteError(http.StatusNotFound, &metadata.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+		return
+	}
+	data := map[string]interface{}{"enabled": false, common.LastTimeField: time.Now()}
+	if err := s.db.UpdateByCondition(common.BKTableNameAPIKey, data, condition); err != nil {
+		blog.Errorf("revoke api key %d failed, err: %v", keyID, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBUpdateFailed)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
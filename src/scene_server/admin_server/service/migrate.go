@@ -43,3 +43,40 @@ func (s *Service) migrate(req *restful.Request, resp *restful.Response) {
 
 	resp.WriteEntity(metadata.NewSuccessResp("migrate success"))
 }
+
+func (s *Service) migratePlan(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+
+	plans, err := upgrader.Plan(s.db, &upgrader.Config{
+		OwnerID:    common.BKDefaultOwnerID,
+		SupplierID: common.BKDefaultSupplierID,
+		User:       "migrate",
+	})
+	if nil != err {
+		blog.Errorf("db upgrade plan error: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommMigratePlanFailed)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(plans))
+}
+
+func (s *Service) migrateRollback(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	version := req.PathParameter("version")
+
+	err := upgrader.Rollback(s.db, &upgrader.Config{
+		OwnerID:    common.BKDefaultOwnerID,
+		SupplierID: common.BKDefaultSupplierID,
+		User:       "migrate",
+	}, version)
+	if nil != err {
+		blog.Errorf("db rollback to version %s error: %v", version, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommMigrateRollbackFailed, err.Error())})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp("rollback success"))
+}
@@ -41,8 +41,13 @@ func (s *Service) WebService() *restful.WebService {
 	ws.Path("/migrate/v3").Filter(rdapi.AllGlobalFilter(getErrFun)).Produces(restful.MIME_JSON)
 
 	ws.Route(ws.POST("/migrate/{distribution}/{ownerID}").To(s.migrate))
+	ws.Route(ws.GET("/migrate/plan").To(s.migratePlan))
+	ws.Route(ws.POST("/migrate/rollback/{version}").To(s.migrateRollback))
 	ws.Route(ws.POST("/migrate/system/hostcrossbiz/{ownerID}").To(s.Set))
 	ws.Route(ws.POST("/clear").To(s.clear))
+	ws.Route(ws.POST("/apikey").To(s.createAPIKey))
+	ws.Route(ws.GET("/apikey").To(s.searchAPIKey))
+	ws.Route(ws.DELETE("/apikey/{bk_key_id}").To(s.revokeAPIKey))
 	ws.Route(ws.GET("/healthz").To(s.Healthz))
 
 	return ws
@@ -15,5 +15,7 @@
 // we use date instead of version later since 2018.09.04, because the version wasn't manage by the developer
 // when use date instead of version, the date should add x prefix, cause x > v
 // example: x08.09.04.01
+// Plan preview the writes of the upgraders without doing them, and the upgraders registed
+// with a down by RegistUpgraderWithDown can be rollback by Rollback
 
 package upgrader
@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upgrader
+
+import (
+	"fmt"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/storage"
+)
+
+// VersionPlan the writes the upgrader of the version would make
+type VersionPlan struct {
+	Version    string              `json:"version"`
+	Operations []storage.Operation `json:"operations"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// Plan run the upgraders which Upgrade would run against a storage.Recorder, and return the
+// writes each of them would make, nothing is written to db. The upgraders read db as it is now,
+// they don't see the writes of the upgraders before them, so the plan of an upgrader which
+// depends on them may differ from the real upgrade, and its error is kept in the plan
+// instead of stopping the planning.
+func Plan(db storage.DI, conf *Config) ([]VersionPlan, error) {
+	sortUpgraders()
+
+	recorder := storage.NewRecorder(db)
+	cmdbVision, err := getVersion(recorder)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := []VersionPlan{}
+	for _, v := range upgraderPool {
+		if v.version <= cmdbVision.CurrentVersion {
+			continue
+		}
+		plan := VersionPlan{Version: v.version}
+		if err := doRecorded(v.do, recorder, conf); err != nil {
+			blog.Warnf("plan upgrade version %s error: %s", v.version, err.Error())
+			plan.Error = err.Error()
+		}
+		cmdbVision.CurrentVersion = v.version
+		if err := saveVesion(recorder, cmdbVision); err != nil {
+			return nil, err
+		}
+		plan.Operations = recorder.Operations()
+		recorder.Reset()
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// doRecorded the upgraders which use the raw session panic on the nil session of the recorder
+func doRecorded(do func(storage.DI, *Config) error, db storage.DI, conf *Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("upgrader can not be planed: %v", r)
+		}
+	}()
+	return do(db, conf)
+}
+
+// Rollback undo the upgraders newer than version in the reverse order, and set the current
+// version to version. Every upgrader to undo must have a down, or nothing is done.
+// Each upgrader is undone in its own transaction with the version change.
+func Rollback(db storage.DI, conf *Config, version string) error {
+	sortUpgraders()
+
+	cmdbVision, err := getVersion(db)
+	if err != nil {
+		return err
+	}
+
+	target := -1
+	for idx, v := range upgraderPool {
+		if v.version == version {
+			target = idx
+			break
+		}
+	}
+	if target < 0 {
+		return fmt.Errorf("there is no upgrader of version %s", version)
+	}
+	if version >= cmdbVision.CurrentVersion {
+		return fmt.Errorf("the current version %s is not newer than %s", cmdbVision.CurrentVersion, version)
+	}
+
+	for idx := target + 1; idx < len(upgraderPool); idx++ {
+		v := upgraderPool[idx]
+		if v.version > cmdbVision.CurrentVersion {
+			break
+		}
+		if v.down == nil {
+			return fmt.Errorf("the upgrader of version %s can not be rollback", v.version)
+		}
+	}
+
+	for idx := len(upgraderPool) - 1; idx > target; idx-- {
+		v := upgraderPool[idx]
+		if v.version > cmdbVision.CurrentVersion {
+			continue
+		}
+		previous := upgraderPool[idx-1].version
+		err := db.WithTransaction(func(db storage.DI) error {
+			if err := v.down(db, conf); err != nil {
+				return err
+			}
+			cmdbVision.CurrentVersion = previous
+			return saveVesion(db, cmdbVision)
+		})
+		if err != nil {
+			blog.Errorf("rollback version %s error: %s", v.version, err.Error())
+			return err
+		}
+		blog.Infof("rollback version %s success, current version is %s", v.version, previous)
+	}
+	return nil
+}
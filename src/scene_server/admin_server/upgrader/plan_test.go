@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upgrader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/storage"
+	"configcenter/src/storage/memclient"
+)
+
+func registTestUpgraders(t *testing.T) {
+	pool := upgraderPool
+	t.Cleanup(func() { upgraderPool = pool })
+	upgraderPool = nil
+
+	RegistUpgrader("x00", func(db storage.DI, conf *Config) error {
+		return db.CreateTable("cc_Test")
+	})
+	RegistUpgrader("x01", func(db storage.DI, conf *Config) error {
+		_, err := db.Insert("cc_Test", map[string]interface{}{"name": "a", "version": 1})
+		return err
+	})
+	RegistUpgraderWithDown("x02", func(db storage.DI, conf *Config) error {
+		return db.UpdateByCondition("cc_Test", map[string]interface{}{"version": 2}, map[string]interface{}{"name": "a"})
+	}, func(db storage.DI, conf *Config) error {
+		return db.UpdateByCondition("cc_Test", map[string]interface{}{"version": 1}, map[string]interface{}{"name": "a"})
+	})
+}
+
+func currentVersion(t *testing.T, db storage.DI) string {
+	version, err := getVersion(db)
+	require.NoError(t, err)
+	return version.CurrentVersion
+}
+
+func TestPlan(t *testing.T) {
+	registTestUpgraders(t)
+	db := memclient.NewMemCli("cmdb")
+	conf := &Config{OwnerID: common.BKDefaultOwnerID}
+
+	plans, err := Plan(db, conf)
+	require.NoError(t, err)
+	require.Len(t, plans, 3)
+	assert.Equal(t, "x00", plans[0].Version)
+	assert.Equal(t, storage.ActionInsert, plans[0].Operations[0].Action)
+	assert.Equal(t, common.BKTableNameSystem, plans[0].Operations[0].Collection)
+	assert.Equal(t, storage.ActionCreateTable, plans[0].Operations[1].Action)
+	assert.Equal(t, storage.ActionUpdate, plans[0].Operations[2].Action)
+	require.Len(t, plans[1].Operations, 2)
+	assert.Equal(t, storage.ActionInsert, plans[1].Operations[0].Action)
+	assert.Equal(t, "cc_Test", plans[1].Operations[0].Collection)
+
+	// nothing is written
+	exists, err := db.HasTable(common.BKTableNameSystem)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, Upgrade(db, conf))
+	plans, err = Plan(db, conf)
+	require.NoError(t, err)
+	assert.Empty(t, plans)
+}
+
+func TestRollback(t *testing.T) {
+	registTestUpgraders(t)
+	db := memclient.NewMemCli("cmdb")
+	conf := &Config{OwnerID: common.BKDefaultOwnerID}
+	require.NoError(t, Upgrade(db, conf))
+	require.Equal(t, "x02", currentVersion(t, db))
+
+	// x01 has no down
+	assert.Error(t, Rollback(db, conf, "x"))
+	assert.Error(t, Rollback(db, conf, "x00"))
+	assert.Equal(t, "x02", currentVersion(t, db))
+
+	require.NoError(t, Rollback(db, conf, "x01"))
+	assert.Equal(t, "x01", currentVersion(t, db))
+	inst := map[string]interface{}{}
+	require.NoError(t, db.GetOneByCondition("cc_Test", nil, map[string]interface{}{"name": "a"}, &inst))
+	assert.EqualValues(t, 1, inst["version"])
+
+	// only older version can be rollback to
+	assert.Error(t, Rollback(db, conf, "x02"))
+	assert.Error(t, Rollback(db, conf, "x01"))
+
+	require.NoError(t, Upgrade(db, conf))
+	assert.Equal(t, "x02", currentVersion(t, db))
+}
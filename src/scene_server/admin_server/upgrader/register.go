@@ -35,6 +35,7 @@ type Config struct {
 type Upgrader struct {
 	version string // v3.0.8-beta.11
 	do      func(storage.DI, *Config) error
+	down    func(storage.DI, *Config) error // undo the do, optional
 }
 
 var upgraderPool = []Upgrader{}
@@ -50,13 +51,25 @@ func RegistUpgrader(version string, handlerFunc func(storage.DI, *Config) error)
 	// blog.Infof("registed upgrader for version %s", v.version)
 }
 
+// RegistUpgraderWithDown register upgrader which can be rollback by the downFunc
+func RegistUpgraderWithDown(version string, handlerFunc, downFunc func(storage.DI, *Config) error) {
+	registlock.Lock()
+	defer registlock.Unlock()
+	v := Upgrader{version: version, do: handlerFunc, down: downFunc}
+	upgraderPool = append(upgraderPool, v)
+}
+
+func sortUpgraders() {
+	sort.Slice(upgraderPool, func(i, j int) bool {
+		return upgraderPool[i].version < upgraderPool[j].version
+	})
+}
+
 // Upgrade uprade the db datas to newest verison
 // we use date instead of version later since 2018.09.04, because the version wasn't manage by the developer
 // ps: when use date instead of version, the date should add x prefix cause x > v
 func Upgrade(db storage.DI, conf *Config) (err error) {
-	sort.Slice(upgraderPool, func(i, j int) bool {
-		return upgraderPool[i].version < upgraderPool[j].version
-	})
+	sortUpgraders()
 
 	cmdbVision, err := getVersion(db)
 	if err != nil {
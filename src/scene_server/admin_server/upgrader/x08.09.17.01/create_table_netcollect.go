@@ -40,6 +40,21 @@ func createTable(db storage.DI, conf *upgrader.Config) (err error) {
 	return nil
 }
 
+func dropTable(db storage.DI, conf *upgrader.Config) (err error) {
+	for tablename := range tables {
+		exists, err := db.HasTable(tablename)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err = db.DropTable(tablename); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 var tables = map[string][]storage.Index{
 	common.BKTableNameNetcollectDevice: []storage.Index{
 		storage.Index{Name: "", Columns: []string{"device_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
@@ -19,7 +19,7 @@ import (
 )
 
 func init() {
-	upgrader.RegistUpgrader("x08.09.17.01", upgrade)
+	upgrader.RegistUpgraderWithDown("x08.09.17.01", upgrade, down)
 }
 
 func upgrade(db storage.DI, conf *upgrader.Config) (err error) {
@@ -31,3 +31,13 @@ func upgrade(db storage.DI, conf *upgrader.Config) (err error) {
 
 	return nil
 }
+
+func down(db storage.DI, conf *upgrader.Config) (err error) {
+	err = dropTable(db, conf)
+	if err != nil {
+		blog.Errorf("[rollback x08.09.17.01] drop table netcollect error  %s", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package x08_10_08_01
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage"
+)
+
+func init() {
+	upgrader.RegistUpgraderWithDown("x08.10.08.01", upgrade, down)
+}
+
+func upgrade(db storage.DI, conf *upgrader.Config) (err error) {
+	err = createTable(db, conf)
+	if err != nil {
+		blog.Errorf("[upgrade x08.10.08.01] create table api key error  %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func down(db storage.DI, conf *upgrader.Config) (err error) {
+	err = dropTable(db, conf)
+	if err != nil {
+		blog.Errorf("[rollback x08.10.08.01] drop table api key error  %s", err.Error())
+		return err
+	}
+
+	return nil
+}
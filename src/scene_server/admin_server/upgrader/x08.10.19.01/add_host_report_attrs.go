@@ -0,0 +1,81 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package x08_10_19_01
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	mCommon "configcenter/src/scene_server/admin_server/common"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage"
+
+	"gopkg.in/mgo.v2"
+)
+
+func reportAttrs(conf *upgrader.Config) []*metadata.Attribute {
+	now := time.Now()
+	newAttr := func(propertyID, propertyName, description string) *metadata.Attribute {
+		return &metadata.Attribute{
+			ObjectID:      common.BKInnerObjIDHost,
+			PropertyID:    propertyID,
+			PropertyName:  propertyName,
+			IsRequired:    false,
+			IsOnly:        false,
+			IsEditable:    false,
+			PropertyGroup: mCommon.HostAutoFields,
+			PropertyType:  common.FieldTypeTime,
+			Option:        "",
+			OwnerID:       conf.OwnerID,
+			IsPre:         true,
+			IsReadOnly:    true,
+			CreateTime:    &now,
+			Creator:       common.CCSystemOperatorUserName,
+			LastTime:      &now,
+			Description:   description,
+		}
+	}
+	return []*metadata.Attribute{
+		newAttr(common.BKLastReportTimeField, "最近上报时间", "主机agent最近一次上报快照的时间"),
+		newAttr(common.BKAgentOfflineTimeField, "agent离线时间", "主机agent超过设定时间未上报时，记录其离线的起始时间，恢复上报后清空"),
+	}
+}
+
+func addReportAttrs(db storage.DI, conf *upgrader.Config) error {
+	for _, attr := range reportAttrs(conf) {
+		_, _, err := upgrader.Upsert(db, common.BKTableNameObjAttDes, attr, "id", []string{common.BKObjIDField, common.BKPropertyIDField, common.BKOwnerIDField}, []string{})
+		if nil != err {
+			blog.Errorf("[upgrade x08.10.19.01] add host attribute %s error %s", attr.PropertyID, err)
+			return err
+		}
+	}
+
+	index := storage.Index{Name: "", Columns: []string{common.BKLastReportTimeField}, Type: storage.INDEX_TYPE_BACKGROUP}
+	if err := db.Index(common.BKTableNameBaseHost, &index); err != nil && !mgo.IsDup(err) {
+		return err
+	}
+	return nil
+}
+
+func removeReportAttrs(db storage.DI, conf *upgrader.Config) error {
+	condition := map[string]interface{}{
+		common.BKObjIDField: common.BKInnerObjIDHost,
+		common.BKPropertyIDField: map[string]interface{}{
+			common.BKDBIN: []string{common.BKLastReportTimeField, common.BKAgentOfflineTimeField},
+		},
+		common.BKOwnerIDField: conf.OwnerID,
+	}
+	return db.DelByCondition(common.BKTableNameObjAttDes, condition)
+}
@@ -44,4 +44,13 @@ type Config struct {
 	CCRedis       redisclient.RedisConfig
 	SnapRedis     redisclient.RedisConfig
 	DiscoverRedis redisclient.RedisConfig
+	HostSnap      HostSnap
+}
+
+// HostSnap the config of the host snapshot collection
+type HostSnap struct {
+	// HistoryDays how many days the snapshot history is kept, 0 means forever
+	HistoryDays int
+	// StaleHours the hosts whose agents have not reported in the hours are marked offline, 0 disables it
+	StaleHours int
 }
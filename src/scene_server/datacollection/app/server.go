@@ -16,6 +16,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -87,10 +88,13 @@ func Run(ctx context.Context, op *options.ServerOption) error {
 			continue
 		}
 
-		err := datacollection.NewDataCollection(process.Config, process.Core).Run()
+		collection := datacollection.NewDataCollection(process.Config, process.Core)
+		err := collection.Run()
 		if err != nil {
 			return fmt.Errorf("run datacollection routine failed %s", err.Error())
 		}
+		service.SetDB(collection.DB())
+		service.SetDiscover(collection.Discover())
 		break
 	}
 
@@ -107,6 +111,13 @@ type DCServer struct {
 
 var configLock sync.Mutex
 
+const (
+	// defaultSnapHistoryDays used when hostsnap.history_days is not configured
+	defaultSnapHistoryDays = 90
+	// defaultStaleHours used when hostsnap.stale_hours is not configured
+	defaultStaleHours = 24
+)
+
 func (h *DCServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 	configLock.Lock()
 	defer configLock.Unlock()
@@ -143,6 +154,26 @@ func (h *DCServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 		h.Config.DiscoverRedis.Database = current.ConfigMap[discoverPrefix+".database"]
 		h.Config.DiscoverRedis.Port = current.ConfigMap[discoverPrefix+".port"]
 		h.Config.DiscoverRedis.MasterName = current.ConfigMap[discoverPrefix+".mastername"]
+
+		h.Config.HostSnap.HistoryDays = defaultSnapHistoryDays
+		if days, ok := current.ConfigMap["hostsnap.history_days"]; ok && "" != days {
+			historyDays, err := strconv.Atoi(days)
+			if nil != err || historyDays < 0 {
+				blog.Errorf("invalid hostsnap.history_days %s, use default %d", days, defaultSnapHistoryDays)
+			} else {
+				h.Config.HostSnap.HistoryDays = historyDays
+			}
+		}
+
+		h.Config.HostSnap.StaleHours = defaultStaleHours
+		if hours, ok := current.ConfigMap["hostsnap.stale_hours"]; ok && "" != hours {
+			staleHours, err := strconv.Atoi(hours)
+			if nil != err || staleHours < 0 {
+				blog.Errorf("invalid hostsnap.stale_hours %s, use default %d", hours, defaultStaleHours)
+			} else {
+				h.Config.HostSnap.StaleHours = staleHours
+			}
+		}
 	}
 }
 
@@ -31,7 +31,8 @@ import (
 type DataCollection struct {
 	Config *options.Config
 	*backbone.Engine
-	db storage.DI
+	db       storage.DI
+	discover *Discover
 }
 
 func NewDataCollection(config *options.Config, backbone *backbone.Engine) *DataCollection {
@@ -79,7 +80,7 @@ func (d *DataCollection) Run() error {
 		time.Sleep(time.Second * 10)
 	}
 
-	hostSnap := NewHostSnap(chanName, MaxSnapSize, rediscli, snapcli, db)
+	hostSnap := NewHostSnap(chanName, MaxSnapSize, rediscli, snapcli, db, d.Engine, d.Config.HostSnap.HistoryDays, d.Config.HostSnap.StaleHours)
 	hostSnap.Start()
 
 	discoverChan := ""
@@ -93,11 +94,22 @@ func (d *DataCollection) Run() error {
 	}
 	discover := NewDiscover(context.Background(), discoverChan, MaxDiscoverSize, rediscli, discli, d.Engine)
 	discover.Start()
+	d.discover = discover
 
 	blog.Infof("datacollection started")
 	return nil
 }
 
+// DB the database connected by Run
+func (d *DataCollection) DB() storage.DI {
+	return d.db
+}
+
+// Discover the discover started by Run, which saves the discovered objects
+func (d *DataCollection) Discover() *Discover {
+	return d.discover
+}
+
 func (d *DataCollection) getDiscoverChanName() (string, error) {
 	defaultAppID, err := d.getDefaultAppID()
 	if nil != err {
@@ -600,6 +600,39 @@ func (d *Discover) UpdateOrCreateInst(msg string) error {
 		return fmt.Errorf("get bk_inst_id failed: %s %s", inst[bkc.BKInstIDField], err.Error())
 	}
 
+	hasDiff := mergeInstData(inst, bodyData)
+
+	if !hasDiff {
+		blog.Infof("no need to update inst")
+		return nil
+	}
+
+	delete(inst, bkc.BKObjIDField)
+	delete(inst, bkc.BKOwnerIDField)
+	delete(inst, bkc.BKDefaultField)
+	delete(inst, bkc.BKInstIDField)
+	delete(inst, bkc.LastTimeField)
+	delete(inst, bkc.CreateTimeField)
+
+	resp, err := d.CoreAPI.TopoServer().Instance().UpdateInst(d.ctx, ownerID, objID, instID, d.pheader, inst)
+	if err != nil {
+		blog.Errorf("search model failed %s", err.Error())
+		return fmt.Errorf("search model failed: %s", err.Error())
+	}
+	if !resp.Result {
+		blog.Errorf("search model failed %s", resp.ErrMsg)
+		return fmt.Errorf("search model failed: %s", resp.ErrMsg)
+	}
+	blog.Infof("update inst result: %v", resp)
+
+	d.TryUnsetRedis(instKeyStr)
+
+	return nil
+}
+
+// mergeInstData set the discovered values to the instance, the single relation to the host is
+// kept once it is set, and it returns whether the instance is changed
+func mergeInstData(inst map[string]interface{}, bodyData M) bool {
 	hasDiff := false
 	for attrId, attrValue := range bodyData {
 
@@ -635,30 +668,5 @@ func (d *Discover) UpdateOrCreateInst(msg string) error {
 
 	}
 
-	if !hasDiff {
-		blog.Infof("no need to update inst")
-		return nil
-	}
-
-	delete(inst, bkc.BKObjIDField)
-	delete(inst, bkc.BKOwnerIDField)
-	delete(inst, bkc.BKDefaultField)
-	delete(inst, bkc.BKInstIDField)
-	delete(inst, bkc.LastTimeField)
-	delete(inst, bkc.CreateTimeField)
-
-	resp, err := d.CoreAPI.TopoServer().Instance().UpdateInst(d.ctx, ownerID, objID, instID, d.pheader, inst)
-	if err != nil {
-		blog.Errorf("search model failed %s", err.Error())
-		return fmt.Errorf("search model failed: %s", err.Error())
-	}
-	if !resp.Result {
-		blog.Errorf("search model failed %s", resp.ErrMsg)
-		return fmt.Errorf("search model failed: %s", resp.ErrMsg)
-	}
-	blog.Infof("update inst result: %v", resp)
-
-	d.TryUnsetRedis(instKeyStr)
-
-	return nil
+	return hasDiff
 }
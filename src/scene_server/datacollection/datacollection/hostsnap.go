@@ -28,7 +28,10 @@ import (
 	redis "gopkg.in/redis.v5"
 
 	"configcenter/src/common"
+	"configcenter/src/common/backbone"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
 	"configcenter/src/storage"
 )
 
@@ -65,8 +68,20 @@ type HostSnap struct {
 	subscribing bool
 
 	cache *Cache
+	rules *hostSnapRuleCache
 	db    storage.DI
 
+	engine *backbone.Engine
+	// historyDays how many days the snapshot history is kept, 0 means forever
+	historyDays    int
+	historyLock    sync.Mutex
+	historyBuckets map[int64]time.Time
+
+	// staleHours the hosts whose agents have not reported in the hours are marked offline, 0 disables it
+	staleHours    int
+	reportLock    sync.Mutex
+	reportedHosts map[int64]bool
+
 	wg *sync.WaitGroup
 }
 
@@ -75,7 +90,7 @@ type Cache struct {
 	flag  bool
 }
 
-func NewHostSnap(chanName []string, maxSize int, redisCli, snapCli *redis.Client, db storage.DI) *HostSnap {
+func NewHostSnap(chanName []string, maxSize int, redisCli, snapCli *redis.Client, db storage.DI, engine *backbone.Engine, historyDays, staleHours int) *HostSnap {
 	if 0 == maxSize {
 		maxSize = 100
 	}
@@ -88,6 +103,9 @@ func NewHostSnap(chanName []string, maxSize int, redisCli, snapCli *redis.Client
 		redisCli:      redisCli,
 		snapCli:       snapCli,
 		db:            db,
+		engine:        engine,
+		historyDays:   historyDays,
+		staleHours:    staleHours,
 		ts:            time.Now(),
 		id:            xid.New().String()[5:],
 		maxconcurrent: maxconcurrent,
@@ -97,6 +115,11 @@ func NewHostSnap(chanName []string, maxSize int, redisCli, snapCli *redis.Client
 			cache: map[bool]*HostCache{},
 			flag:  false,
 		},
+		rules: &hostSnapRuleCache{
+			rules: map[string][]metadata.HostSnapFieldRule{"": MergeHostSnapRules(nil)},
+		},
+		historyBuckets: map[int64]time.Time{},
+		reportedHosts:  map[int64]bool{},
 	}
 	return hostSnapInstance
 }
@@ -107,7 +130,7 @@ func (h *HostSnap) Start() {
 		h.Run()
 		for {
 			time.Sleep(time.Second * 10)
-			NewHostSnap(h.hostChanName, h.maxSize, h.redisCli, h.snapCli, h.db).Run()
+			NewHostSnap(h.hostChanName, h.maxSize, h.redisCli, h.snapCli, h.db, h.engine, h.historyDays, h.staleHours).Run()
 		}
 	}()
 }
@@ -131,6 +154,9 @@ func (h *HostSnap) Run() {
 	var waitCnt int
 
 	go h.fetchDB()
+	go h.fetchRules()
+	go h.cleanHistory()
+	go h.watchReport()
 
 	if h.saveRunning() {
 		go h.subChan(h.snapCli, h.hostChanName)
@@ -250,7 +276,20 @@ func (h *HostSnap) handleMsg(msgs []string, resetHandle chan struct{}) error {
 			if !ok {
 				blog.Warnf("outip is not string, %s", val.String())
 			}
-			setter := parseSetter(&val, innerip, outip)
+			rules := h.rules.get(fmt.Sprint(host.get(common.BKOwnerIDField)))
+			setter := parseSetter(&val, innerip, outip, rules)
+			now := time.Now()
+			preData := host.copy()
+			changes := diffSnapFields(preData, setter, now)
+			hostID, err := util.GetInt64ByInterface(host.get(common.BKHostIDField))
+			if err != nil {
+				blog.Warnf("host id %v is not integer, continue", host.get(common.BKHostIDField))
+				continue
+			}
+			h.markReported(hostID)
+			if err := h.saveHistory(hostID, fmt.Sprint(host.get(common.BKOwnerIDField)), setter, changes, now); err != nil {
+				blog.Errorf("save snapshot history of host %d failed: %v", hostID, err)
+			}
 			if needToUpdate(setter, host) {
 				blog.Infof("update by %v, to %v", condition, setter)
 				if err := h.db.UpdateByCondition(common.BKTableNameBaseHost, setter, condition); err != nil {
@@ -258,6 +297,7 @@ func (h *HostSnap) handleMsg(msgs []string, resetHandle chan struct{}) error {
 					continue
 				}
 				copyVal(setter, host)
+				h.reportHardwareChanges(hostID, innerip, preData, host.copy(), changes)
 			}
 		}
 	}
@@ -279,19 +319,9 @@ func needToUpdate(a map[string]interface{}, b *HostInst) bool {
 	return false
 }
 
-func parseSetter(val *gjson.Result, innerIP, outerIP string) map[string]interface{} {
-	var cpumodule = val.Get("data.cpu.cpuinfo.0.modelName").String()
-	var cupnum int64
-	for _, core := range val.Get("data.cpu.cpuinfo.#.cores").Array() {
-		cupnum += core.Int()
-	}
-	var CPUMhz = val.Get("data.cpu.cpuinfo.0.mhz").Int()
-	var disk int64
-	for _, disktotal := range val.Get("data.disk.usage.#.total").Array() {
-		disk += disktotal.Int()
-	}
-	var mem = val.Get("data.mem.meminfo.total").Int()
-	var hostname = val.Get("data.system.info.hostname").String()
+// parseSetter get the host attributes from the snapshot, the os and mac attributes are derived
+// from several values of the snapshot, and the others are filled by the rules
+func parseSetter(val *gjson.Result, innerIP, outerIP string, rules []metadata.HostSnapFieldRule) map[string]interface{} {
 	var ostype = val.Get("data.system.info.os").String()
 	var osname string
 	platform := val.Get("data.system.info.platform").String()
@@ -322,38 +352,14 @@ func parseSetter(val *gjson.Result, innerIP, outerIP string) map[string]interfac
 		}
 	}
 
-	osbit := val.Get("data.system.info.systemtype").String()
-
 	setter := map[string]interface{}{
-		"bk_cpu":        cupnum,
-		"bk_cpu_module": cpumodule,
-		"bk_cpu_mhz":    CPUMhz,
-		"bk_disk":       disk / 1024 / 1024 / 1024,
-		"bk_mem":        mem / 1024 / 1024,
 		"bk_os_type":    ostype,
 		"bk_os_name":    osname,
 		"bk_os_version": version,
-		"bk_host_name":  hostname,
 		"bk_outer_mac":  OuterMAC,
 		"bk_mac":        InnerMAC,
-		"bk_os_bit":     osbit,
 	}
 
-	if cupnum <= 0 {
-		blog.Infof("bk_cpu not found in message for %s", innerIP)
-	}
-	if cpumodule == "" {
-		blog.Infof("bk_cpu_module not found in message for %s", innerIP)
-	}
-	if CPUMhz <= 0 {
-		blog.Infof("bk_cpu_mhz not found in message for %s", innerIP)
-	}
-	if disk <= 0 {
-		blog.Infof("bk_disk not found in message for %s", innerIP)
-	}
-	if mem <= 0 {
-		blog.Infof("bk_mem not found in message for %s", innerIP)
-	}
 	if ostype == "" {
 		blog.Infof("bk_os_type not found in message for %s", innerIP)
 	}
@@ -363,9 +369,6 @@ func parseSetter(val *gjson.Result, innerIP, outerIP string) map[string]interfac
 	if version == "" {
 		blog.Infof("bk_os_version not found in message for %s", innerIP)
 	}
-	if hostname == "" {
-		blog.Infof("bk_host_name not found in message for %s", innerIP)
-	}
 	if outerIP != "" && OuterMAC == "" {
 		blog.Infof("bk_outer_mac not found in message for %s", innerIP)
 	}
@@ -373,6 +376,20 @@ func parseSetter(val *gjson.Result, innerIP, outerIP string) map[string]interfac
 		blog.Infof("bk_mac not found in message for %s", innerIP)
 	}
 
+	for idx := range rules {
+		rule := &rules[idx]
+		if !rule.Enable {
+			delete(setter, rule.PropertyID)
+			continue
+		}
+		value, ok := extractField(val, rule)
+		if !ok {
+			blog.Infof("%s not found in message for %s", rule.PropertyID, innerIP)
+			continue
+		}
+		setter[rule.PropertyID] = value
+	}
+
 	return setter
 }
 func getIPS(val *gjson.Result) (ips []string) {
@@ -641,6 +658,20 @@ func (h *HostInst) get(key string) interface{} {
 	return value
 }
 
+// copy get a copy of the host data without the mongo id
+func (h *HostInst) copy() map[string]interface{} {
+	h.RLock()
+	defer h.RUnlock()
+	data := make(map[string]interface{}, len(h.data))
+	for key, value := range h.data {
+		if "_id" == key {
+			continue
+		}
+		data[key] = value
+	}
+	return data
+}
+
 func (h *HostInst) set(key string, value interface{}) {
 	h.Lock()
 	h.data[key] = value
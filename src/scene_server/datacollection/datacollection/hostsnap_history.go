@@ -0,0 +1,212 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datacollection
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/auditoplog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+var (
+	// snapHistoryBucket the time span of a host snapshot history document
+	snapHistoryBucket = time.Hour * 24
+	// snapHistoryMaxChanges the max count of the changes kept in a bucket, the earliest ones are dropped
+	snapHistoryMaxChanges = 1000
+
+	cleanHistoryInterval = time.Hour
+)
+
+// diffSnapFields get the attributes the setter changes, the values are compared by their string
+// forms, as the numbers read from the database may be of other types than the ones collected
+func diffSnapFields(pre map[string]interface{}, setter map[string]interface{}, now time.Time) []metadata.HostSnapChange {
+	changes := make([]metadata.HostSnapChange, 0)
+	for field, value := range setter {
+		preValue := pre[field]
+		if nil != preValue && fmt.Sprint(preValue) == fmt.Sprint(value) {
+			continue
+		}
+		changes = append(changes, metadata.HostSnapChange{Time: now, PropertyID: field, PreValue: preValue, CurValue: value})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].PropertyID < changes[j].PropertyID })
+	return changes
+}
+
+// isHardwareChange the attribute had been collected and changes to another value, e.g. the memory
+// shrank, the mac changed or the os was reinstalled, rather than being filled at the first time
+func isHardwareChange(change *metadata.HostSnapChange) bool {
+	switch value := change.PreValue.(type) {
+	case nil:
+		return false
+	case string:
+		return "" != value
+	default:
+		return "0" != fmt.Sprint(value)
+	}
+}
+
+// saveHistory save the attributes at the first snapshot of the host in a bucket, and the changes
+// in the bucket, nothing is written for the snapshots which change nothing in the same bucket
+func (h *HostSnap) saveHistory(hostID int64, ownerID string, setter map[string]interface{}, changes []metadata.HostSnapChange, now time.Time) error {
+	bucket := now.UTC().Truncate(snapHistoryBucket)
+	h.historyLock.Lock()
+	last := h.historyBuckets[hostID]
+	h.historyLock.Unlock()
+	if last.Equal(bucket) && 0 == len(changes) {
+		return nil
+	}
+
+	condition := map[string]interface{}{common.BKHostIDField: hostID, "bucket_time": bucket}
+	count, err := h.db.GetCntByCondition(common.BKTableNameHostSnapHistory, condition)
+	if err != nil {
+		return err
+	}
+	inserted := false
+	if 0 == count {
+		history := metadata.HostSnapHistory{
+			HostID:     hostID,
+			OwnerID:    ownerID,
+			BucketTime: bucket,
+			LastTime:   now,
+			Data:       setter,
+			Changes:    changes,
+		}
+		_, err := h.db.Insert(common.BKTableNameHostSnapHistory, history)
+		switch {
+		case nil == err:
+			inserted = true
+		case !h.db.IsDuplicateErr(err):
+			return err
+		}
+		// the bucket was inserted by another collector meanwhile, append the changes to it
+	}
+	if !inserted {
+		if err := h.appendHistoryChanges(condition, changes, now); err != nil {
+			return err
+		}
+	}
+
+	h.historyLock.Lock()
+	h.historyBuckets[hostID] = bucket
+	h.historyLock.Unlock()
+	return nil
+}
+
+// appendHistoryChanges append the changes to the bucket at once, so that the changes saved by
+// the other collectors meanwhile are kept
+func (h *HostSnap) appendHistoryChanges(condition map[string]interface{}, changes []metadata.HostSnapChange, now time.Time) error {
+	data := map[string]interface{}{"last_time": now}
+	if 0 == len(changes) {
+		return h.db.UpdateByCondition(common.BKTableNameHostSnapHistory, data, condition)
+	}
+	items := make([]interface{}, 0, len(changes))
+	for _, change := range changes {
+		items = append(items, change)
+	}
+	return h.db.PushByCondition(common.BKTableNameHostSnapHistory, data, "changes", items, snapHistoryMaxChanges, condition)
+}
+
+// reportHardwareChanges save the audit log and send the host update event of the hardware changes
+func (h *HostSnap) reportHardwareChanges(hostID int64, innerIP string, preData, curData map[string]interface{}, changes []metadata.HostSnapChange) {
+	hardware := make([]metadata.HostSnapChange, 0, len(changes))
+	for idx := range changes {
+		if isHardwareChange(&changes[idx]) {
+			hardware = append(hardware, changes[idx])
+		}
+	}
+	if 0 == len(hardware) {
+		return
+	}
+
+	h.sendHostUpdateEvent(hostID, curData, preData)
+
+	if nil == h.engine {
+		return
+	}
+	appID, err := h.getHostAppID(hostID)
+	if err != nil {
+		blog.Errorf("get the business of host %d failed, the hardware change is not audited, err: %v", hostID, err)
+		return
+	}
+	pre := map[string]interface{}{}
+	cur := map[string]interface{}{}
+	headers := make([]metadata.Header, 0, len(hardware))
+	for _, change := range hardware {
+		pre[change.PropertyID] = change.PreValue
+		cur[change.PropertyID] = change.CurValue
+		headers = append(headers, metadata.Header{PropertyID: change.PropertyID, PropertyName: change.PropertyID})
+	}
+	log := metadata.AuditHostLogParams{
+		Content: metadata.Content{PreData: pre, CurData: cur, Headers: headers},
+		OpDesc:  "host hardware changed",
+		InnerIP: innerIP,
+		OpType:  auditoplog.AuditOpTypeModify,
+		HostID:  hostID,
+	}
+	ownerID := fmt.Sprint(curData[common.BKOwnerIDField])
+	result, err := h.engine.CoreAPI.AuditController().AddHostLog(context.Background(), ownerID, appID, common.CCSystemCollectorUserName, collectorHeader(curData), log)
+	if err != nil || (err == nil && !result.Result) {
+		blog.Errorf("save the hardware change audit log of host %d failed, err: %v, %v", hostID, err, result)
+	}
+}
+
+// collectorHeader the header of the requests made by the collector for the owner of the host
+func collectorHeader(host map[string]interface{}) http.Header {
+	header := make(http.Header)
+	header.Set(common.BKHTTPOwnerID, fmt.Sprint(host[common.BKOwnerIDField]))
+	header.Set(common.BKHTTPHeaderUser, common.CCSystemCollectorUserName)
+	return header
+}
+
+func (h *HostSnap) getHostAppID(hostID int64) (string, error) {
+	relation := map[string]interface{}{}
+	condition := map[string]interface{}{common.BKHostIDField: hostID}
+	if err := h.db.GetOneByCondition(common.BKTableNameModuleHostConfig, []string{common.BKAppIDField}, condition, &relation); err != nil {
+		return "", err
+	}
+	appID, err := util.GetInt64ByInterface(relation[common.BKAppIDField])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(appID), nil
+}
+
+// cleanHistory delete the host snapshot history older than the days kept
+func (h *HostSnap) cleanHistory() {
+	ticker := time.NewTicker(cleanHistoryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !h.isMaster || h.historyDays <= 0 {
+				continue
+			}
+			expire := time.Now().UTC().Add(-time.Duration(h.historyDays) * time.Hour * 24)
+			condition := map[string]interface{}{"bucket_time": map[string]interface{}{common.BKDBLT: expire}}
+			if err := h.db.DelByCondition(common.BKTableNameHostSnapHistory, condition); err != nil {
+				blog.Errorf("clean host snapshot history before %v failed, err: %v", expire, err)
+			}
+		case <-h.doneCh:
+			blog.Warnf("close cleanHistory")
+			return
+		}
+	}
+}
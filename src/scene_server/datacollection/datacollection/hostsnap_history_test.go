@@ -0,0 +1,112 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datacollection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+func TestDiffSnapFields(t *testing.T) {
+	now := time.Now()
+	pre := map[string]interface{}{"bk_mem": 4096, "bk_mac": "", "bk_os_name": "linux centos"}
+	setter := map[string]interface{}{"bk_mem": int64(4096), "bk_mac": "52:54:00:19:2e:e8", "bk_os_name": "linux ubuntu", "bk_cpu": int64(8)}
+	changes := diffSnapFields(pre, setter, now)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "bk_cpu", changes[0].PropertyID)
+	assert.Equal(t, "bk_mac", changes[1].PropertyID)
+	assert.Equal(t, "bk_os_name", changes[2].PropertyID)
+
+	assert.False(t, isHardwareChange(&changes[0]), "filled at the first time")
+	assert.False(t, isHardwareChange(&changes[1]), "filled at the first time")
+	assert.True(t, isHardwareChange(&changes[2]))
+	assert.True(t, isHardwareChange(&metadata.HostSnapChange{PreValue: 4096, CurValue: int64(2048)}))
+	assert.False(t, isHardwareChange(&metadata.HostSnapChange{PreValue: 0, CurValue: int64(2048)}))
+}
+
+func TestSaveHistory(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	h := NewHostSnap(nil, 0, nil, nil, db, nil, 0, 0)
+	now := time.Date(2018, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	setter := map[string]interface{}{"bk_mem": int64(4096)}
+	changes := diffSnapFields(map[string]interface{}{}, setter, now)
+	require.NoError(t, h.saveHistory(1, "0", setter, changes, now))
+	// the same values in the same bucket write nothing
+	require.NoError(t, h.saveHistory(1, "0", setter, nil, now.Add(time.Minute)))
+
+	later := now.Add(time.Hour)
+	shrank := map[string]interface{}{"bk_mem": int64(2048)}
+	require.NoError(t, h.saveHistory(1, "0", shrank, diffSnapFields(setter, shrank, later), later))
+
+	nextDay := now.Add(snapHistoryBucket)
+	require.NoError(t, h.saveHistory(1, "0", shrank, nil, nextDay))
+
+	history := make([]metadata.HostSnapHistory, 0)
+	condition := map[string]interface{}{common.BKHostIDField: 1}
+	require.NoError(t, db.GetMutilByCondition(common.BKTableNameHostSnapHistory, nil, condition, &history, "bucket_time", 0, 0))
+	require.Len(t, history, 2)
+	assert.Equal(t, now.Truncate(snapHistoryBucket), history[0].BucketTime.UTC())
+	assert.Equal(t, later, history[0].LastTime.UTC())
+	require.Len(t, history[0].Changes, 2)
+	assert.Equal(t, int64(2048), history[0].Changes[1].CurValue)
+	assert.Empty(t, history[1].Changes)
+	assert.Equal(t, int64(2048), history[1].Data["bk_mem"])
+}
+
+func TestSaveHistoryConcurrently(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	now := time.Date(2018, 10, 18, 10, 0, 0, 0, time.UTC)
+	setter := map[string]interface{}{"bk_mem": int64(4096)}
+	first := NewHostSnap(nil, 0, nil, nil, db, nil, 0, 0)
+	require.NoError(t, first.saveHistory(1, "0", setter, diffSnapFields(map[string]interface{}{}, setter, now), now))
+
+	// the collectors append their changes to the same bucket, none of them is lost
+	second := NewHostSnap(nil, 0, nil, nil, db, nil, 0, 0)
+	later := now.Add(time.Minute)
+	shrank := map[string]interface{}{"bk_mem": int64(2048)}
+	require.NoError(t, first.saveHistory(1, "0", shrank, diffSnapFields(setter, shrank, later), later))
+	require.NoError(t, second.saveHistory(1, "0", shrank, diffSnapFields(setter, shrank, later), later))
+
+	history := metadata.HostSnapHistory{}
+	condition := map[string]interface{}{common.BKHostIDField: 1}
+	require.NoError(t, db.GetOneByCondition(common.BKTableNameHostSnapHistory, nil, condition, &history))
+	require.Len(t, history.Changes, 3)
+	assert.Equal(t, int64(4096), history.Data["bk_mem"])
+}
+
+func TestSaveHistoryMaxChanges(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	h := NewHostSnap(nil, 0, nil, nil, db, nil, 0, 0)
+	now := time.Date(2018, 10, 18, 10, 0, 0, 0, time.UTC)
+	pre := map[string]interface{}{"bk_mem": int64(0)}
+	require.NoError(t, h.saveHistory(1, "0", pre, nil, now))
+	for i := 1; i <= snapHistoryMaxChanges+1; i++ {
+		cur := map[string]interface{}{"bk_mem": int64(i)}
+		require.NoError(t, h.saveHistory(1, "0", cur, diffSnapFields(pre, cur, now), now))
+		pre = cur
+	}
+
+	history := metadata.HostSnapHistory{}
+	condition := map[string]interface{}{common.BKHostIDField: 1}
+	require.NoError(t, db.GetOneByCondition(common.BKTableNameHostSnapHistory, nil, condition, &history))
+	require.Len(t, history.Changes, snapHistoryMaxChanges)
+	assert.Equal(t, int64(2), history.Changes[0].CurValue, "the earliest change is dropped")
+}
@@ -0,0 +1,290 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datacollection
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+)
+
+var fetchRuleInterval = time.Minute
+
+// defaultHostSnapRules the rules used for the attributes which are not configured in the database
+var defaultHostSnapRules = []metadata.HostSnapFieldRule{
+	{
+		PropertyID: "bk_cpu",
+		Path:       "data.cpu.cpuinfo.#.cores",
+		Transforms: []metadata.HostSnapTransform{{Type: metadata.HostSnapTransformSum}},
+		ValueType:  metadata.HostSnapValueTypeInt,
+	},
+	{
+		PropertyID: "bk_cpu_module",
+		Path:       "data.cpu.cpuinfo.0.modelName",
+		ValueType:  metadata.HostSnapValueTypeString,
+	},
+	{
+		PropertyID: "bk_cpu_mhz",
+		Path:       "data.cpu.cpuinfo.0.mhz",
+		ValueType:  metadata.HostSnapValueTypeInt,
+	},
+	{
+		PropertyID: "bk_disk",
+		Path:       "data.disk.usage.#.total",
+		Transforms: []metadata.HostSnapTransform{
+			{Type: metadata.HostSnapTransformSum},
+			{Type: metadata.HostSnapTransformScale, Scale: 1024 * 1024 * 1024},
+		},
+		ValueType: metadata.HostSnapValueTypeInt,
+	},
+	{
+		PropertyID: "bk_mem",
+		Path:       "data.mem.meminfo.total",
+		Transforms: []metadata.HostSnapTransform{{Type: metadata.HostSnapTransformScale, Scale: 1024 * 1024}},
+		ValueType:  metadata.HostSnapValueTypeInt,
+	},
+	{
+		PropertyID: "bk_host_name",
+		Path:       "data.system.info.hostname",
+		ValueType:  metadata.HostSnapValueTypeString,
+	},
+	{
+		PropertyID: "bk_os_bit",
+		Path:       "data.system.info.systemtype",
+		ValueType:  metadata.HostSnapValueTypeString,
+	},
+}
+
+// MergeHostSnapRules merge the rules configured over the default rules, the configured rules
+// replace the default ones of the same attribute
+func MergeHostSnapRules(configured []metadata.HostSnapFieldRule) []metadata.HostSnapFieldRule {
+	byField := make(map[string]metadata.HostSnapFieldRule, len(configured))
+	for _, rule := range configured {
+		// the rules saved before they were validated, such as the ones filling the host identity
+		if err := rule.Validate(); err != nil {
+			blog.Warnf("ignore the invalid host snapshot field rule %s, err: %v", rule.PropertyID, err)
+			continue
+		}
+		byField[rule.PropertyID] = rule
+	}
+
+	rules := make([]metadata.HostSnapFieldRule, 0, len(defaultHostSnapRules)+len(configured))
+	for _, rule := range defaultHostSnapRules {
+		if custom, ok := byField[rule.PropertyID]; ok {
+			rule = custom
+			delete(byField, rule.PropertyID)
+		} else {
+			rule.Enable = true
+		}
+		rule.Builtin = true
+		rules = append(rules, rule)
+	}
+
+	custom := make([]metadata.HostSnapFieldRule, 0, len(byField))
+	for _, rule := range byField {
+		custom = append(custom, rule)
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].PropertyID < custom[j].PropertyID })
+	return append(rules, custom...)
+}
+
+// hostSnapRuleCache the rules of each supplier account
+type hostSnapRuleCache struct {
+	sync.RWMutex
+	rules map[string][]metadata.HostSnapFieldRule
+}
+
+func (c *hostSnapRuleCache) get(ownerID string) []metadata.HostSnapFieldRule {
+	c.RLock()
+	defer c.RUnlock()
+	if rules, ok := c.rules[ownerID]; ok {
+		return rules
+	}
+	return c.rules[""]
+}
+
+func (c *hostSnapRuleCache) set(rules map[string][]metadata.HostSnapFieldRule) {
+	c.Lock()
+	c.rules = rules
+	c.Unlock()
+}
+
+func (h *HostSnap) fetchRules() {
+	h.loadRules()
+	ticker := time.NewTicker(fetchRuleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.loadRules()
+		case <-h.doneCh:
+			blog.Warnf("close fetchRules")
+			return
+		}
+	}
+}
+
+// loadRules load the rules configured, the last rules are kept when it fails
+func (h *HostSnap) loadRules() {
+	configured := make([]metadata.HostSnapFieldRule, 0)
+	if err := h.db.GetMutilByCondition(common.BKTableNameHostSnapRule, nil, nil, &configured, "", 0, 0); err != nil {
+		blog.Errorf("fetch host snapshot field rules failed, err: %v", err)
+		return
+	}
+
+	byOwner := map[string][]metadata.HostSnapFieldRule{}
+	for _, rule := range configured {
+		if err := rule.Validate(); err != nil {
+			blog.Errorf("ignore invalid host snapshot field rule %s of %s, err: %v", rule.PropertyID, rule.OwnerID, err)
+			continue
+		}
+		byOwner[rule.OwnerID] = append(byOwner[rule.OwnerID], rule)
+	}
+
+	rules := map[string][]metadata.HostSnapFieldRule{"": MergeHostSnapRules(nil)}
+	for ownerID, ownerRules := range byOwner {
+		rules[ownerID] = MergeHostSnapRules(ownerRules)
+	}
+	h.rules.set(rules)
+}
+
+var ruleRegexps = struct {
+	sync.RWMutex
+	data map[string]*regexp.Regexp
+}{data: map[string]*regexp.Regexp{}}
+
+func getRuleRegexp(pattern string) (*regexp.Regexp, error) {
+	ruleRegexps.RLock()
+	reg, ok := ruleRegexps.data[pattern]
+	ruleRegexps.RUnlock()
+	if ok {
+		return reg, nil
+	}
+
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	ruleRegexps.Lock()
+	ruleRegexps.data[pattern] = reg
+	ruleRegexps.Unlock()
+	return reg, nil
+}
+
+// extractField get the value of the attribute from the snapshot by the rule,
+// false is returned when the value is not found in the snapshot
+func extractField(val *gjson.Result, rule *metadata.HostSnapFieldRule) (interface{}, bool) {
+	result := val.Get(rule.Path)
+	if !result.Exists() {
+		return nil, false
+	}
+
+	// the values are float64 or string
+	values := []interface{}{}
+	results := []gjson.Result{result}
+	if result.IsArray() {
+		results = result.Array()
+	}
+	for _, item := range results {
+		if item.Type == gjson.Number {
+			values = append(values, item.Float())
+		} else {
+			values = append(values, item.String())
+		}
+	}
+
+	for _, transform := range rule.Transforms {
+		switch transform.Type {
+		case metadata.HostSnapTransformFirst:
+			if len(values) > 1 {
+				values = values[:1]
+			}
+		case metadata.HostSnapTransformSum:
+			var sum float64
+			for _, value := range values {
+				number, ok := toFloat(value)
+				if !ok {
+					return nil, false
+				}
+				sum += number
+			}
+			values = []interface{}{sum}
+		case metadata.HostSnapTransformScale:
+			if 0 == transform.Scale {
+				return nil, false
+			}
+			for idx, value := range values {
+				number, ok := toFloat(value)
+				if !ok {
+					return nil, false
+				}
+				values[idx] = number / transform.Scale
+			}
+		case metadata.HostSnapTransformRegexReplace:
+			reg, err := getRuleRegexp(transform.Pattern)
+			if err != nil {
+				return nil, false
+			}
+			for idx, value := range values {
+				values[idx] = reg.ReplaceAllString(toString(value), transform.Replace)
+			}
+		default:
+			return nil, false
+		}
+	}
+
+	if 0 == len(values) {
+		return nil, false
+	}
+	switch rule.ValueType {
+	case metadata.HostSnapValueTypeInt:
+		number, ok := toFloat(values[0])
+		if !ok {
+			return nil, false
+		}
+		return int64(number), true
+	case metadata.HostSnapValueTypeFloat:
+		return toFloat(values[0])
+	default:
+		return toString(values[0]), true
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, nil == err
+	}
+	return 0, false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datacollection
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/eventclient"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+var (
+	flushReportInterval = time.Minute
+	checkStaleInterval  = time.Minute * 5
+)
+
+// markReported remember the host reported a snapshot, the report times are written in batch
+func (h *HostSnap) markReported(hostID int64) {
+	h.reportLock.Lock()
+	h.reportedHosts[hostID] = true
+	h.reportLock.Unlock()
+}
+
+// flushReported save the report time of the hosts reported since the last flush, and bring the
+// hosts which were marked offline back online
+func (h *HostSnap) flushReported(now time.Time) error {
+	h.reportLock.Lock()
+	reported := h.reportedHosts
+	h.reportedHosts = map[int64]bool{}
+	h.reportLock.Unlock()
+	if 0 == len(reported) {
+		return nil
+	}
+
+	hostIDs := make([]int64, 0, len(reported))
+	for hostID := range reported {
+		hostIDs = append(hostIDs, hostID)
+	}
+	now = now.UTC()
+
+	offline := make([]map[string]interface{}, 0)
+	offlineCond := map[string]interface{}{
+		common.BKHostIDField:           map[string]interface{}{common.BKDBIN: hostIDs},
+		common.BKAgentOfflineTimeField: map[string]interface{}{common.BKDBNE: nil},
+	}
+	if err := h.db.GetMutilByCondition(common.BKTableNameBaseHost, nil, offlineCond, &offline, "", 0, 0); err != nil {
+		return err
+	}
+
+	condition := map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs}}
+	data := map[string]interface{}{common.BKLastReportTimeField: now}
+	if err := h.db.UpdateByCondition(common.BKTableNameBaseHost, data, condition); err != nil {
+		return err
+	}
+
+	for _, preData := range offline {
+		delete(preData, "_id")
+		hostID, err := util.GetInt64ByInterface(preData[common.BKHostIDField])
+		if err != nil {
+			blog.Warnf("host id %v is not integer, continue", preData[common.BKHostIDField])
+			continue
+		}
+		condition := map[string]interface{}{common.BKHostIDField: hostID}
+		data := map[string]interface{}{common.BKAgentOfflineTimeField: nil}
+		if err := h.db.UpdateByCondition(common.BKTableNameBaseHost, data, condition); err != nil {
+			blog.Errorf("bring host %d back online failed, err: %v", hostID, err)
+			continue
+		}
+		blog.Infof("the agent of host %d reports again, offline since %v", hostID, preData[common.BKAgentOfflineTimeField])
+		curData := copyHostData(preData)
+		curData[common.BKLastReportTimeField] = now
+		curData[common.BKAgentOfflineTimeField] = nil
+		h.sendHostUpdateEvent(hostID, curData, preData)
+	}
+	return nil
+}
+
+// checkStale mark the hosts whose agents have not reported in the stale hours offline since their
+// last report, the hosts which never reported are left alone, as they may have no agent at all
+func (h *HostSnap) checkStale(now time.Time) error {
+	if h.staleHours <= 0 {
+		return nil
+	}
+	before := now.UTC().Add(-time.Duration(h.staleHours) * time.Hour)
+	condition := map[string]interface{}{
+		common.BKLastReportTimeField:   map[string]interface{}{common.BKDBLT: before},
+		common.BKAgentOfflineTimeField: nil,
+	}
+	stale := make([]map[string]interface{}, 0)
+	if err := h.db.GetMutilByCondition(common.BKTableNameBaseHost, nil, condition, &stale, "", 0, 0); err != nil {
+		return err
+	}
+
+	for _, preData := range stale {
+		delete(preData, "_id")
+		hostID, err := util.GetInt64ByInterface(preData[common.BKHostIDField])
+		if err != nil {
+			blog.Warnf("host id %v is not integer, continue", preData[common.BKHostIDField])
+			continue
+		}
+		offlineTime := preData[common.BKLastReportTimeField]
+		// the host may report between the search and the update
+		condition := map[string]interface{}{
+			common.BKHostIDField:           hostID,
+			common.BKLastReportTimeField:   offlineTime,
+			common.BKAgentOfflineTimeField: nil,
+		}
+		data := map[string]interface{}{common.BKAgentOfflineTimeField: offlineTime}
+		if err := h.db.UpdateByCondition(common.BKTableNameBaseHost, data, condition); err != nil {
+			blog.Errorf("mark host %d offline failed, err: %v", hostID, err)
+			continue
+		}
+		blog.Infof("the agent of host %d has not reported since %v, mark it offline", hostID, offlineTime)
+		curData := copyHostData(preData)
+		curData[common.BKAgentOfflineTimeField] = offlineTime
+		h.sendHostUpdateEvent(hostID, curData, preData)
+	}
+	return nil
+}
+
+// sendHostUpdateEvent send the host update event as the collector
+func (h *HostSnap) sendHostUpdateEvent(hostID int64, curData, preData map[string]interface{}) {
+	ec := eventclient.NewEventContextByReq(collectorHeader(curData), h.redisCli)
+	if err := ec.InsertEvent(metadata.EventTypeInstData, common.BKInnerObjIDHost, metadata.EventActionUpdate, curData, preData); err != nil {
+		blog.Errorf("send the update event of host %d failed, err: %v", hostID, err)
+	}
+}
+
+func copyHostData(data map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		cp[key] = value
+	}
+	return cp
+}
+
+// watchReport flush the report times, and check the stale hosts on the master
+func (h *HostSnap) watchReport() {
+	flushTicker := time.NewTicker(flushReportInterval)
+	defer flushTicker.Stop()
+	staleTicker := time.NewTicker(checkStaleInterval)
+	defer staleTicker.Stop()
+	for {
+		select {
+		case <-flushTicker.C:
+			if err := h.flushReported(time.Now()); err != nil {
+				blog.Errorf("save the report time of the hosts failed, err: %v", err)
+			}
+		case <-staleTicker.C:
+			if !h.isMaster {
+				continue
+			}
+			if err := h.checkStale(time.Now()); err != nil {
+				blog.Errorf("check the stale hosts failed, err: %v", err)
+			}
+		case <-h.doneCh:
+			if err := h.flushReported(time.Now()); err != nil {
+				blog.Errorf("save the report time of the hosts failed, err: %v", err)
+			}
+			blog.Warnf("close watchReport")
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datacollection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/storage/memclient"
+)
+
+func getTestHost(t *testing.T, h *HostSnap, hostID int64) map[string]interface{} {
+	host := map[string]interface{}{}
+	condition := map[string]interface{}{common.BKHostIDField: hostID}
+	require.NoError(t, h.db.GetOneByCondition(common.BKTableNameBaseHost, nil, condition, &host))
+	return host
+}
+
+func assertTime(t *testing.T, expect time.Time, actual interface{}) {
+	ts, ok := actual.(time.Time)
+	require.True(t, ok, "%v is not time", actual)
+	assert.True(t, expect.Equal(ts), "expect %v, got %v", expect, ts)
+}
+
+func TestHostReportStale(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	h := NewHostSnap(nil, 0, nil, nil, db, nil, 0, 24)
+	for _, hostID := range []int64{1, 2, 3} {
+		_, err := db.Insert(common.BKTableNameBaseHost, map[string]interface{}{common.BKHostIDField: hostID, common.BKOwnerIDField: "0"})
+		require.NoError(t, err)
+	}
+
+	now := time.Date(2018, 10, 19, 12, 0, 0, 0, time.UTC)
+	h.markReported(1)
+	h.markReported(2)
+	require.NoError(t, h.flushReported(now))
+	assertTime(t, now, getTestHost(t, h, 1)[common.BKLastReportTimeField])
+	assert.Nil(t, getTestHost(t, h, 3)[common.BKLastReportTimeField])
+	// nothing reported since the last flush
+	require.NoError(t, h.flushReported(now.Add(time.Minute)))
+	assertTime(t, now, getTestHost(t, h, 1)[common.BKLastReportTimeField])
+
+	later := now.Add(time.Hour * 20)
+	h.markReported(2)
+	require.NoError(t, h.flushReported(later))
+
+	// host 1 has not reported in 24 hours, host 3 never reported
+	require.NoError(t, h.checkStale(now.Add(time.Hour*25)))
+	assertTime(t, now, getTestHost(t, h, 1)[common.BKAgentOfflineTimeField])
+	assert.Nil(t, getTestHost(t, h, 2)[common.BKAgentOfflineTimeField])
+	assert.Nil(t, getTestHost(t, h, 3)[common.BKAgentOfflineTimeField])
+
+	// host 1 reports again
+	back := now.Add(time.Hour * 30)
+	h.markReported(1)
+	require.NoError(t, h.flushReported(back))
+	host := getTestHost(t, h, 1)
+	assertTime(t, back, host[common.BKLastReportTimeField])
+	assert.Nil(t, host[common.BKAgentOfflineTimeField])
+
+	disabled := NewHostSnap(nil, 0, nil, nil, db, nil, 0, 0)
+	require.NoError(t, disabled.checkStale(now.Add(time.Hour*1000)))
+	assert.Nil(t, getTestHost(t, h, 2)[common.BKAgentOfflineTimeField])
+}
@@ -17,42 +17,45 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/gjson"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
 )
 
 func TestNeedToUpdate(t *testing.T) {
-	need := needToUpdate(map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "a"})
+	need := needToUpdate(map[string]interface{}{"name": "a"}, &HostInst{data: map[string]interface{}{"name": "a"}})
 	if need {
 		t.Errorf("not neet to update but got %v", need)
 	}
-	need = needToUpdate(map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"})
+	need = needToUpdate(map[string]interface{}{"name": "a"}, &HostInst{data: map[string]interface{}{"name": "b"}})
 	if !need {
 		t.Errorf("not neet to update but got %v", need)
 	}
-	need = needToUpdate(map[string]interface{}{"name": 1}, map[string]interface{}{"name": 1})
+	need = needToUpdate(map[string]interface{}{"name": 1}, &HostInst{data: map[string]interface{}{"name": 1}})
 	if need {
 		t.Errorf("not neet to update but got %v", need)
 	}
 }
 
 func TestGetIPS(t *testing.T) {
-	val := gjson.Parse(example)
+	val := gjson.Parse(`{"ip":"10.0.0.3","data":{"net":{"interface":[{"addrs":[{"addr":"127.0.0.1/8"}]},{"addrs":[{"addr":"10.0.0.1/24"},{"addr":"10.0.0.2/24"}]}]}}}`)
 	ips := getIPS(&val)
-	assert.Equal(t, []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}, ips)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, ips)
 }
 
 func TestGetSetter(t *testing.T) {
 	val := gjson.Parse(example)
-	actual := parseSetter(&val, "127.0.0.1", "127.0.0.2")
+	actual := parseSetter(&val, "127.0.0.1", "127.0.0.2", MergeHostSnapRules(nil))
 	expected := map[string]interface{}{
 		"bk_cpu":        int64(1),
-		"bk_disk":       int64(10079),
+		"bk_disk":       int64(9),
 		"bk_mem":        int64(15794),
 		"bk_os_version": "6.2",
 		"bk_host_name":  "test-master-02",
-		"bk_outer_mac":  "",
+		"bk_outer_mac":  "28:31:52:1d:c6:0a",
 		"bk_cpu_module": "Intel(R) Xeon(R) CPU E3-1230 V2 @ 3.30GHz",
 		"bk_cpu_mhz":    int64(3301),
-		"bk_os_type":    "Linux",
+		"bk_os_type":    common.HostOSTypeEnumLinux,
 		"bk_os_name":    "linux centos",
 		"bk_mac":        "28:31:52:1d:c6:0a",
 	}
@@ -63,6 +66,61 @@ func TestGetSetter(t *testing.T) {
 	}
 }
 
+func TestGetSetterByRules(t *testing.T) {
+	val := gjson.Parse(example)
+	rules := MergeHostSnapRules([]metadata.HostSnapFieldRule{
+		{PropertyID: "bk_mem", Enable: false},
+		{PropertyID: "bk_os_name", Path: "data.system.info.platform", Enable: true},
+		{
+			PropertyID: "kernel_version",
+			Path:       "data.system.info.kernelVersion",
+			Transforms: []metadata.HostSnapTransform{{Type: metadata.HostSnapTransformRegexReplace, Pattern: `-.*$`}},
+			Enable:     true,
+		},
+		{PropertyID: "missing", Path: "data.gpu.count", ValueType: metadata.HostSnapValueTypeInt, Enable: true},
+		{PropertyID: "bk_host_innerip", Path: "data.system.info.hostname", Enable: true},
+	})
+	actual := parseSetter(&val, "127.0.0.1", "127.0.0.2", rules)
+	assert.NotContains(t, actual, "bk_mem")
+	assert.NotContains(t, actual, "missing")
+	assert.NotContains(t, actual, "bk_host_innerip", "the rule filling the host identity is ignored")
+	assert.Equal(t, "centos", actual["bk_os_name"])
+	assert.Equal(t, "2.6.32.43", actual["kernel_version"])
+	assert.Equal(t, int64(9), actual["bk_disk"])
+}
+
+func TestExtractField(t *testing.T) {
+	val := gjson.Parse(`{"disks":[{"size":"1536"},{"size":512}],"name":"a-b-c","ratio":0.5}`)
+	value, ok := extractField(&val, &metadata.HostSnapFieldRule{
+		Path: "disks.#.size",
+		Transforms: []metadata.HostSnapTransform{
+			{Type: metadata.HostSnapTransformSum},
+			{Type: metadata.HostSnapTransformScale, Scale: 1024},
+		},
+		ValueType: metadata.HostSnapValueTypeFloat,
+	})
+	assert.True(t, ok)
+	assert.Equal(t, float64(2), value)
+
+	value, ok = extractField(&val, &metadata.HostSnapFieldRule{Path: "disks.#.size", ValueType: metadata.HostSnapValueTypeInt})
+	assert.True(t, ok)
+	assert.Equal(t, int64(1536), value)
+
+	value, ok = extractField(&val, &metadata.HostSnapFieldRule{
+		Path:       "name",
+		Transforms: []metadata.HostSnapTransform{{Type: metadata.HostSnapTransformRegexReplace, Pattern: "-", Replace: "_"}},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "a_b_c", value)
+
+	value, ok = extractField(&val, &metadata.HostSnapFieldRule{Path: "ratio"})
+	assert.True(t, ok)
+	assert.Equal(t, "0.5", value)
+
+	_, ok = extractField(&val, &metadata.HostSnapFieldRule{Path: "name", ValueType: metadata.HostSnapValueTypeInt})
+	assert.False(t, ok)
+}
+
 var example = `{
     "bizid":0,
     "cloudid":0,
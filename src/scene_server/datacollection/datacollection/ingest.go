@@ -0,0 +1,275 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datacollection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	bkc "configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+)
+
+const (
+	// MaxIngestInsts the max count of the instances in an ingestion request
+	MaxIngestInsts = 1000
+
+	IngestActionCreate = "create"
+	IngestActionUpdate = "update"
+	IngestActionNone   = "none"
+	IngestActionFailed = "failed"
+)
+
+var ingestSourceRegexp = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,64}$`)
+
+// IngestRequest the objects discovered by an external source, e.g. a network device scanner or a
+// cloud inventory, they are saved the same way as the discover messages published to redis
+type IngestRequest struct {
+	// Source the name of the discovery source, the instances are identified in the source
+	Source string `json:"source"`
+	// DryRun only reports what would be changed
+	DryRun  bool           `json:"dry_run"`
+	Objects []IngestObject `json:"objects"`
+}
+
+// IngestObject the model, the attributes and the instances of a discovered object, the
+// instances are identified by the values of the fields in bk_obj_keys
+type IngestObject struct {
+	Model  Model                         `json:"model"`
+	Fields map[string]metadata.ObjAttDes `json:"fields"`
+	Insts  []M                           `json:"insts"`
+}
+
+type IngestResult struct {
+	Source  string               `json:"source"`
+	DryRun  bool                 `json:"dry_run"`
+	Objects []IngestObjectResult `json:"objects"`
+}
+
+type IngestObjectResult struct {
+	ObjectID    string             `json:"bk_obj_id"`
+	CreateModel bool               `json:"create_model"`
+	NewAttrs    []string           `json:"new_attrs"`
+	Insts       []IngestInstResult `json:"insts"`
+	Error       string             `json:"error,omitempty"`
+}
+
+type IngestInstResult struct {
+	InstKey string `json:"bk_inst_key"`
+	Action  string `json:"action"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Validate check the source, the models and that every instance has its identity fields
+func (r *IngestRequest) Validate() error {
+	if !ingestSourceRegexp.MatchString(r.Source) {
+		return fmt.Errorf("invalid source %s, it must be letters, digits, _ or - and at most 64 characters", r.Source)
+	}
+	if 0 == len(r.Objects) {
+		return fmt.Errorf("no objects")
+	}
+	total := 0
+	for idx := range r.Objects {
+		obj := &r.Objects[idx]
+		if "" == obj.Model.BkObjID || "" == obj.Model.BkClassificationID {
+			return fmt.Errorf("the model of object %d has no bk_obj_id or bk_classification_id", idx)
+		}
+		if 0 == len(obj.identityFields()) {
+			return fmt.Errorf("the model %s has no bk_obj_keys", obj.Model.BkObjID)
+		}
+		for _, inst := range obj.Insts {
+			if _, err := obj.instKey(r.Source, inst); err != nil {
+				return err
+			}
+		}
+		total += len(obj.Insts)
+	}
+	if total > MaxIngestInsts {
+		return fmt.Errorf("%d instances exceed the limit %d", total, MaxIngestInsts)
+	}
+	return nil
+}
+
+func (o *IngestObject) identityFields() []string {
+	fields := make([]string, 0)
+	for _, field := range strings.Split(o.Model.Keys, ",") {
+		if field = strings.TrimSpace(field); "" != field {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// instKey the identity of the instance in the source, the values of the identity fields are
+// prefixed by the source and the object, so the sources never overwrite each other
+func (o *IngestObject) instKey(source string, inst M) (string, error) {
+	values := make([]string, 0)
+	for _, field := range o.identityFields() {
+		value, ok := inst[field]
+		if !ok || nil == value || "" == fmt.Sprint(value) {
+			return "", fmt.Errorf("an instance of %s has no identity field %s", o.Model.BkObjID, field)
+		}
+		values = append(values, fmt.Sprint(value))
+	}
+	return fmt.Sprintf("%s:%s:%s", source, o.Model.BkObjID, strings.Join(values, ":")), nil
+}
+
+// discoverMsg make the discover message of the instance, which is handled by TryCreateModel,
+// UpdateOrAppendAttrs and UpdateOrCreateInst as the messages published to redis
+func (o *IngestObject) discoverMsg(ownerID, instKey string, inst M) (string, error) {
+	data := make(M, len(inst)+2)
+	for key, value := range inst {
+		data[key] = value
+	}
+	data[bkc.BKInstKeyField] = instKey
+	if _, ok := data[bkc.BKInstNameField]; !ok {
+		data[bkc.BKInstNameField] = instKey
+	}
+
+	msg := M{
+		"data": M{
+			"meta": M{"model": o.Model, "fields": o.Fields},
+			"data": data,
+			"host": M{bkc.BKOwnerIDField: ownerID},
+		},
+	}
+	js, err := msg.toJson()
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+// Ingest save the discovered objects of the request, or only plan the changes in the dry run
+func (d *Discover) Ingest(ownerID string, req *IngestRequest) *IngestResult {
+	result := &IngestResult{Source: req.Source, DryRun: req.DryRun, Objects: make([]IngestObjectResult, 0, len(req.Objects))}
+	for idx := range req.Objects {
+		result.Objects = append(result.Objects, d.ingestObject(ownerID, req.Source, req.DryRun, &req.Objects[idx]))
+	}
+	return result
+}
+
+func (d *Discover) ingestObject(ownerID, source string, dryRun bool, obj *IngestObject) IngestObjectResult {
+	result := IngestObjectResult{ObjectID: obj.Model.BkObjID, NewAttrs: make([]string, 0), Insts: make([]IngestInstResult, 0, len(obj.Insts))}
+
+	exists, err := d.GetModel(obj.Model, ownerID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.CreateModel = !exists
+
+	existAttrs := make([]metadata.Attribute, 0)
+	if exists {
+		existAttrs, err = d.GetAttrs(ownerID, obj.Model.BkObjID, d.CreateModelAttrKey(obj.Model, ownerID), obj.Fields)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+	existAttrHash := make(map[string]bool, len(existAttrs))
+	for _, attr := range existAttrs {
+		existAttrHash[attr.PropertyID] = true
+	}
+	for propertyID := range obj.Fields {
+		if !existAttrHash[propertyID] && propertyID != bkc.BKInstNameField {
+			result.NewAttrs = append(result.NewAttrs, propertyID)
+		}
+	}
+
+	checkModel := true
+	for _, inst := range obj.Insts {
+		instKey, _ := obj.instKey(source, inst)
+		instResult := IngestInstResult{InstKey: instKey}
+
+		msg, err := obj.discoverMsg(ownerID, instKey, inst)
+		if err != nil {
+			instResult.Action, instResult.Error = IngestActionFailed, err.Error()
+			result.Insts = append(result.Insts, instResult)
+			continue
+		}
+
+		instResult.Action = IngestActionCreate
+		if exists {
+			instResult.Action, err = d.planInst(ownerID, obj, instKey, msg)
+			if err != nil {
+				instResult.Action, instResult.Error = IngestActionFailed, err.Error()
+				result.Insts = append(result.Insts, instResult)
+				continue
+			}
+		}
+
+		if !dryRun && (checkModel || IngestActionNone != instResult.Action) {
+			if err := d.saveDiscoverMsg(msg, checkModel); err != nil {
+				blog.Errorf("ingest instance %s from %s failed, err: %v", instKey, source, err)
+				instResult.Action, instResult.Error = IngestActionFailed, err.Error()
+			} else {
+				checkModel = false
+			}
+		}
+		result.Insts = append(result.Insts, instResult)
+	}
+	return result
+}
+
+// planInst get whether the instance would be created, updated or left unchanged
+func (d *Discover) planInst(ownerID string, obj *IngestObject, instKey, msg string) (string, error) {
+	inst, err := d.GetInst(ownerID, obj.Model.BkObjID, obj.identityFields(), instKey)
+	if err != nil {
+		return "", err
+	}
+	if 0 == len(inst) {
+		return IngestActionCreate, nil
+	}
+	data, err := d.parseData(msg)
+	if err != nil {
+		return "", err
+	}
+	if mergeInstData(inst, data) {
+		return IngestActionUpdate, nil
+	}
+	return IngestActionNone, nil
+}
+
+// saveDiscoverMsg save the message like handleMsg, the model and the attributes are checked
+// until they are saved with an instance of the object
+func (d *Discover) saveDiscoverMsg(msg string, withModel bool) error {
+	if withModel {
+		if err := d.TryCreateModel(msg); err != nil {
+			return fmt.Errorf("create model err: %s", err)
+		}
+		if err := d.UpdateOrAppendAttrs(msg); err != nil {
+			return fmt.Errorf("create attr err: %s", err)
+		}
+	}
+	if err := d.UpdateOrCreateInst(msg); err != nil {
+		return fmt.Errorf("create inst err: %s", err)
+	}
+	return nil
+}
+
+// DecodeIngestRequest decode the request body, the numbers are kept as json numbers so the
+// identity values are not formatted as floats
+func DecodeIngestRequest(body io.Reader) (*IngestRequest, error) {
+	req := new(IngestRequest)
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	if err := decoder.Decode(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
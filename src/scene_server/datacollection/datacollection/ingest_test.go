@@ -0,0 +1,79 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datacollection
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+)
+
+const testIngestBody = `{
+    "source": "netscan",
+    "objects": [{
+        "model": {"bk_classification_id": "network", "bk_obj_id": "switch", "bk_obj_name": "switch", "bk_obj_keys": "sn, mgmt_ip"},
+        "fields": {"sn": {"bk_property_name": "SN", "bk_property_type": "singlechar"}},
+        "insts": [{"sn": 10000001, "mgmt_ip": "10.0.0.1", "ports": 48}]
+    }]
+}`
+
+func TestIngestRequest(t *testing.T) {
+	req, err := DecodeIngestRequest(strings.NewReader(testIngestBody))
+	require.NoError(t, err)
+	require.NoError(t, req.Validate())
+
+	obj := &req.Objects[0]
+	assert.Equal(t, []string{"sn", "mgmt_ip"}, obj.identityFields())
+	key, err := obj.instKey(req.Source, obj.Insts[0])
+	require.NoError(t, err)
+	assert.Equal(t, "netscan:switch:10000001:10.0.0.1", key)
+
+	msg, err := obj.discoverMsg("0", key, obj.Insts[0])
+	require.NoError(t, err)
+	d := &Discover{}
+	assert.Equal(t, "switch", d.parseObjID(msg))
+	assert.Equal(t, "0", d.parseOwnerId(msg))
+	model, err := d.parseModel(msg)
+	require.NoError(t, err)
+	assert.Equal(t, obj.Model, *model)
+	attrs, err := d.parseAttrs(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "SN", attrs["sn"].PropertyName)
+	data, err := d.parseData(msg)
+	require.NoError(t, err)
+	assert.Equal(t, key, data[common.BKInstKeyField])
+	assert.Equal(t, key, data[common.BKInstNameField])
+
+	// the instance found by the key is updated only when the values change
+	inst := map[string]interface{}{"sn": float64(10000001), "mgmt_ip": "10.0.0.1", "ports": float64(48), common.BKInstKeyField: key, common.BKInstNameField: key}
+	assert.False(t, mergeInstData(inst, data))
+	inst["ports"] = float64(24)
+	assert.True(t, mergeInstData(inst, data))
+	assert.Equal(t, float64(48), inst["ports"])
+
+	invalid := []string{
+		`{"source": "net scan", "objects": [{"model": {"bk_classification_id": "network", "bk_obj_id": "switch", "bk_obj_keys": "sn"}}]}`,
+		`{"source": "netscan", "objects": []}`,
+		`{"source": "netscan", "objects": [{"model": {"bk_classification_id": "network", "bk_obj_id": "switch"}}]}`,
+		`{"source": "netscan", "objects": [{"model": {"bk_classification_id": "network", "bk_obj_id": "switch", "bk_obj_keys": "sn"}, "insts": [{"sn": ""}]}]}`,
+	}
+	for _, body := range invalid {
+		req, err := DecodeIngestRequest(strings.NewReader(body))
+		require.NoError(t, err)
+		assert.Error(t, req.Validate(), body)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// defaultSnapHistoryRange the time range searched when the start time is not set
+const defaultSnapHistoryRange = time.Hour * 24 * 7
+
+// SearchHostSnapHistory get the snapshot history of the host in the time range, the buckets
+// which overlap with the range are returned in time order
+func (s *Service) SearchHostSnapHistory(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+	if nil == s.db {
+		resp.WriteError(http.StatusServiceUnavailable, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	hostID, err := strconv.ParseInt(req.PathParameter(common.BKHostIDField), 10, 64)
+	if err != nil {
+		blog.Errorf("search host snapshot history failed, invalid host id %s", req.PathParameter(common.BKHostIDField))
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsNeedInt, common.BKHostIDField)})
+		return
+	}
+
+	params := new(meta.HostSnapHistoryParams)
+	if err := json.NewDecoder(req.Request.Body).Decode(params); err != nil {
+		blog.Errorf("search host snapshot history failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	end := time.Now().UTC()
+	if nil != params.EndTime {
+		end = params.EndTime.UTC()
+	}
+	start := end.Add(-defaultSnapHistoryRange)
+	if nil != params.StartTime {
+		start = params.StartTime.UTC()
+	}
+	if start.After(end) {
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "start_time")})
+		return
+	}
+
+	// a bucket overlaps with the range when it is last written after the start and begins before the end
+	condition := util.SetModOwner(map[string]interface{}{
+		common.BKHostIDField: hostID,
+		"last_time":          map[string]interface{}{common.BKDBGTE: start},
+		"bucket_time":        map[string]interface{}{common.BKDBLTE: end},
+	}, ownerID)
+	history := make([]meta.HostSnapHistory, 0)
+	if err := s.db.GetMutilByCondition(common.BKTableNameHostSnapHistory, nil, condition, &history, "bucket_time", 0, 0); err != nil {
+		blog.Errorf("search host snapshot history failed, err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	resp.WriteEntity(meta.HostSnapHistoryResult{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     history,
+	})
+}
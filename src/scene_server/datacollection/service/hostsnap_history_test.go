@@ -0,0 +1,62 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/http/resttest"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+func TestSearchHostSnapHistory(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	s := &Service{Engine: resttest.NewEngine()}
+	s.SetDB(db)
+	container := resttest.NewContainer(s.WebService())
+
+	day := time.Date(2018, 10, 15, 0, 0, 0, 0, time.UTC)
+	for idx := 0; idx < 3; idx++ {
+		bucket := day.Add(time.Duration(idx) * time.Hour * 24)
+		_, err := db.Insert(common.BKTableNameHostSnapHistory, meta.HostSnapHistory{
+			HostID:     1,
+			OwnerID:    "0",
+			BucketTime: bucket,
+			LastTime:   bucket.Add(time.Hour * 23),
+			Data:       map[string]interface{}{"bk_mem": idx},
+		})
+		require.NoError(t, err)
+	}
+
+	start := day.Add(time.Hour * 30)
+	end := day.Add(time.Hour * 50)
+	result := meta.HostSnapHistoryResult{}
+	code := resttest.DoRequest(t, container, http.MethodPost, "/collector/v3/hostsnap/history/1", meta.HostSnapHistoryParams{StartTime: &start, EndTime: &end}, &result)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, result.Data, 2)
+	assert.True(t, result.Data[0].BucketTime.Equal(day.Add(time.Hour*24)))
+	assert.True(t, result.Data[1].BucketTime.Equal(day.Add(time.Hour*48)))
+
+	code = resttest.DoRequest(t, container, http.MethodPost, "/collector/v3/hostsnap/history/1", meta.HostSnapHistoryParams{StartTime: &end, EndTime: &start}, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code = resttest.DoRequest(t, container, http.MethodPost, "/collector/v3/hostsnap/history/x", meta.HostSnapHistoryParams{}, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
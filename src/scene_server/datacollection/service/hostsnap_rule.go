@@ -0,0 +1,149 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/datacollection/datacollection"
+	"configcenter/src/storage"
+)
+
+// SearchHostSnapRules get the rules which fill the host attributes from the host snapshot,
+// the default rules are merged with the rules configured
+func (s *Service) SearchHostSnapRules(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+	if nil == s.db {
+		resp.WriteError(http.StatusServiceUnavailable, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	configured := make([]meta.HostSnapFieldRule, 0)
+	condition := map[string]interface{}{common.BKOwnerIDField: ownerID}
+	if err := s.db.GetMutilByCondition(common.BKTableNameHostSnapRule, nil, condition, &configured, common.BKPropertyIDField, 0, 0); err != nil {
+		blog.Errorf("search host snapshot field rules failed, err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	resp.WriteEntity(meta.HostSnapFieldRulesResult{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     datacollection.MergeHostSnapRules(configured),
+	})
+}
+
+// SaveHostSnapRule create or replace the rule of the host attribute, the attribute must be a property of the host model
+func (s *Service) SaveHostSnapRule(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+	if nil == s.db {
+		resp.WriteError(http.StatusServiceUnavailable, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	rule := new(meta.HostSnapFieldRule)
+	if err := json.NewDecoder(req.Request.Body).Decode(rule); err != nil {
+		blog.Errorf("save host snapshot field rule failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	rule.PropertyID = req.PathParameter(common.BKPropertyIDField)
+	if err := rule.Validate(); err != nil {
+		blog.Errorf("save host snapshot field rule failed, invalid rule %+v, err: %v", rule, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, err.Error())})
+		return
+	}
+
+	attrCond := util.SetQueryOwner(map[string]interface{}{
+		common.BKObjIDField:      common.BKInnerObjIDHost,
+		common.BKPropertyIDField: rule.PropertyID,
+	}, ownerID)
+	cnt, err := s.db.GetCntByCondition(common.BKTableNameObjAttDes, attrCond)
+	if err != nil {
+		blog.Errorf("save host snapshot field rule failed, get host attribute %s failed, err: %v", rule.PropertyID, err)
+		resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+	if 0 == cnt {
+		blog.Errorf("save host snapshot field rule failed, %s is not a host attribute", rule.PropertyID)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, common.BKPropertyIDField)})
+		return
+	}
+
+	now := time.Now().UTC()
+	rule.OwnerID = ownerID
+	rule.Modifier = util.GetUser(pheader)
+	rule.LastTime = &now
+	condition := map[string]interface{}{
+		common.BKPropertyIDField: rule.PropertyID,
+		common.BKOwnerIDField:    ownerID,
+	}
+	err = s.db.WithTransaction(func(db storage.DI) error {
+		if err := db.DelByCondition(common.BKTableNameHostSnapRule, condition); err != nil && !db.IsNotFoundErr(err) {
+			return err
+		}
+		_, err := db.Insert(common.BKTableNameHostSnapRule, rule)
+		return err
+	})
+	if err != nil {
+		blog.Errorf("save host snapshot field rule %+v failed, err: %v", rule, err)
+		resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBInsertFailed)})
+		return
+	}
+
+	resp.WriteEntity(meta.NewSuccessResp(rule))
+}
+
+// DeleteHostSnapRule delete the rule of the host attribute, the default rule is used again if there is one
+func (s *Service) DeleteHostSnapRule(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+	if nil == s.db {
+		resp.WriteError(http.StatusServiceUnavailable, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	condition := map[string]interface{}{
+		common.BKPropertyIDField: req.PathParameter(common.BKPropertyIDField),
+		common.BKOwnerIDField:    ownerID,
+	}
+	cnt, err := s.db.GetCntByCondition(common.BKTableNameHostSnapRule, condition)
+	if err != nil {
+		blog.Errorf("delete host snapshot field rule failed, err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+	if 0 == cnt {
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+		return
+	}
+	if err := s.db.DelByCondition(common.BKTableNameHostSnapRule, condition); err != nil {
+		blog.Errorf("delete host snapshot field rule failed, err: %v", err)
+		resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBDeleteFailed)})
+		return
+	}
+
+	resp.WriteEntity(meta.NewSuccessResp(nil))
+}
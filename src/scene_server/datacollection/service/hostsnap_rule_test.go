@@ -0,0 +1,68 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/http/resttest"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+func TestHostSnapRules(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	s := &Service{Engine: resttest.NewEngine()}
+	s.SetDB(db)
+	container := resttest.NewContainer(s.WebService())
+
+	rule := meta.HostSnapFieldRule{Path: "data.system.info.kernelVersion", Enable: true}
+	code := resttest.DoRequest(t, container, http.MethodPut, "/collector/v3/hostsnap/rules/kernel_version", rule, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code, "only the host attributes can be filled")
+
+	_, err := db.Insert(common.BKTableNameObjAttDes, map[string]interface{}{
+		common.BKObjIDField:      common.BKInnerObjIDHost,
+		common.BKPropertyIDField: "kernel_version",
+		common.BKOwnerIDField:    "0",
+	})
+	require.NoError(t, err)
+	code = resttest.DoRequest(t, container, http.MethodPut, "/collector/v3/hostsnap/rules/kernel_version", rule, &meta.Response{})
+	require.Equal(t, http.StatusOK, code)
+
+	invalid := meta.HostSnapFieldRule{Enable: true}
+	code = resttest.DoRequest(t, container, http.MethodPut, "/collector/v3/hostsnap/rules/kernel_version", invalid, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	identity := meta.HostSnapFieldRule{Path: "data.net.interface.0.addrs.0.addr", Enable: true}
+	code = resttest.DoRequest(t, container, http.MethodPut, "/collector/v3/hostsnap/rules/bk_host_innerip", identity, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code, "the fields identifying the host can not be filled")
+
+	rules := meta.HostSnapFieldRulesResult{}
+	code = resttest.DoRequest(t, container, http.MethodGet, "/collector/v3/hostsnap/rules", nil, &rules)
+	require.Equal(t, http.StatusOK, code)
+	last := rules.Data[len(rules.Data)-1]
+	assert.Equal(t, "kernel_version", last.PropertyID)
+	assert.Equal(t, "admin", last.Modifier)
+	assert.False(t, last.Builtin)
+	assert.True(t, rules.Data[0].Builtin)
+
+	code = resttest.DoRequest(t, container, http.MethodDelete, "/collector/v3/hostsnap/rules/kernel_version", nil, &meta.Response{})
+	require.Equal(t, http.StatusOK, code)
+	code = resttest.DoRequest(t, container, http.MethodDelete, "/collector/v3/hostsnap/rules/kernel_version", nil, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,51 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/datacollection/datacollection"
+)
+
+// IngestDiscoverObjects save the objects pushed by the external discovery sources, the models,
+// the attributes and the instances are created or updated like the discover messages in redis
+func (s *Service) IngestDiscoverObjects(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	body, err := datacollection.DecodeIngestRequest(req.Request.Body)
+	if err != nil {
+		blog.Errorf("ingest discovered objects failed with decode body err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if err := body.Validate(); err != nil {
+		blog.Errorf("ingest discovered objects failed, invalid request, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, err.Error())})
+		return
+	}
+	if nil == s.discover {
+		resp.WriteError(http.StatusServiceUnavailable, &meta.RespError{Msg: defErr.Error(common.CCErrCommRelyOnServerAddressFailed)})
+		return
+	}
+
+	resp.WriteEntity(meta.NewSuccessResp(s.discover.Ingest(ownerID, body)))
+}
@@ -0,0 +1,44 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"configcenter/src/common/http/resttest"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/scene_server/datacollection/datacollection"
+)
+
+func TestIngestDiscoverObjects(t *testing.T) {
+	s := &Service{Engine: resttest.NewEngine()}
+	container := resttest.NewContainer(s.WebService())
+
+	invalid := datacollection.IngestRequest{Source: "netscan"}
+	code := resttest.DoRequest(t, container, http.MethodPost, "/collector/v3/discover/ingest", invalid, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	valid := datacollection.IngestRequest{
+		Source: "netscan",
+		DryRun: true,
+		Objects: []datacollection.IngestObject{{
+			Model: datacollection.Model{BkClassificationID: "network", BkObjID: "switch", Keys: "sn"},
+			Insts: []datacollection.M{{"sn": "10000001"}},
+		}},
+	}
+	code = resttest.DoRequest(t, container, http.MethodPost, "/collector/v3/discover/ingest", valid, &meta.Response{})
+	assert.Equal(t, http.StatusServiceUnavailable, code, "the discover is not started")
+}
@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/metric"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
+	"configcenter/src/scene_server/datacollection/datacollection"
 	"configcenter/src/storage"
 
 	"github.com/emicklei/go-restful"
@@ -27,14 +28,19 @@ import (
 
 type Service struct {
 	*backbone.Engine
-	db    storage.DI
-	cache *redis.Client
+	db       storage.DI
+	cache    *redis.Client
+	discover *datacollection.Discover
 }
 
 func (s *Service) SetDB(db storage.DI) {
 	s.db = db
 }
 
+func (s *Service) SetDiscover(discover *datacollection.Discover) {
+	s.discover = discover
+}
+
 func (s *Service) SetCache(db *redis.Client) {
 	s.cache = db
 }
@@ -47,6 +53,12 @@ func (s *Service) WebService() *restful.WebService {
 	ws.Path("/collector/v3").Filter(rdapi.AllGlobalFilter(getErrFun)).Produces(restful.MIME_JSON).Consumes(restful.MIME_JSON)
 	ws.Route(ws.GET("/healthz").To(s.Healthz))
 
+	ws.Route(ws.GET("/hostsnap/rules").To(s.SearchHostSnapRules))
+	ws.Route(ws.PUT("/hostsnap/rules/{bk_property_id}").To(s.SaveHostSnapRule))
+	ws.Route(ws.DELETE("/hostsnap/rules/{bk_property_id}").To(s.DeleteHostSnapRule))
+	ws.Route(ws.POST("/hostsnap/history/{bk_host_id}").To(s.SearchHostSnapHistory))
+	ws.Route(ws.POST("/discover/ingest").To(s.IngestDiscoverObjects))
+
 	return ws
 }
 
@@ -13,6 +13,8 @@
 package options
 
 import (
+	"time"
+
 	"configcenter/src/common/core/cc/config"
 	"configcenter/src/storage/mgoclient"
 	"configcenter/src/storage/redisclient"
@@ -45,4 +47,9 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 type Config struct {
 	MongoDB mgoclient.MongoConfig
 	Redis   redisclient.RedisConfig
+	// HistoryRetention how long the distributed events are kept for pulling, not persisted when it is 0
+	HistoryRetention time.Duration
 }
+
+// DefaultHistoryRetention the default retention of the distributed events
+const DefaultHistoryRetention = 7 * 24 * time.Hour
@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -116,7 +117,7 @@ func Run(ctx context.Context, op *options.ServerOption) error {
 		}()
 
 		go func() {
-			errCh <- distribution.Start(cache, db)
+			errCh <- distribution.Start(cache, db, process.Config.HistoryRetention)
 		}()
 		break
 	}
@@ -159,6 +160,16 @@ func (h *EventServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 		h.Config.Redis.Password = current.ConfigMap["redis.pwd"]
 		h.Config.Redis.Database = current.ConfigMap["redis.database"]
 		h.Config.Redis.Port = current.ConfigMap["redis.port"]
+
+		h.Config.HistoryRetention = options.DefaultHistoryRetention
+		if retention, ok := current.ConfigMap["event.historyRetention"]; ok {
+			hours, err := strconv.ParseInt(retention, 10, 64)
+			if err != nil || hours < 0 {
+				blog.Errorf("invalid event.historyRetention %s, use default", retention)
+			} else {
+				h.Config.HistoryRetention = time.Duration(hours) * time.Hour
+			}
+		}
 	}
 }
 
@@ -0,0 +1,73 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+// batchPollInterval how often the queue is polled while a batch is waiting for more dists
+const batchPollInterval = 100 * time.Millisecond
+
+// popDistBatch returns the dists to send in one callback, the batch is returned when it
+// is full or the flush interval passed since its first dist was popped
+func (dh *DistHandler) popDistBatch(sub *metadata.Subscription) []*metadata.DistInstCtx {
+	first := dh.popDistInst(sub.SubscriptionID)
+	if first == nil {
+		return nil
+	}
+	dists := []*metadata.DistInstCtx{first}
+	if !sub.IsBatch() {
+		return dists
+	}
+
+	queue := types.EventCacheDistQueuePrefix + fmt.Sprint(sub.SubscriptionID)
+	deadline := time.Now().Add(sub.GetFlushInterval())
+	for len(dists) < sub.GetBatchSize() {
+		raw, err := dh.cache.LPop(queue).Result()
+		if err == nil {
+			if dist := parseDistInst(raw); dist != nil {
+				dists = append(dists, dist)
+			}
+			continue
+		}
+
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			break
+		}
+		if remaining > batchPollInterval {
+			remaining = batchPollInterval
+		}
+		time.Sleep(remaining)
+	}
+	return dists
+}
+
+// packDists returns the body to deliver, the subscription sending in batch always
+// receives a json array even if there is only one dist in the batch
+func packDists(sub *metadata.Subscription, dists []*metadata.DistInstCtx) string {
+	if !sub.IsBatch() && len(dists) == 1 {
+		return dists[0].Raw
+	}
+	raws := make([]string, 0, len(dists))
+	for _, dist := range dists {
+		raws = append(raws, dist.Raw)
+	}
+	return "[" + strings.Join(raws, ",") + "]"
+}
@@ -14,11 +14,15 @@ package distribution
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	redis "gopkg.in/redis.v5"
@@ -30,14 +34,23 @@ import (
 )
 
 func (dh *DistHandler) SendCallback(receiver *metadata.Subscription, event string) (err error) {
+	_, err = sendCallback(receiver, event)
 	increaseTotal(dh.cache, receiver.SubscriptionID)
+	if err != nil {
+		increaseFailue(dh.cache, receiver.SubscriptionID)
+	}
+	return err
+}
 
+// sendCallback post the event to the receiver, the response is returned even if the callback is not confirmed,
+// the delivery is counted by the caller
+func sendCallback(receiver *metadata.Subscription, event string) (result SinkResponse, err error) {
 	body := bytes.NewBufferString(event)
 	req, err := http.NewRequest("POST", receiver.CallbackURL, body)
 	if err != nil {
-		increaseFailue(dh.cache, receiver.SubscriptionID)
-		return fmt.Errorf("event distribute fail, build request error: %v, date=[%s]", err, event)
+		return result, fmt.Errorf("event distribute fail, build request error: %v, date=[%s]", err, event)
 	}
+	signCallback(req, receiver, event, time.Now())
 	var duration time.Duration
 	if receiver.TimeOut == 0 {
 		duration = timeout
@@ -46,30 +59,28 @@ func (dh *DistHandler) SendCallback(receiver *metadata.Subscription, event strin
 	}
 	resp, err := httpCli.DoWithTimeout(duration, req)
 	if err != nil {
-		increaseFailue(dh.cache, receiver.SubscriptionID)
-		return fmt.Errorf("event distribute fail, send request error: %v, date=[%s]", err, event)
+		return result, fmt.Errorf("event distribute fail, send request error: %v, date=[%s]", err, event)
 	}
 	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
 	respdata, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		increaseFailue(dh.cache, receiver.SubscriptionID)
-		return fmt.Errorf("event distribute fail, read response error: %v, date=[%s]", err, event)
+		return result, fmt.Errorf("event distribute fail, read response error: %v, date=[%s]", err, event)
 	}
+	result.Body = respdata
 	if receiver.ConfirmMode == metadata.ConfirmmodeHttpstatus {
 		if strconv.Itoa(resp.StatusCode) != receiver.ConfirmPattern {
-			increaseFailue(dh.cache, receiver.SubscriptionID)
-			return fmt.Errorf("event distribute fail, received response %s, date=[%s]", respdata, event)
+			return result, fmt.Errorf("event distribute fail, received response %s, date=[%s]", respdata, event)
 		}
 	} else if receiver.ConfirmMode == metadata.ConfirmmodeRegular {
 		pattern, err := regexp.Compile(receiver.ConfirmPattern)
 		if err != nil {
-			return fmt.Errorf("event distribute fail, build regexp error: %v", err)
+			return result, fmt.Errorf("event distribute fail, build regexp error: %v", err)
 		}
 		if !pattern.Match(respdata) {
-			increaseFailue(dh.cache, receiver.SubscriptionID)
-			return fmt.Errorf("event distribute fail, received response %s, date=[%s]", respdata, event)
+			return result, fmt.Errorf("event distribute fail, received response %s, date=[%s]", respdata, event)
 		}
-		return nil
+		return result, nil
 	}
 
 	return
@@ -77,6 +88,27 @@ func (dh *DistHandler) SendCallback(receiver *metadata.Subscription, event strin
 
 var httpCli = httpclient.NewHttpClient()
 
+// signCallback set the timestamp and signature headers when the subscription has secrets
+func signCallback(req *http.Request, receiver *metadata.Subscription, event string, now time.Time) {
+	secrets := receiver.GetSigningSecrets(now)
+	if len(secrets) <= 0 {
+		return
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, metadata.EventCallbackSignaturePrefix+signature(secret, timestamp, event))
+	}
+	req.Header.Set(metadata.EventCallbackTimestampHeader, timestamp)
+	req.Header.Set(metadata.EventCallbackSignatureHeader, strings.Join(signatures, ","))
+}
+
+func signature(secret, timestamp, event string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + event))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func increaseTotal(cache *redis.Client, subscriptionID int64) error {
 	return increase(cache, subscriptionID, "total")
 }
@@ -16,43 +16,34 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
-	"configcenter/src/common/core/cc/api"
-	"configcenter/src/scene_server/event_server/types"
-	"configcenter/src/storage"
-)
+	redis "gopkg.in/redis.v5"
 
-func initTester() {
-	config := map[string]string{
-		storage.DI_REDIS + ".host":     "127.0.0.1",
-		storage.DI_REDIS + ".port":     "6379",
-		storage.DI_REDIS + ".usr":      "cc",
-		storage.DI_REDIS + ".pwd":      "cc",
-		storage.DI_REDIS + ".database": "0",
+	"configcenter/src/common/metadata"
+)
 
-		storage.DI_MONGO + ".host":     "127.0.0.1",
-		storage.DI_MONGO + ".port":     "27017",
-		storage.DI_MONGO + ".usr":      "cc",
-		storage.DI_MONGO + ".pwd":      "cc",
-		storage.DI_MONGO + ".database": "0",
+// initTester returns the cache of the local redis, the test is skipped when the redis is not available
+func initTester(t *testing.T) *redis.Client {
+	cache := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379", DialTimeout: time.Second})
+	if err := cache.Ping().Err(); err != nil {
+		t.Skipf("redis is not available: %v", err)
 	}
-	a := api.NewAPIResource()
-	a.GetDataCli(config, storage.DI_REDIS)
-	a.GetDataCli(config, storage.DI_MONGO)
+	return cache
 }
 
 func TestSendCallback(t *testing.T) {
-	initTester()
 	f := func(http.ResponseWriter, *http.Request) {}
 	s := httptest.NewServer(http.HandlerFunc(f))
 	defer s.Close()
-	var receiver = &types.Subscription{
+	var receiver = &metadata.Subscription{
 		CallbackURL:    s.URL,
-		ConfirmMode:    types.ConfirmmodeHttpstatus,
+		ConfirmMode:    metadata.ConfirmmodeHttpstatus,
 		ConfirmPattern: "200",
 		TimeOut:        10,
 	}
-	if err := SendCallback(receiver, "test message"); err != nil {
+	dh := &DistHandler{cache: testSinkCache()}
+	if err := dh.SendCallback(receiver, "test message"); err != nil {
 		t.Fail()
 	}
 }
@@ -0,0 +1,74 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	commontypes "configcenter/src/common/types"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+// recordDelivery keep the delivery attempt in the bounded record list and latency list of the subscription
+func (dh *DistHandler) recordDelivery(sub *metadata.Subscription, dists []*metadata.DistInstCtx, attempt int, start time.Time, result SinkResponse, sendErr error) {
+	duration := time.Since(start).Nanoseconds() / int64(time.Millisecond)
+	record := newDeliveryRecord(dists, attempt, duration, result, sendErr)
+	out, err := json.Marshal(record)
+	if err != nil {
+		blog.Errorf("marshal delivery record of subscription %d failed: %v", sub.SubscriptionID, err)
+		return
+	}
+
+	subID := fmt.Sprint(sub.SubscriptionID)
+	pipe := dh.cache.Pipeline()
+	defer pipe.Close()
+	pipe.LPush(types.EventCacheDistDeliveryPrefix+subID, string(out))
+	pipe.LTrim(types.EventCacheDistDeliveryPrefix+subID, 0, types.EventDeliveryMaxLength-1)
+	pipe.LPush(types.EventCacheDistLatencyPrefix+subID, duration)
+	pipe.LTrim(types.EventCacheDistLatencyPrefix+subID, 0, types.EventDeliveryMaxLength-1)
+	if _, err := pipe.Exec(); err != nil {
+		blog.Errorf("save delivery record of subscription %d failed: %v", sub.SubscriptionID, err)
+	}
+}
+
+// newDeliveryRecord build the delivery record of one attempt, the response and error are truncated
+func newDeliveryRecord(dists []*metadata.DistInstCtx, attempt int, duration int64, result SinkResponse, sendErr error) metadata.DeliveryRecord {
+	record := metadata.DeliveryRecord{
+		DistributionID: dists[0].DstbID,
+		Attempt:        attempt,
+		Status:         metadata.DeliveryStatusSuccess,
+		HTTPStatus:     result.StatusCode,
+		Duration:       duration,
+		Response:       truncateResponse(result.Body),
+		DeliveryTime:   commontypes.Now(),
+	}
+	for _, dist := range dists {
+		record.EventIDs = append(record.EventIDs, dist.ID)
+	}
+	if sendErr != nil {
+		record.Status = metadata.DeliveryStatusFailure
+		record.Error = truncateResponse([]byte(sendErr.Error()))
+	}
+	return record
+}
+
+func truncateResponse(body []byte) string {
+	if len(body) > types.EventDeliveryResponseMaxLength {
+		return string(body[:types.EventDeliveryResponseMaxLength])
+	}
+	return string(body)
+}
@@ -0,0 +1,82 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+func testDeliveryDists() []*metadata.DistInstCtx {
+	dists := []*metadata.DistInstCtx{}
+	for _, id := range []int64{11, 12} {
+		dist := &metadata.DistInstCtx{}
+		dist.ID = id
+		dist.DstbID = 3
+		dists = append(dists, dist)
+	}
+	return dists
+}
+
+func TestNewDeliveryRecordSuccess(t *testing.T) {
+	result := SinkResponse{StatusCode: http.StatusOK, Body: []byte("ok")}
+	record := newDeliveryRecord(testDeliveryDists(), 1, 25, result, nil)
+
+	assert.Equal(t, int64(3), record.DistributionID)
+	assert.Equal(t, 1, record.Attempt)
+	assert.Equal(t, metadata.DeliveryStatusSuccess, record.Status)
+	assert.Equal(t, http.StatusOK, record.HTTPStatus)
+	assert.Equal(t, int64(25), record.Duration)
+	assert.Equal(t, "ok", record.Response)
+	assert.Equal(t, []int64{11, 12}, record.EventIDs)
+	assert.Empty(t, record.Error)
+}
+
+func TestNewDeliveryRecordTruncate(t *testing.T) {
+	long := strings.Repeat("x", types.EventDeliveryResponseMaxLength+100)
+	result := SinkResponse{StatusCode: http.StatusInternalServerError, Body: []byte(long)}
+	record := newDeliveryRecord(testDeliveryDists(), 2, 25, result, errors.New(long))
+
+	assert.Equal(t, metadata.DeliveryStatusFailure, record.Status)
+	assert.Equal(t, http.StatusInternalServerError, record.HTTPStatus)
+	assert.Len(t, record.Response, types.EventDeliveryResponseMaxLength)
+	assert.Len(t, record.Error, types.EventDeliveryResponseMaxLength)
+}
+
+func TestNewDeliveryRecordEventIDs(t *testing.T) {
+	// the dists are queued and popped as the event handler and the distributer do
+	dists := []*metadata.DistInstCtx{}
+	for _, id := range []int64{21, 22} {
+		event := &metadata.EventInst{ID: id, EventType: metadata.EventTypeInstData, ObjType: "host", Action: metadata.EventActionCreate}
+		for _, dist := range (&EventHandler{}).GetDistInst(event) {
+			dist.DstbID = 3
+			out, err := json.Marshal(dist)
+			require.NoError(t, err)
+			queued := parseDistInst(string(out))
+			require.NotNil(t, queued)
+			dists = append(dists, queued)
+		}
+	}
+
+	record := newDeliveryRecord(dists, 1, 25, SinkResponse{StatusCode: http.StatusOK}, nil)
+	assert.Equal(t, []int64{21, 22}, record.EventIDs)
+}
@@ -157,28 +157,37 @@ func (dh *DistHandler) distToSubscribe(param metadata.Subscription, chNew chan m
 			}
 			if count <= 0 {
 				ticker.Stop()
+				dh.cache.Del(types.EventCacheDistRetryPrefix + fmt.Sprint(sub.SubscriptionID))
 				return
 			}
 		case <-done:
 			return
 		default:
-			dist := dh.popDistInst(sub.SubscriptionID)
-			if dist == nil {
+			if dists, attempt := dh.popDueRetry(sub.SubscriptionID); len(dists) > 0 {
+				if retryErr := dh.deliver(&sub, dists, attempt); retryErr != nil {
+					blog.Errorf("error retry dist: %v, %v", retryErr, dists[0])
+				}
+				continue
+			}
+			dists := dh.popDistBatch(&sub)
+			if len(dists) <= 0 {
 				continue
 			}
-			if err = dh.handleDist(&sub, dist); err != nil {
-				blog.Errorf("error handle dist: %v, %v", err, dist)
+			if err = dh.handleDists(&sub, dists); err != nil {
+				blog.Errorf("error handle dist: %v, %v", err, dists[0])
 			}
 		}
 	}
 }
 
-func (dh *DistHandler) handleDist(sub *metadata.Subscription, dist *metadata.DistInstCtx) (err error) {
-	blog.Infof("handling dist %s", dist.Raw)
+// handleDists send the dists in order, the dists is a single one or a batch of the subscription
+func (dh *DistHandler) handleDists(sub *metadata.Subscription, dists []*metadata.DistInstCtx) (err error) {
+	dist := dists[0]
+	blog.Infof("handling dist %s, batch size %d", dist.Raw, len(dists))
 	distID := fmt.Sprint(dist.DstbID - 1)
 	subscriberID := fmt.Sprint(dist.SubscriptionID)
 	runningkey := types.EventCacheDistRunningPrefix + subscriberID + "_" + distID
-	if err = saveRunning(dh.cache, runningkey, timeout+sub.GetTimeout()); err != nil {
+	if err = saveRunning(dh.cache, runningkey, runningTimeout(sub)); err != nil {
 		if ErrProcessExists == err {
 			blog.Infof("process exist, continue")
 			return nil
@@ -219,14 +228,16 @@ func (dh *DistHandler) handleDist(sub *metadata.Subscription, dist *metadata.Dis
 	}
 
 	defer func() {
-		if err = dh.saveDistDone(dist); err != nil {
-			return
+		for _, item := range dists {
+			if err = dh.saveDistDone(item); err != nil {
+				return
+			}
+			blog.Info("done event dist : %v", item.DstbID)
 		}
-		blog.Info("done event dist : %v", dist.DstbID)
 	}()
 
-	if err = dh.SendCallback(sub, dist.Raw); err != nil {
-		blog.Errorf("send callback error: %v", err)
+	if err = dh.sendWithRetry(sub, dists); err != nil {
+		blog.Errorf("send dist error: %v", err)
 		return
 	}
 
@@ -240,14 +251,17 @@ func (dh *DistHandler) popDistInst(subID int64) *metadata.DistInstCtx {
 		return nil
 	}
 
-	eventbytes := []byte(eventslice[1])
+	return parseDistInst(eventslice[1])
+}
+
+func parseDistInst(raw string) *metadata.DistInstCtx {
 	event := metadata.DistInst{}
-	if err := json.Unmarshal(eventbytes, &event); err != nil {
-		blog.Errorf("event distribute fail, unmarshal error: %v, date=[%s]", err, eventbytes)
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		blog.Errorf("event distribute fail, unmarshal error: %v, date=[%s]", err, raw)
 		return nil
 	}
 
-	return &metadata.DistInstCtx{DistInst: event, Raw: eventslice[1]}
+	return &metadata.DistInstCtx{DistInst: event, Raw: raw}
 }
 
 func (dh *DistHandler) saveDistDone(dist *metadata.DistInstCtx) (err error) {
@@ -20,27 +20,26 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/common/metadata"
 )
 
 func TestSaveDistDone(t *testing.T) {
-	initTester()
-	dist := &types.DistInstCtx{}
-	if err := saveDistDone(dist); err != nil {
+	dh := &DistHandler{cache: initTester(t)}
+	dist := &metadata.DistInstCtx{}
+	if err := dh.saveDistDone(dist); err != nil {
 		t.Fatalf("%v", err)
 	}
 }
 
 func TestHandleDist(t *testing.T) {
-	initTester()
-	dist := &types.DistInst{}
+	dh := &DistHandler{cache: initTester(t)}
+	dist := &metadata.DistInst{}
 	dist.SubscriptionID = 1
 	dist.DstbID = 1
 	dist.EventType = "create"
 	dist.Action = "create"
 	dist.ObjType = "animal"
-	dist.CurData = map[string]string{"name": "dog"}
-	dist.PreData = map[string]string{"name": "cat"}
+	dist.Data = []metadata.EventData{{CurData: map[string]string{"name": "dog"}, PreData: map[string]string{"name": "cat"}}}
 	dist.RequestID = "1"
 
 	expect, err := json.Marshal(dist)
@@ -58,22 +57,21 @@ func TestHandleDist(t *testing.T) {
 		}
 	}
 	s := httptest.NewServer(http.HandlerFunc(f))
-	s.Start()
 	defer s.Close()
-	sub := &types.Subscription{
+	sub := &metadata.Subscription{
 		SubscriptionID:   1,
 		SubscriptionName: "testsubscription",
 		SystemName:       "testsystem",
 		CallbackURL:      s.URL,
-		ConfirmMode:      types.ConfirmmodeHttpstatus,
+		ConfirmMode:      metadata.ConfirmmodeHttpstatus,
 		ConfirmPattern:   "200",
 		TimeOut:          10,
 		SubscriptionForm: "hostadd",
 	}
-	distCtx := &types.DistInstCtx{}
+	distCtx := &metadata.DistInstCtx{}
 	distCtx.DistInst = *dist
 	distCtx.Raw = string(expect)
-	if err := handleDist(sub, distCtx); err != nil {
+	if err := dh.handleDists(sub, []*metadata.DistInstCtx{distCtx}); err != nil {
 		t.Fatalf("%v", err)
 	}
 }
@@ -0,0 +1,291 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+// distFilter evaluate the subscription filters against one dist
+type distFilter struct {
+	eh     *EventHandler
+	dist   *metadata.DistInst
+	bizIDs []int64
+	// bizLoaded whether bizIDs was resolved, the biz of host events need a db query
+	bizLoaded bool
+}
+
+// hostBizCacheTTL how long the business of a host is cached for the filters
+const hostBizCacheTTL = time.Minute
+
+// hostBizCache caches the business of the hosts, so the host events do not query the db every time.
+// The host is dropped when its relation event is handled, a nil cache caches nothing
+type hostBizCache struct {
+	lock  sync.Mutex
+	ttl   time.Duration
+	items map[int64]hostBizItem
+}
+
+type hostBizItem struct {
+	bizIDs []int64
+	expire time.Time
+}
+
+func newHostBizCache(ttl time.Duration) *hostBizCache {
+	return &hostBizCache{ttl: ttl, items: map[int64]hostBizItem{}}
+}
+
+// get returns the cached business of the hosts, and the hosts not cached
+func (c *hostBizCache) get(hostIDs []int64) (bizIDs []int64, missing []int64) {
+	if c == nil {
+		return nil, hostIDs
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	for _, hostID := range hostIDs {
+		item, ok := c.items[hostID]
+		if !ok || now.After(item.expire) {
+			missing = append(missing, hostID)
+			continue
+		}
+		bizIDs = append(bizIDs, item.bizIDs...)
+	}
+	return bizIDs, missing
+}
+
+func (c *hostBizCache) set(hostBizIDs map[int64][]int64) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	for hostID, item := range c.items {
+		if now.After(item.expire) {
+			delete(c.items, hostID)
+		}
+	}
+	for hostID, bizIDs := range hostBizIDs {
+		c.items[hostID] = hostBizItem{bizIDs: bizIDs, expire: now.Add(c.ttl)}
+	}
+}
+
+func (c *hostBizCache) drop(hostIDs []int64) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, hostID := range hostIDs {
+		delete(c.items, hostID)
+	}
+}
+
+// forgetHostBiz drop the cached business of the hosts whose relation is changed by the dist
+func (eh *EventHandler) forgetHostBiz(dist *metadata.DistInst) {
+	if dist.EventType != metadata.EventTypeRelation {
+		return
+	}
+	hostIDs := []int64{}
+	for _, item := range dist.Data {
+		for _, raw := range []interface{}{item.PreData, item.CurData} {
+			data, err := mapstr.NewFromInterface(raw)
+			if err != nil || data == nil {
+				continue
+			}
+			if hostID, err := util.GetInt64ByInterface(data[common.BKHostIDField]); err == nil {
+				hostIDs = append(hostIDs, hostID)
+			}
+		}
+	}
+	eh.hostBiz.drop(hostIDs)
+}
+
+// getSubscriberFilters returns the filters of the subscribers, the subscribers without filter are not included
+func (eh *EventHandler) getSubscriberFilters(subscribers []string) map[string]*metadata.SubscriptionFilter {
+	filters := map[string]*metadata.SubscriptionFilter{}
+	if len(subscribers) <= 0 {
+		return filters
+	}
+	vals, err := eh.cache.HMGet(types.EventCacheSubscriptionFilterKey, subscribers...).Result()
+	if err != nil {
+		blog.Errorf("get subscription filters failed: %v", err)
+		return filters
+	}
+	for index, val := range vals {
+		str, ok := val.(string)
+		if !ok || str == "" {
+			continue
+		}
+		filter := metadata.SubscriptionFilter{}
+		if err := json.Unmarshal([]byte(str), &filter); err != nil {
+			blog.Errorf("unmarshal filter of subscription %s failed: %v, data=[%s]", subscribers[index], err, str)
+			continue
+		}
+		filters[subscribers[index]] = &filter
+	}
+	return filters
+}
+
+func newDistFilter(eh *EventHandler, dist *metadata.DistInst) *distFilter {
+	return &distFilter{eh: eh, dist: dist}
+}
+
+// Match returns whether the dist should be sent to the subscriber with the filter
+func (f *distFilter) Match(filter *metadata.SubscriptionFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if len(filter.ObjIDs) > 0 && !f.matchObjIDs(filter.ObjIDs) {
+		return false
+	}
+	if len(filter.ChangedFields) > 0 && !f.matchChangedFields(filter.ChangedFields) {
+		return false
+	}
+	if len(filter.Condition) > 0 && !f.matchCondition(filter.Condition) {
+		return false
+	}
+	if len(filter.BizIDs) > 0 && !f.matchBizIDs(filter.BizIDs) {
+		return false
+	}
+	return true
+}
+
+func (f *distFilter) matchObjIDs(objIDs []string) bool {
+	if util.InStrArr(objIDs, f.dist.ObjType) {
+		return true
+	}
+	for _, data := range f.eventData() {
+		if objID, ok := data[common.BKObjIDField].(string); ok && util.InStrArr(objIDs, objID) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchChangedFields only the update events are checked, the others always match
+func (f *distFilter) matchChangedFields(fields []string) bool {
+	if f.dist.Action != metadata.EventActionUpdate {
+		return true
+	}
+	for _, item := range f.dist.Data {
+		pre, _ := mapstr.NewFromInterface(item.PreData)
+		cur, _ := mapstr.NewFromInterface(item.CurData)
+		for _, field := range fields {
+			if !reflect.DeepEqual(pre[field], cur[field]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *distFilter) matchCondition(cond map[string]interface{}) bool {
+	parsed := condition.CreateCondition()
+	if err := parsed.Parse(cond); err != nil {
+		blog.Errorf("parse subscription filter condition %v failed: %v", cond, err)
+		return false
+	}
+	for _, data := range f.eventData() {
+		if parsed.Match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *distFilter) matchBizIDs(bizIDs []int64) bool {
+	for _, bizID := range f.getBizIDs() {
+		if util.InArray(bizID, bizIDs) {
+			return true
+		}
+	}
+	return false
+}
+
+// getBizIDs returns the business of the event, the host's business is queried from the module host config,
+// it is loaded once for the dist and cached by the handler for the next events of the host
+func (f *distFilter) getBizIDs() []int64 {
+	if f.bizLoaded {
+		return f.bizIDs
+	}
+	f.bizLoaded = true
+
+	hostIDs := []int64{}
+	for _, data := range f.eventData() {
+		if bizID, err := util.GetInt64ByInterface(data[common.BKAppIDField]); err == nil {
+			f.bizIDs = append(f.bizIDs, bizID)
+			continue
+		}
+		if hostID, err := util.GetInt64ByInterface(data[common.BKHostIDField]); err == nil {
+			hostIDs = append(hostIDs, hostID)
+		}
+	}
+
+	cached, missing := f.eh.hostBiz.get(hostIDs)
+	f.bizIDs = append(f.bizIDs, cached...)
+	if len(missing) > 0 && f.eh.db != nil {
+		relations := []map[string]interface{}{}
+		cond := condition.CreateCondition().Field(common.BKHostIDField).In(missing).ToMapStr()
+		fields := []string{common.BKHostIDField, common.BKAppIDField}
+		if err := f.eh.db.GetMutilByCondition(common.BKTableNameModuleHostConfig, fields, cond, &relations, "", 0, 0); err != nil {
+			blog.Errorf("get business of hosts %v failed: %v", missing, err)
+			return f.bizIDs
+		}
+		hostBizIDs := make(map[int64][]int64, len(missing))
+		for _, hostID := range missing {
+			hostBizIDs[hostID] = []int64{}
+		}
+		for _, relation := range relations {
+			hostID, err := util.GetInt64ByInterface(relation[common.BKHostIDField])
+			if err != nil {
+				continue
+			}
+			if bizID, err := util.GetInt64ByInterface(relation[common.BKAppIDField]); err == nil {
+				hostBizIDs[hostID] = append(hostBizIDs[hostID], bizID)
+				f.bizIDs = append(f.bizIDs, bizID)
+			}
+		}
+		f.eh.hostBiz.set(hostBizIDs)
+	}
+	return f.bizIDs
+}
+
+// eventData returns the current data of the event, or the previous data of the delete events
+func (f *distFilter) eventData() []mapstr.MapStr {
+	datas := []mapstr.MapStr{}
+	for _, item := range f.dist.Data {
+		raw := item.CurData
+		if f.dist.Action == metadata.EventActionDelete {
+			raw = item.PreData
+		}
+		data, err := mapstr.NewFromInterface(raw)
+		if err != nil || data == nil {
+			continue
+		}
+		datas = append(datas, data)
+	}
+	return datas
+}
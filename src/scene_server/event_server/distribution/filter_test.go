@@ -0,0 +1,110 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+func testFilterDist(eventType, objType, action string, pre, cur map[string]interface{}) *metadata.DistInst {
+	dist := &metadata.DistInst{}
+	dist.EventType = eventType
+	dist.ObjType = objType
+	dist.Action = action
+	dist.Data = []metadata.EventData{{PreData: pre, CurData: cur}}
+	return dist
+}
+
+func TestDistFilterMatch(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	_, err := db.Insert(common.BKTableNameModuleHostConfig, map[string]interface{}{common.BKHostIDField: int64(1), common.BKAppIDField: int64(2)})
+	require.NoError(t, err)
+
+	hostUpdate := testFilterDist(metadata.EventTypeInstData, common.BKInnerObjIDHost, metadata.EventActionUpdate,
+		map[string]interface{}{common.BKHostIDField: 1, "bk_os_type": "1", "bk_host_name": "a"},
+		map[string]interface{}{common.BKHostIDField: 1, "bk_os_type": "1", "bk_host_name": "b"})
+	hostDelete := testFilterDist(metadata.EventTypeInstData, common.BKInnerObjIDHost, metadata.EventActionDelete,
+		map[string]interface{}{common.BKHostIDField: 1, "bk_os_type": "2"}, nil)
+	instCreate := testFilterDist(metadata.EventTypeInstData, common.BKINnerObjIDObject, metadata.EventActionCreate,
+		nil, map[string]interface{}{common.BKObjIDField: "switch", common.BKAppIDField: 3, "name": "s1"})
+
+	testCases := []struct {
+		name   string
+		dist   *metadata.DistInst
+		filter *metadata.SubscriptionFilter
+		match  bool
+	}{
+		{name: "no filter", dist: hostUpdate, filter: nil, match: true},
+		{name: "empty filter", dist: hostUpdate, filter: &metadata.SubscriptionFilter{}, match: true},
+		{name: "object type", dist: hostUpdate, filter: &metadata.SubscriptionFilter{ObjIDs: []string{common.BKInnerObjIDHost}}, match: true},
+		{name: "object of the instance", dist: instCreate, filter: &metadata.SubscriptionFilter{ObjIDs: []string{"switch"}}, match: true},
+		{name: "other object", dist: instCreate, filter: &metadata.SubscriptionFilter{ObjIDs: []string{"router"}}, match: false},
+		{name: "changed field", dist: hostUpdate, filter: &metadata.SubscriptionFilter{ChangedFields: []string{"bk_host_name"}}, match: true},
+		{name: "unchanged field", dist: hostUpdate, filter: &metadata.SubscriptionFilter{ChangedFields: []string{"bk_os_type"}}, match: false},
+		{name: "changed field of create", dist: instCreate, filter: &metadata.SubscriptionFilter{ChangedFields: []string{"bk_os_type"}}, match: true},
+		{name: "condition", dist: hostUpdate, filter: &metadata.SubscriptionFilter{Condition: map[string]interface{}{"bk_host_name": "b"}}, match: true},
+		{name: "condition of previous data", dist: hostUpdate, filter: &metadata.SubscriptionFilter{Condition: map[string]interface{}{"bk_host_name": "a"}}, match: false},
+		{name: "condition of delete", dist: hostDelete, filter: &metadata.SubscriptionFilter{Condition: map[string]interface{}{"bk_os_type": "2"}}, match: true},
+		{name: "invalid condition", dist: hostUpdate, filter: &metadata.SubscriptionFilter{Condition: map[string]interface{}{"bk_os_type": map[string]interface{}{"$unknown": 1}}}, match: false},
+		{name: "business of the instance", dist: instCreate, filter: &metadata.SubscriptionFilter{BizIDs: []int64{3}}, match: true},
+		{name: "other business of the instance", dist: instCreate, filter: &metadata.SubscriptionFilter{BizIDs: []int64{2}}, match: false},
+		{name: "business of the host", dist: hostUpdate, filter: &metadata.SubscriptionFilter{BizIDs: []int64{2}}, match: true},
+		{name: "other business of the host", dist: hostUpdate, filter: &metadata.SubscriptionFilter{BizIDs: []int64{3}}, match: false},
+		{name: "all of the filters", dist: hostUpdate, filter: &metadata.SubscriptionFilter{
+			BizIDs: []int64{2}, ObjIDs: []string{common.BKInnerObjIDHost}, ChangedFields: []string{"bk_host_name"},
+			Condition: map[string]interface{}{"bk_os_type": "1"}}, match: true},
+		{name: "any of the filters fails", dist: hostUpdate, filter: &metadata.SubscriptionFilter{
+			BizIDs: []int64{2}, ObjIDs: []string{common.BKInnerObjIDHost}, ChangedFields: []string{"bk_os_type"}}, match: false},
+	}
+	for _, tc := range testCases {
+		eh := &EventHandler{db: db}
+		assert.Equal(t, tc.match, newDistFilter(eh, tc.dist).Match(tc.filter), tc.name)
+	}
+}
+
+func TestDistFilterHostBizCache(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	relation := map[string]interface{}{common.BKHostIDField: int64(1), common.BKAppIDField: int64(2)}
+	_, err := db.Insert(common.BKTableNameModuleHostConfig, relation)
+	require.NoError(t, err)
+
+	eh := &EventHandler{db: db, hostBiz: newHostBizCache(time.Hour)}
+	filter := &metadata.SubscriptionFilter{BizIDs: []int64{2}}
+	hostUpdate := testFilterDist(metadata.EventTypeInstData, common.BKInnerObjIDHost, metadata.EventActionUpdate,
+		map[string]interface{}{common.BKHostIDField: 1}, map[string]interface{}{common.BKHostIDField: 1})
+	assert.True(t, newDistFilter(eh, hostUpdate).Match(filter))
+
+	// the next events of the host use the cached business
+	require.NoError(t, db.DelByCondition(common.BKTableNameModuleHostConfig, map[string]interface{}{common.BKHostIDField: 1}))
+	assert.True(t, newDistFilter(eh, hostUpdate).Match(filter))
+
+	// the host is queried again after its relation changed
+	eh.forgetHostBiz(testFilterDist(metadata.EventTypeRelation, "moduletransfer", metadata.EventActionDelete, relation, nil))
+	assert.False(t, newDistFilter(eh, hostUpdate).Match(filter))
+
+	// the cached business expires
+	eh.hostBiz = newHostBizCache(-time.Second)
+	_, err = db.Insert(common.BKTableNameModuleHostConfig, relation)
+	require.NoError(t, err)
+	assert.True(t, newDistFilter(eh, hostUpdate).Match(filter))
+	require.NoError(t, db.DelByCondition(common.BKTableNameModuleHostConfig, map[string]interface{}{common.BKHostIDField: 1}))
+	assert.False(t, newDistFilter(eh, hostUpdate).Match(filter))
+}
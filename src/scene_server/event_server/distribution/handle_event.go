@@ -118,14 +118,25 @@ func (eh *EventHandler) handleInst(event *metadata.EventInstCtx) (err error) {
 	origindists := eh.GetDistInst(&event.EventInst)
 
 	for _, origindist := range origindists {
+		if err = eh.saveHistory(&origindist); err != nil {
+			blog.Errorf("save event history failed: %v, raw = %s", err, event.Raw)
+			err = nil
+		}
+		eh.forgetHostBiz(&origindist)
 		subscribers := eh.findEventTypeSubscribers(origindist.GetType(), event.OwnerID)
 		if len(subscribers) <= 0 || "nil" == subscribers[0] {
 			blog.Infof("%v no subscriber，continue", origindist.GetType())
 			return eh.SaveEventDone(event)
 		}
 
+		filters := eh.getSubscriberFilters(subscribers)
+		matcher := newDistFilter(eh, &origindist)
 		for _, subscriber := range subscribers {
 			var dstbID, subscribeID int64
+			if !matcher.Match(filters[subscriber]) {
+				blog.V(3).Infof("event %v filtered out by subscription %s", event.ID, subscriber)
+				continue
+			}
 			distinst := origindist
 			dstbID, err = eh.nextDistID(subscriber)
 			if err != nil {
@@ -145,11 +156,11 @@ func (eh *EventHandler) handleInst(event *metadata.EventInstCtx) (err error) {
 	return
 }
 
+// GetDistInst returns the dists of the event, the dists keep the id of the event
 func (eh *EventHandler) GetDistInst(e *metadata.EventInst) []metadata.DistInst {
 	distinst := metadata.DistInst{
 		EventInst: *e,
 	}
-	distinst.ID = 0
 	var ds []metadata.DistInst
 	var m map[string]interface{}
 	if e.EventType == metadata.EventTypeInstData && e.ObjType == common.BKINnerObjIDObject {
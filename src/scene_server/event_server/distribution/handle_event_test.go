@@ -17,43 +17,39 @@ import (
 	"testing"
 
 	"configcenter/src/common"
+	"configcenter/src/common/metadata"
 	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/storage/memclient"
 )
 
-func TestPrepareDistInst(t *testing.T) {
-	initTester()
-	subscriber := "1"
-	event := types.EventInstCtx{}
-	event.EventType = types.EventTypeInstData
-	event.Action = types.EventActionUpdate
-	event.ObjType = "object"
-	event.CurData = map[string]string{"name": "dog", common.BKObjIDField: "animal"}
-	event.PreData = map[string]string{"name": "cat", common.BKObjIDField: "animal"}
-	event.RequestID = "1"
-
-	dist := prepareDistInst(subscriber, event)
+func TestGetDistInst(t *testing.T) {
+	eh := &EventHandler{}
+	event := testEventCtx()
 
-	if dist.ObjType != "animal" {
-		t.Fatalf("expected animal but got %s", dist.ObjType)
+	dists := eh.GetDistInst(&event.EventInst)
+	if len(dists) != 1 {
+		t.Fatalf("expected 1 dist but got %d", len(dists))
 	}
-
-	if dist.DstbID <= 0 {
-		t.Fatalf("expected dstbid > 0 but got %d", dist.DstbID)
+	if dists[0].ObjType != "animal" {
+		t.Fatalf("expected animal but got %s", dists[0].ObjType)
+	}
+	if dists[0].ID != event.ID {
+		t.Fatalf("expected event id %d but got %d", event.ID, dists[0].ID)
 	}
 }
 
 func TestPushToQueue(t *testing.T) {
-	initTester()
+	eh := &EventHandler{cache: initTester(t)}
 	key := common.BKCacheKeyV3Prefix + "event:testqueue"
 	value := `{"dist":"testdata"}`
-	if err := pushToQueue(key, value); err != nil {
+	if err := eh.pushToQueue(key, value); err != nil {
 		t.Fatalf("pushToQueue failed %v", err)
 	}
 }
 
 func TestNextDistID(t *testing.T) {
-	initTester()
-	nextID, err := nextDistID("1")
+	eh := &EventHandler{cache: initTester(t)}
+	nextID, err := eh.nextDistID("1")
 	if err != nil {
 		t.Fatalf("nextDistID failed %v", err)
 	}
@@ -63,52 +59,58 @@ func TestNextDistID(t *testing.T) {
 }
 
 func TestSaveEventDone(t *testing.T) {
-	initTester()
+	eh := &EventHandler{cache: initTester(t)}
 	eventCtx := testEventCtx()
-	err := SaveEventDone(eventCtx)
+	err := eh.SaveEventDone(eventCtx)
 	if err != nil {
 		t.Fatalf("SaveEventDone failed %v", err)
 	}
 }
 
 func TestCheckFromDone(t *testing.T) {
-	initTester()
-	_, err := checkFromDone(types.EventCacheEventDoneKey, "0")
+	cache := initTester(t)
+	_, err := checkFromDone(cache, types.EventCacheEventDoneKey, "0")
 	if err != nil {
 		t.Fatalf("checkFromDone failed %v", err)
 	}
 }
 
 func TestFindEventTypeSubscribers(t *testing.T) {
-	initTester()
-	finded := findEventTypeSubscribers("animalcreate")
+	eh := &EventHandler{cache: initTester(t)}
+	if err := eh.cache.SAdd(types.EventSubscriberCacheKey("0", "animalcreate"), "1").Err(); err != nil {
+		t.Fatalf("add subscriber failed %v", err)
+	}
+	finded := eh.findEventTypeSubscribers("animalcreate", "0")
 	if len(finded) < 1 {
 		t.Fatalf("findEventTypeSubscribers failed")
 	}
 }
 
 func TestHandleInst(t *testing.T) {
-	initTester()
+	eh := &EventHandler{cache: initTester(t), db: memclient.NewMemCli("cmdb")}
 	event := testEventCtx()
-	err := handleInst(event)
+	err := eh.handleInst(event)
 	if err != nil {
 		t.Fatalf("handleInst failed %v", err)
 	}
 }
 
-func testEventCtx() *types.EventInstCtx {
-	initTester()
-	event := &types.EventInst{}
-	event.EventType = types.EventTypeInstData
-	event.Action = types.EventActionUpdate
-	event.ObjType = "object"
-	event.CurData = map[string]string{"name": "dog", common.BKObjIDField: "animal"}
-	event.PreData = map[string]string{"name": "cat", common.BKObjIDField: "animal"}
+func testEventCtx() *metadata.EventInstCtx {
+	event := &metadata.EventInst{}
+	event.ID = 1
+	event.EventType = metadata.EventTypeInstData
+	event.Action = metadata.EventActionUpdate
+	event.ObjType = common.BKINnerObjIDObject
+	event.Data = []metadata.EventData{{
+		CurData: map[string]interface{}{"name": "dog", common.BKObjIDField: "animal"},
+		PreData: map[string]interface{}{"name": "cat", common.BKObjIDField: "animal"},
+	}}
+	event.OwnerID = "0"
 	event.RequestID = "1"
 
 	out, _ := json.Marshal(event)
 
-	eventCtx := &types.EventInstCtx{}
+	eventCtx := &metadata.EventInstCtx{}
 	eventCtx.Raw = string(out)
 	eventCtx.EventInst = *event
 	return eventCtx
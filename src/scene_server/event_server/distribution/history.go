@@ -0,0 +1,51 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	"configcenter/src/common/metadata"
+)
+
+// saveHistory persist the dist, so that the consumers could pull it later.
+// allocating the cursor and inserting the event is not atomic, the events may be inserted out of
+// the cursor order by the event servers, the pulling waits them to settle instead. an event is lost
+// for the consumers which have pulled a larger cursor only when its insert is delayed longer than that.
+func (eh *EventHandler) saveHistory(dist *metadata.DistInst) error {
+	if eh.historyRetention <= 0 {
+		return nil
+	}
+	cursor, err := eh.db.GetIncID(common.BKTableNameEventHistory)
+	if err != nil {
+		return err
+	}
+	_, err = eh.db.Insert(common.BKTableNameEventHistory, metadata.NewEventHistory(cursor, dist))
+	return err
+}
+
+// StartCleanHistory delete the event histories which are older than the retention periodically
+func (eh *EventHandler) StartCleanHistory() {
+	blog.Info("event history clean process started")
+	for {
+		expire := time.Now().UTC().Add(-eh.historyRetention)
+		cond := condition.CreateCondition().Field(common.CreateTimeField).Lt(expire).ToMapStr()
+		if err := eh.db.DelByCondition(common.BKTableNameEventHistory, cond); err != nil {
+			blog.Errorf("clean event history before %v failed: %v", expire, err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
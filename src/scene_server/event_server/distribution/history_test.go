@@ -0,0 +1,66 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+// testHistoryDist returns the dist of the event as the event handler does
+func testHistoryDist(t *testing.T, id int64, action string) *metadata.DistInst {
+	event := &metadata.EventInst{}
+	event.ID = id
+	event.EventType = metadata.EventTypeInstData
+	event.Action = action
+	event.ObjType = common.BKInnerObjIDHost
+	event.OwnerID = "0"
+	event.RequestID = "request"
+	dists := (&EventHandler{}).GetDistInst(event)
+	require.Len(t, dists, 1)
+	return &dists[0]
+}
+
+func TestSaveHistory(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+
+	// not persisted without retention
+	eh := &EventHandler{db: db}
+	require.NoError(t, eh.saveHistory(testHistoryDist(t, 1, metadata.EventActionCreate)))
+	count, err := db.GetCntByCondition(common.BKTableNameEventHistory, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	eh.historyRetention = time.Hour
+	require.NoError(t, eh.saveHistory(testHistoryDist(t, 1, metadata.EventActionCreate)))
+	require.NoError(t, eh.saveHistory(testHistoryDist(t, 2, metadata.EventActionUpdate)))
+
+	histories := []metadata.EventHistory{}
+	require.NoError(t, db.GetMutilByCondition(common.BKTableNameEventHistory, nil, nil, &histories, "cursor", 0, 0))
+	require.Len(t, histories, 2)
+	assert.True(t, histories[0].Cursor < histories[1].Cursor, "the cursor is monotonic")
+	assert.Equal(t, "hostcreate", histories[0].EventName)
+	assert.Equal(t, int64(1), histories[0].EventID)
+	assert.Equal(t, "hostupdate", histories[1].EventName)
+	assert.Equal(t, int64(2), histories[1].EventID)
+	assert.Equal(t, "0", histories[1].OwnerID)
+	assert.Equal(t, "request", histories[1].RequestID)
+	assert.False(t, histories[1].CreateTime.IsZero())
+}
@@ -13,6 +13,7 @@
 package distribution
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
@@ -36,6 +37,7 @@ type reconciler struct {
 	persisted            map[string][]string
 	cachedSubscribers    []string
 	persistedSubscribers []string
+	persistedFilters     map[string]string
 	processID            string
 }
 
@@ -46,6 +48,7 @@ func newReconciler(cache *redis.Client, db storage.DI) *reconciler {
 		cached:               map[string][]string{},
 		persisted:            map[string][]string{},
 		persistedSubscribers: []string{},
+		persistedFilters:     map[string]string{},
 	}
 }
 
@@ -68,6 +71,7 @@ func (r *reconciler) loadAllCached() {
 func (r *reconciler) loadAllPersisted() {
 	r.persisted = map[string][]string{}
 	r.persistedSubscribers = []string{}
+	r.persistedFilters = map[string]string{}
 	subscriptions := []metadata.Subscription{}
 	if err := r.db.GetMutilByCondition(common.BKTableNameSubscription, nil, nil, &subscriptions, "", 0, 0); err != nil {
 		blog.Errorf("reconcile err: %v", err)
@@ -76,6 +80,11 @@ func (r *reconciler) loadAllPersisted() {
 	for _, sub := range subscriptions {
 		eventnames := strings.Split(sub.SubscriptionForm, ",")
 		r.persistedSubscribers = append(r.persistedSubscribers, sub.GetCacheKey())
+		if sub.Filter != nil {
+			if out, err := json.Marshal(sub.Filter); err == nil {
+				r.persistedFilters[fmt.Sprint(sub.SubscriptionID)] = string(out)
+			}
+		}
 		for _, eventname := range eventnames {
 			eventname = sub.OwnerID + ":" + eventname
 			r.persisted[eventname] = append(r.persisted[eventname], fmt.Sprint(sub.SubscriptionID))
@@ -106,6 +115,30 @@ func (r *reconciler) reconcile() {
 		r.cache.Del(types.EventCacheSubscribeformKey + k)
 	}
 
+	r.reconcileFilters()
+}
+
+// reconcileFilters make the filters in cache the same as the persisted ones
+func (r *reconciler) reconcileFilters() {
+	cachedFilters, err := r.cache.HGetAll(types.EventCacheSubscriptionFilterKey).Result()
+	if err != nil {
+		blog.Errorf("reconcile filters err: %v", err)
+		return
+	}
+	for subID, filter := range r.persistedFilters {
+		if cachedFilters[subID] == filter {
+			continue
+		}
+		if err := r.cache.HSet(types.EventCacheSubscriptionFilterKey, subID, filter).Err(); err != nil {
+			blog.Errorf("reconcile filters err: %v", err)
+		}
+	}
+	for subID := range cachedFilters {
+		if _, ok := r.persistedFilters[subID]; !ok {
+			r.cache.HDel(types.EventCacheSubscriptionFilterKey, subID)
+		}
+	}
+
 }
 
 func SubscribeChannel(redisCli *redis.Client) (err error) {
@@ -14,11 +14,13 @@ package distribution
 
 import (
 	"testing"
+
+	"configcenter/src/storage/memclient"
 )
 
 func TestReconciler(t *testing.T) {
-	initTester()
-	reconcil := newReconciler()
+	cache := initTester(t)
+	reconcil := newReconciler(cache, memclient.NewMemCli("cmdb"))
 	reconcil.loadAll()
 	reconcil.reconcile()
 }
@@ -0,0 +1,165 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	redis "gopkg.in/redis.v5"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	commontypes "configcenter/src/common/types"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+// retryEntry the failed dists waiting in the retry queue of the subscription
+type retryEntry struct {
+	Attempt int      `json:"attempt"`
+	Dists   []string `json:"dists"`
+}
+
+// sendWithRetry send the dists to the subscriber by the retry policy of the subscription, the failed dists
+// are put into the retry queue to be sent again after the backoff, so that the backoff never blocks the
+// dists behind them, they are moved into the dead letter list as a whole when all the attempts failed
+func (dh *DistHandler) sendWithRetry(sub *metadata.Subscription, dists []*metadata.DistInstCtx) error {
+	return dh.deliver(sub, dists, 1)
+}
+
+// deliver make the attempt to send the dists, the delivery is counted once when it succeeds or the last attempt fails
+func (dh *DistHandler) deliver(sub *metadata.Subscription, dists []*metadata.DistInstCtx, attempt int) error {
+	sink, err := dh.getSink(sub)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	result, err := sink.Send(sub, dists)
+	dh.recordDelivery(sub, dists, attempt, start, result, err)
+	if err == nil {
+		increaseTotal(dh.cache, sub.SubscriptionID)
+		return nil
+	}
+
+	maxAttempts := sub.RetryPolicy.GetMaxAttempts()
+	blog.Warnf("send dist to subscription %d failed at attempt %d/%d: %v", sub.SubscriptionID, attempt, maxAttempts, err)
+	if attempt < maxAttempts {
+		due := time.Now().Add(withJitter(sub.RetryPolicy.GetBackoff(attempt), sub.RetryPolicy))
+		retryErr := dh.pushRetry(sub.SubscriptionID, dists, attempt+1, due)
+		if retryErr == nil {
+			return err
+		}
+		blog.Errorf("push dist %d of subscription %d to retry queue failed: %v", dists[0].DstbID, sub.SubscriptionID, retryErr)
+	}
+
+	increaseTotal(dh.cache, sub.SubscriptionID)
+	increaseFailue(dh.cache, sub.SubscriptionID)
+	if dlErr := dh.pushDeadLetter(dists, attempt, err); dlErr != nil {
+		blog.Errorf("push dist %d of subscription %d to dead letter failed: %v", dists[0].DstbID, sub.SubscriptionID, dlErr)
+	}
+	return err
+}
+
+// pushRetry put the dists into the retry queue of the subscription, the queue is sorted by the due time
+func (dh *DistHandler) pushRetry(subID int64, dists []*metadata.DistInstCtx, attempt int, due time.Time) error {
+	entry := retryEntry{Attempt: attempt}
+	for _, dist := range dists {
+		entry.Dists = append(entry.Dists, dist.Raw)
+	}
+	out, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	score := float64(due.UnixNano() / int64(time.Millisecond))
+	return dh.cache.ZAdd(types.EventCacheDistRetryPrefix+fmt.Sprint(subID), redis.Z{Score: score, Member: string(out)}).Err()
+}
+
+// popDueRetry returns the failed dists whose backoff passed and their attempt, the entry is removed
+// from the retry queue before returned, so that only one of the event servers retries it
+func (dh *DistHandler) popDueRetry(subID int64) ([]*metadata.DistInstCtx, int) {
+	key := types.EventCacheDistRetryPrefix + fmt.Sprint(subID)
+	now := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	members, err := dh.cache.ZRangeByScore(key, redis.ZRangeBy{Min: "-inf", Max: now, Count: 1}).Result()
+	if err != nil || len(members) <= 0 {
+		return nil, 0
+	}
+	if removed, err := dh.cache.ZRem(key, members[0]).Result(); err != nil || removed <= 0 {
+		return nil, 0
+	}
+
+	entry := retryEntry{}
+	if err := json.Unmarshal([]byte(members[0]), &entry); err != nil {
+		blog.Errorf("retry dist of subscription %d fail, unmarshal error: %v, date=[%s]", subID, err, members[0])
+		return nil, 0
+	}
+	dists := make([]*metadata.DistInstCtx, 0, len(entry.Dists))
+	for _, raw := range entry.Dists {
+		if dist := parseDistInst(raw); dist != nil {
+			dists = append(dists, dist)
+		}
+	}
+	if len(dists) <= 0 {
+		return nil, 0
+	}
+	return dists, entry.Attempt
+}
+
+func (dh *DistHandler) pushDeadLetter(dists []*metadata.DistInstCtx, attempts int, sendErr error) error {
+	letter := metadata.DeadLetter{
+		DistInst:   dists[0].DistInst,
+		Attempts:   attempts,
+		FailedTime: commontypes.Now(),
+	}
+	if len(dists) > 1 {
+		for _, dist := range dists {
+			letter.Batch = append(letter.Batch, dist.DistInst)
+		}
+	}
+	if sendErr != nil {
+		letter.Error = sendErr.Error()
+	}
+	out, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	key := types.EventCacheDistDeadLetterPrefix + fmt.Sprint(dists[0].SubscriptionID)
+	if err = dh.cache.RPush(key, string(out)).Err(); err != nil {
+		return err
+	}
+	// drop the oldest dead letters when the list is full
+	return dh.cache.LTrim(key, -types.EventDeadLetterMaxLength, -1).Err()
+}
+
+// runningTimeout returns how long a dist of the subscription may keep running, the retries are
+// not included since they are sent from the retry queue
+func runningTimeout(sub *metadata.Subscription) time.Duration {
+	return timeout + sub.GetTimeout()
+}
+
+func withJitter(backoff time.Duration, policy *metadata.RetryPolicy) time.Duration {
+	if policy == nil || policy.Jitter <= 0 || backoff <= 0 {
+		return backoff
+	}
+	jitter := policy.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	// spread the backoff into [backoff*(1-jitter), backoff*(1+jitter))
+	delta := float64(backoff) * jitter
+	return backoff + time.Duration(delta*(2*rand.Float64()-1))
+}
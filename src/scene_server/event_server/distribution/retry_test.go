@@ -0,0 +1,81 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+func TestDeliverRetry(t *testing.T) {
+	recv := &receiver{status: http.StatusInternalServerError, body: "busy"}
+	receiverServer := httptest.NewServer(recv)
+	defer receiverServer.Close()
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	dh := &DistHandler{cache: server.client()}
+	sub := &metadata.Subscription{
+		SubscriptionID: 1,
+		CallbackURL:    receiverServer.URL,
+		ConfirmMode:    metadata.ConfirmmodeHttpstatus,
+		ConfirmPattern: "200",
+		RetryPolicy:    &metadata.RetryPolicy{MaxAttempts: 2, InitialBackoff: 60000},
+	}
+	dists := testSinkDists(`{"event_id":1}`)
+
+	// the failed attempt is put into the retry queue without waiting for the backoff, and is not counted yet
+	start := time.Now()
+	assert.Error(t, dh.deliver(sub, dists, 1))
+	assert.True(t, time.Since(start) < time.Minute)
+	assert.Empty(t, server.find("HINCRBY"))
+	queued := server.find("ZADD")
+	require.Len(t, queued, 1)
+	assert.Equal(t, types.EventCacheDistRetryPrefix+"1", queued[0][1])
+	due, err := strconv.ParseInt(queued[0][2], 10, 64)
+	require.NoError(t, err)
+	assert.True(t, due >= start.Add(time.Minute).UnixNano()/int64(time.Millisecond))
+	entry := retryEntry{}
+	require.NoError(t, json.Unmarshal([]byte(queued[0][3]), &entry))
+	assert.Equal(t, retryEntry{Attempt: 2, Dists: []string{`{"event_id":1}`}}, entry)
+
+	// the last attempt is counted once as a failure and moved into the dead letter list
+	assert.Error(t, dh.deliver(sub, dists, 2))
+	assert.Len(t, server.find("ZADD"), 1)
+	counted := server.find("HINCRBY")
+	require.Len(t, counted, 2)
+	assert.Equal(t, "total", counted[0][2])
+	assert.Equal(t, "failue", counted[1][2])
+	letters := server.find("RPUSH")
+	require.Len(t, letters, 1)
+	letter := metadata.DeadLetter{}
+	require.NoError(t, json.Unmarshal([]byte(letters[0][2]), &letter))
+	assert.Equal(t, 2, letter.Attempts)
+
+	// the success is counted once
+	recv.status = http.StatusOK
+	assert.NoError(t, dh.deliver(sub, dists, 1))
+	counted = server.find("HINCRBY")
+	require.Len(t, counted, 3)
+	assert.Equal(t, "total", counted[2][2])
+}
@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"fmt"
+	"sync"
+
+	redis "gopkg.in/redis.v5"
+
+	"configcenter/src/common/metadata"
+)
+
+// Sink deliver the dists to the subscriber, one sink for each delivery type,
+// the dists are sent as one json array when the subscription sends in batch
+type Sink interface {
+	Send(sub *metadata.Subscription, dists []*metadata.DistInstCtx) (SinkResponse, error)
+}
+
+// SinkResponse the response of the receiver, it is empty when the sink has no response
+type SinkResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// SinkCreator create the sink with the event cache
+type SinkCreator func(cache *redis.Client) Sink
+
+var sinkCreators = map[string]SinkCreator{}
+
+var sinkLock sync.Mutex
+
+// RegisterSink register the sink of the delivery type
+func RegisterSink(deliveryType string, creator SinkCreator) {
+	sinkLock.Lock()
+	defer sinkLock.Unlock()
+	sinkCreators[deliveryType] = creator
+}
+
+// IsSupportedDeliveryType returns whether there is a sink for the delivery type
+func IsSupportedDeliveryType(deliveryType string) bool {
+	sinkLock.Lock()
+	defer sinkLock.Unlock()
+	_, ok := sinkCreators[deliveryType]
+	return ok
+}
+
+// getSink returns the sink of the subscription's delivery type
+func (dh *DistHandler) getSink(sub *metadata.Subscription) (Sink, error) {
+	deliveryType := sub.GetDeliveryType()
+
+	sinkLock.Lock()
+	defer sinkLock.Unlock()
+	if sink, ok := dh.sinks[deliveryType]; ok {
+		return sink, nil
+	}
+	creator, ok := sinkCreators[deliveryType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported delivery type %s", deliveryType)
+	}
+	if dh.sinks == nil {
+		dh.sinks = map[string]Sink{}
+	}
+	sink := creator(dh.cache)
+	dh.sinks[deliveryType] = sink
+	return sink, nil
+}
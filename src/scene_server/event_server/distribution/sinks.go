@@ -0,0 +1,66 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"fmt"
+
+	redis "gopkg.in/redis.v5"
+
+	"configcenter/src/common/metadata"
+)
+
+func init() {
+	RegisterSink(metadata.DeliveryTypeHTTP, newHTTPSink)
+	RegisterSink(metadata.DeliveryTypeRedisStream, newRedisStreamSink)
+}
+
+// httpSink post the dist to the callback url of the subscription
+type httpSink struct{}
+
+func newHTTPSink(cache *redis.Client) Sink {
+	return &httpSink{}
+}
+
+func (s *httpSink) Send(sub *metadata.Subscription, dists []*metadata.DistInstCtx) (SinkResponse, error) {
+	return sendCallback(sub, packDists(sub, dists))
+}
+
+// redisStreamMaxLen the approximate max length of the stream, the oldest events are dropped when exceeded
+const redisStreamMaxLen = 100000
+
+// redisStreamSink add the dist to the redis stream named by the subscription's delivery target,
+// the consumers could read the stream with consumer groups
+type redisStreamSink struct {
+	cache *redis.Client
+}
+
+func newRedisStreamSink(cache *redis.Client) Sink {
+	return &redisStreamSink{cache: cache}
+}
+
+func (s *redisStreamSink) Send(sub *metadata.Subscription, dists []*metadata.DistInstCtx) (SinkResponse, error) {
+	event := packDists(sub, dists)
+	if sub.DeliveryTarget == "" {
+		return SinkResponse{}, fmt.Errorf("event distribute fail, the stream name is empty, date=[%s]", event)
+	}
+
+	cmd := redis.NewStringCmd("XADD", sub.DeliveryTarget, "MAXLEN", "~", redisStreamMaxLen, "*",
+		"subscription_id", sub.SubscriptionID,
+		"distribution_id", dists[0].DstbID,
+		"event", event)
+	if err := s.cache.Process(cmd); err != nil {
+		return SinkResponse{}, fmt.Errorf("event distribute fail, add to stream %s error: %v, date=[%s]", sub.DeliveryTarget, err, event)
+	}
+	return SinkResponse{}, nil
+}
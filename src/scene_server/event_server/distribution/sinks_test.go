@@ -0,0 +1,285 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redis "gopkg.in/redis.v5"
+
+	"configcenter/src/common/metadata"
+)
+
+// testSinkCache returns the cache which could not be connected, the sinks only count the deliveries in it
+func testSinkCache() *redis.Client {
+	return redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 10 * time.Millisecond})
+}
+
+// fakeRedis serve the redis protocol for the sinks, it records the commands and replies 1 to all of them,
+// except a stream id to XADD
+type fakeRedis struct {
+	listener net.Listener
+	lock     sync.Mutex
+	commands [][]string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	r := &fakeRedis{listener: listener}
+	go r.serve()
+	return r
+}
+
+func (r *fakeRedis) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(conn)
+	}
+}
+
+func (r *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		command, err := readRedisCommand(reader)
+		if err != nil {
+			return
+		}
+		r.lock.Lock()
+		r.commands = append(r.commands, command)
+		r.lock.Unlock()
+		if strings.EqualFold(command[0], "XADD") {
+			conn.Write([]byte("$3\r\n1-0\r\n"))
+		} else {
+			conn.Write([]byte(":1\r\n"))
+		}
+	}
+}
+
+// readRedisCommand read the command sent as an array of bulk strings
+func readRedisCommand(reader *bufio.Reader) ([]string, error) {
+	count, err := readRedisLength(reader, '*')
+	if err != nil {
+		return nil, err
+	}
+	command := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		size, err := readRedisLength(reader, '$')
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		command = append(command, string(arg[:size]))
+	}
+	return command, nil
+}
+
+func readRedisLength(reader *bufio.Reader, prefix byte) (int, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	line = strings.TrimSpace(line)
+	if len(line) <= 1 || line[0] != prefix {
+		return 0, fmt.Errorf("unexpected line %s", line)
+	}
+	return strconv.Atoi(line[1:])
+}
+
+func (r *fakeRedis) client() *redis.Client {
+	return redis.NewClient(&redis.Options{Addr: r.listener.Addr().String()})
+}
+
+// find returns the received commands of the name
+func (r *fakeRedis) find(name string) [][]string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	found := [][]string{}
+	for _, command := range r.commands {
+		if strings.EqualFold(command[0], name) {
+			found = append(found, command)
+		}
+	}
+	return found
+}
+
+func (r *fakeRedis) Close() {
+	r.listener.Close()
+}
+
+func testSinkDists(raws ...string) []*metadata.DistInstCtx {
+	dists := []*metadata.DistInstCtx{}
+	for idx, raw := range raws {
+		dist := &metadata.DistInstCtx{Raw: raw}
+		dist.ID = int64(idx + 1)
+		dist.DstbID = 1
+		dists = append(dists, dist)
+	}
+	return dists
+}
+
+// receiver record the last request and reply with the status and body
+type receiver struct {
+	status int
+	body   string
+	req    *http.Request
+	data   string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data, _ := ioutil.ReadAll(req.Body)
+	r.req = req
+	r.data = string(data)
+	w.WriteHeader(r.status)
+	w.Write([]byte(r.body))
+}
+
+func TestHTTPSinkSend(t *testing.T) {
+	recv := &receiver{status: http.StatusOK, body: "ok"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	sink := newHTTPSink(testSinkCache())
+	sub := &metadata.Subscription{SubscriptionID: 1, CallbackURL: server.URL, ConfirmMode: metadata.ConfirmmodeHttpstatus, ConfirmPattern: "200", Secret: "secret"}
+	result, err := sink.Send(sub, testSinkDists(`{"event_id":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "ok", string(result.Body))
+
+	require.NotNil(t, recv.req)
+	assert.Equal(t, http.MethodPost, recv.req.Method)
+	assert.Equal(t, `{"event_id":1}`, recv.data)
+	timestamp := recv.req.Header.Get(metadata.EventCallbackTimestampHeader)
+	require.NotEmpty(t, timestamp)
+	assert.Equal(t, metadata.EventCallbackSignaturePrefix+signature("secret", timestamp, recv.data), recv.req.Header.Get(metadata.EventCallbackSignatureHeader))
+
+	// the batch is sent as one json array
+	sub.BatchSize = 10
+	_, err = sink.Send(sub, testSinkDists(`{"event_id":1}`, `{"event_id":2}`))
+	require.NoError(t, err)
+	assert.Equal(t, `[{"event_id":1},{"event_id":2}]`, recv.data)
+}
+
+func TestHTTPSinkConfirm(t *testing.T) {
+	recv := &receiver{status: http.StatusInternalServerError, body: "busy"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	sink := newHTTPSink(testSinkCache())
+	dists := testSinkDists(`{"event_id":1}`)
+
+	// the response is returned with the error when the status is not confirmed
+	sub := &metadata.Subscription{SubscriptionID: 1, CallbackURL: server.URL, ConfirmMode: metadata.ConfirmmodeHttpstatus, ConfirmPattern: "200"}
+	result, err := sink.Send(sub, dists)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	assert.Equal(t, "busy", string(result.Body))
+
+	recv.status = http.StatusOK
+	sub = &metadata.Subscription{SubscriptionID: 1, CallbackURL: server.URL, ConfirmMode: metadata.ConfirmmodeRegular, ConfirmPattern: "^ok$"}
+	_, err = sink.Send(sub, dists)
+	assert.Error(t, err, "the body does not match the pattern")
+
+	recv.body = "ok"
+	_, err = sink.Send(sub, dists)
+	assert.NoError(t, err)
+
+	sub.ConfirmPattern = "(["
+	_, err = sink.Send(sub, dists)
+	assert.Error(t, err, "the pattern is invalid")
+}
+
+func TestHTTPSinkUnreachable(t *testing.T) {
+	server := httptest.NewServer(&receiver{status: http.StatusOK})
+	url := server.URL
+	server.Close()
+
+	sink := newHTTPSink(testSinkCache())
+	sub := &metadata.Subscription{SubscriptionID: 1, CallbackURL: url, TimeOut: 1}
+	result, err := sink.Send(sub, testSinkDists(`{"event_id":1}`))
+	assert.Error(t, err)
+	assert.Equal(t, 0, result.StatusCode)
+
+	sub.CallbackURL = "://invalid"
+	_, err = sink.Send(sub, testSinkDists(`{"event_id":1}`))
+	assert.Error(t, err)
+}
+
+func TestRedisStreamSinkSend(t *testing.T) {
+	sink := newRedisStreamSink(testSinkCache())
+	sub := &metadata.Subscription{SubscriptionID: 1, DeliveryType: metadata.DeliveryTypeRedisStream}
+	_, err := sink.Send(sub, testSinkDists(`{"event_id":1}`))
+	assert.Error(t, err, "the stream name is empty")
+
+	sub.DeliveryTarget = "cc_events"
+	_, err = sink.Send(sub, testSinkDists(`{"event_id":1}`))
+	assert.Error(t, err, "the cache is unreachable")
+}
+
+func TestRedisStreamSinkSendSuccess(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	sink := newRedisStreamSink(server.client())
+	sub := &metadata.Subscription{SubscriptionID: 1, DeliveryType: metadata.DeliveryTypeRedisStream, DeliveryTarget: "cc_events", BatchSize: 10}
+	result, err := sink.Send(sub, testSinkDists(`{"event_id":1}`, `{"event_id":2}`))
+	require.NoError(t, err)
+	assert.Equal(t, SinkResponse{}, result)
+
+	added := server.find("XADD")
+	require.Len(t, added, 1)
+	assert.Equal(t, []string{"XADD", "cc_events", "MAXLEN", "~", "100000", "*",
+		"subscription_id", "1",
+		"distribution_id", "1",
+		"event", `[{"event_id":1},{"event_id":2}]`}, added[0])
+
+	// the delivery is counted by the retry, not the sink
+	assert.Empty(t, server.find("HINCRBY"))
+}
+
+func TestGetSink(t *testing.T) {
+	assert.True(t, IsSupportedDeliveryType(metadata.DeliveryTypeHTTP))
+	assert.True(t, IsSupportedDeliveryType(metadata.DeliveryTypeRedisStream))
+	assert.False(t, IsSupportedDeliveryType("kafka"))
+
+	dh := &DistHandler{cache: testSinkCache()}
+	sink, err := dh.getSink(&metadata.Subscription{})
+	require.NoError(t, err)
+	assert.IsType(t, &httpSink{}, sink)
+	again, err := dh.getSink(&metadata.Subscription{DeliveryType: metadata.DeliveryTypeHTTP})
+	require.NoError(t, err)
+	assert.True(t, sink == again, "the sink is created once for each delivery type")
+
+	_, err = dh.getSink(&metadata.Subscription{DeliveryType: "kafka"})
+	assert.Error(t, err)
+}
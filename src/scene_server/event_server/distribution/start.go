@@ -13,19 +13,24 @@
 package distribution
 
 import (
+	"time"
+
 	redis "gopkg.in/redis.v5"
 
 	"configcenter/src/scene_server/event_server/identifier"
 	"configcenter/src/storage"
 )
 
-func Start(cache *redis.Client, db storage.DI) error {
+func Start(cache *redis.Client, db storage.DI, historyRetention time.Duration) error {
 	chErr := make(chan error)
 
-	eh := &EventHandler{cache: cache}
+	eh := &EventHandler{cache: cache, db: db, historyRetention: historyRetention, hostBiz: newHostBizCache(hostBizCacheTTL)}
 	go func() {
 		chErr <- eh.StartHandleInsts()
 	}()
+	if historyRetention > 0 {
+		go eh.StartCleanHistory()
+	}
 
 	dh := &DistHandler{cache: cache, db: db}
 	go func() {
@@ -40,8 +45,16 @@ func Start(cache *redis.Client, db storage.DI) error {
 	return <-chErr
 }
 
-type EventHandler struct{ cache *redis.Client }
+type EventHandler struct {
+	cache            *redis.Client
+	db               storage.DI
+	historyRetention time.Duration
+	// hostBiz the business of the hosts used by the filters
+	hostBiz *hostBizCache
+}
 type DistHandler struct {
 	cache *redis.Client
 	db    storage.DI
+	// sinks the sinks of every delivery type, created when first used
+	sinks map[string]Sink
 }
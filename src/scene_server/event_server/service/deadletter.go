@@ -0,0 +1,193 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+// SearchDeadLetter list the events of the subscription which failed after all the retries
+func (s *Service) SearchDeadLetter(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	id, err := s.getOwnedSubscriptionID(req, ownerID)
+	if err != nil {
+		blog.Errorf("search dead letter, but get subscription failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+
+	var dat metadata.ParamDeadLetterSearch
+	if err := json.NewDecoder(req.Request.Body).Decode(&dat); err != nil {
+		blog.Errorf("search dead letter, but decode body failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	key := types.EventCacheDistDeadLetterPrefix + fmt.Sprint(id)
+	count, err := s.cache.LLen(key).Result()
+	if err != nil {
+		blog.Errorf("search dead letter of subscription %d failed, err: %v", id, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+
+	start := int64(dat.Page.Start)
+	stop := int64(-1)
+	if dat.Page.Limit > 0 && dat.Page.Limit != common.BKNoLimit {
+		stop = start + int64(dat.Page.Limit) - 1
+	}
+	values, err := s.cache.LRange(key, start, stop).Result()
+	if err != nil {
+		blog.Errorf("search dead letter of subscription %d failed, err: %v", id, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterSelectFailed)})
+		return
+	}
+
+	letters := []metadata.DeadLetter{}
+	for _, value := range values {
+		letter := metadata.DeadLetter{}
+		if err := json.Unmarshal([]byte(value), &letter); err != nil {
+			blog.Errorf("unmarshal dead letter failed, err: %v, data=[%s]", err, value)
+			continue
+		}
+		letters = append(letters, letter)
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.RspDeadLetterSearch{Count: count, Info: letters}))
+}
+
+// ReplayDeadLetter push the dead letters back to the distribute queue of the subscription
+func (s *Service) ReplayDeadLetter(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	id, err := s.getOwnedSubscriptionID(req, ownerID)
+	if err != nil {
+		blog.Errorf("replay dead letter, but get subscription failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterReplayFailed)})
+		return
+	}
+
+	var dat metadata.ParamDeadLetterReplay
+	if err := json.NewDecoder(req.Request.Body).Decode(&dat); err != nil {
+		blog.Errorf("replay dead letter, but decode body failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	replayIDs := map[int64]bool{}
+	for _, distID := range dat.DistIDs {
+		replayIDs[distID] = true
+	}
+
+	subID := fmt.Sprint(id)
+	key := types.EventCacheDistDeadLetterPrefix + subID
+	values, err := s.cache.LRange(key, 0, -1).Result()
+	if err != nil {
+		blog.Errorf("replay dead letter of subscription %d failed, err: %v", id, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterReplayFailed)})
+		return
+	}
+
+	var replayed int64
+	for _, value := range values {
+		letter := metadata.DeadLetter{}
+		if err := json.Unmarshal([]byte(value), &letter); err != nil {
+			blog.Errorf("unmarshal dead letter failed, err: %v, data=[%s]", err, value)
+			continue
+		}
+		if len(replayIDs) > 0 && !replayIDs[letter.DstbID] {
+			continue
+		}
+
+		// a failed batch is replayed as a whole
+		dists := letter.Batch
+		if len(dists) <= 0 {
+			dists = []metadata.DistInst{letter.DistInst}
+		}
+		for _, dist := range dists {
+			// the old distribution id was marked done already, so take a new one to keep the order
+			dist.DstbID, err = s.cache.Incr(types.EventCacheDistIDPrefix + subID).Result()
+			if err != nil {
+				blog.Errorf("replay dead letter of subscription %d failed, err: %v", id, err)
+				resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterReplayFailed)})
+				return
+			}
+			out, _ := json.Marshal(dist)
+			if err := s.cache.RPush(types.EventCacheDistQueuePrefix+subID, string(out)).Err(); err != nil {
+				blog.Errorf("replay dead letter of subscription %d failed, err: %v", id, err)
+				resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterReplayFailed)})
+				return
+			}
+		}
+		if err := s.cache.LRem(key, 1, value).Err(); err != nil {
+			blog.Errorf("remove replayed dead letter of subscription %d failed, err: %v", id, err)
+		}
+		replayed++
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.RspDeadLetterReplay{Count: replayed}))
+}
+
+// PurgeDeadLetter drop all the dead letters of the subscription
+func (s *Service) PurgeDeadLetter(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	id, err := s.getOwnedSubscriptionID(req, ownerID)
+	if err != nil {
+		blog.Errorf("purge dead letter, but get subscription failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterPurgeFailed)})
+		return
+	}
+
+	if err := s.cache.Del(types.EventCacheDistDeadLetterPrefix + fmt.Sprint(id)).Err(); err != nil {
+		blog.Errorf("purge dead letter of subscription %d failed, err: %v", id, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeadLetterPurgeFailed)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
+
+// getOwnedSubscriptionID returns the subscribeID in path, and make sure it belongs to the owner
+func (s *Service) getOwnedSubscriptionID(req *restful.Request, ownerID string) (int64, error) {
+	id, err := strconv.ParseInt(req.PathParameter("subscribeID"), 10, 64)
+	if nil != err {
+		return 0, err
+	}
+	condiction := util.NewMapBuilder(common.BKSubscriptionIDField, id, common.BKOwnerIDField, ownerID).Build()
+	count, err := s.db.GetCntByCondition(common.BKTableNameSubscription, condiction)
+	if err != nil {
+		return 0, err
+	}
+	if count <= 0 {
+		return 0, fmt.Errorf("subscription %d not found", id)
+	}
+	return id, nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+const defaultDeliveryLimit = 20
+
+// SearchDeliveries list the recent delivery attempts of the subscription, the newest first
+func (s *Service) SearchDeliveries(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	id, err := s.getOwnedSubscriptionID(req, ownerID)
+	if err != nil {
+		blog.Errorf("search deliveries, but get subscription failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeliverySelectFailed)})
+		return
+	}
+
+	var start int64
+	if param := req.QueryParameter("start"); param != "" {
+		start, err = strconv.ParseInt(param, 10, 64)
+		if err != nil || start < 0 {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "start")})
+			return
+		}
+	}
+	limit := int64(defaultDeliveryLimit)
+	if param := req.QueryParameter("limit"); param != "" {
+		limit, err = strconv.ParseInt(param, 10, 64)
+		if err != nil || limit <= 0 {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "limit")})
+			return
+		}
+	}
+
+	key := types.EventCacheDistDeliveryPrefix + fmt.Sprint(id)
+	count, err := s.cache.LLen(key).Result()
+	if err != nil {
+		blog.Errorf("search deliveries of subscription %d failed, err: %v", id, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeliverySelectFailed)})
+		return
+	}
+	values, err := s.cache.LRange(key, start, start+limit-1).Result()
+	if err != nil {
+		blog.Errorf("search deliveries of subscription %d failed, err: %v", id, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventDeliverySelectFailed)})
+		return
+	}
+
+	records := []metadata.DeliveryRecord{}
+	for _, value := range values {
+		record := metadata.DeliveryRecord{}
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			blog.Errorf("unmarshal delivery record failed, err: %v, data=[%s]", err, value)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.RspDeliverySearch{Count: count, Info: records}))
+}
+
+// getLatencyPercentiles returns the p50 and p95 latency of the recent deliveries of the subscription
+func (s *Service) getLatencyPercentiles(subscriptionID int64) (p50, p95 int64) {
+	values, err := s.cache.LRange(types.EventCacheDistLatencyPrefix+fmt.Sprint(subscriptionID), 0, -1).Result()
+	if err != nil {
+		blog.Errorf("get latencies of subscription %d failed, err: %v", subscriptionID, err)
+		return 0, 0
+	}
+	return latencyPercentiles(values)
+}
+
+// latencyPercentiles returns the p50 and p95 of the latency values, the invalid values are ignored
+func latencyPercentiles(values []string) (p50, p95 int64) {
+	latencies := make([]int64, 0, len(values))
+	for _, value := range values {
+		latency, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		latencies = append(latencies, latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return percentile(latencies, 50), percentile(latencies, 95)
+}
+
+// percentile returns the nearest rank percentile of the sorted values
+func percentile(sorted []int64, p int) int64 {
+	if len(sorted) <= 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
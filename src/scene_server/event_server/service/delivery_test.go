@@ -0,0 +1,49 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, int64(0), percentile(nil, 50))
+	assert.Equal(t, int64(7), percentile([]int64{7}, 50))
+	assert.Equal(t, int64(7), percentile([]int64{7}, 95))
+
+	sorted := make([]int64, 0, 100)
+	for i := int64(1); i <= 100; i++ {
+		sorted = append(sorted, i)
+	}
+	assert.Equal(t, int64(50), percentile(sorted, 50))
+	assert.Equal(t, int64(95), percentile(sorted, 95))
+	assert.Equal(t, int64(1), percentile(sorted, 0))
+	assert.Equal(t, int64(100), percentile(sorted, 100))
+
+	// nearest rank rounds up
+	assert.Equal(t, int64(20), percentile([]int64{10, 20, 30}, 50))
+	assert.Equal(t, int64(30), percentile([]int64{10, 20, 30}, 95))
+}
+
+func TestLatencyPercentiles(t *testing.T) {
+	p50, p95 := latencyPercentiles(nil)
+	assert.Equal(t, int64(0), p50)
+	assert.Equal(t, int64(0), p95)
+
+	// unsorted values and the invalid one is ignored
+	p50, p95 = latencyPercentiles([]string{"30", "invalid", "10", "20"})
+	assert.Equal(t, int64(20), p50)
+	assert.Equal(t, int64(30), p95)
+}
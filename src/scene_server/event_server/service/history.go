@@ -0,0 +1,96 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+const (
+	defaultPullLimit = 200
+	maxPullLimit     = 1000
+
+	// pullSettleDelay the events created in the delay are not pulled yet. the cursor is allocated before
+	// the event is inserted, so an event with a smaller cursor may still be inserting by another event server,
+	// the consumer would skip it if it pulled the larger cursor first.
+	pullSettleDelay = 5 * time.Second
+)
+
+// PullEvents returns the persisted events after the cursor, the consumer should pull again
+// with the cursor in the response, so that it could resume from where it stopped.
+// the events are returned after they have settled for pullSettleDelay, see saveHistory
+func (s *Service) PullEvents(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	var cursor int64
+	var err error
+	if param := req.QueryParameter("cursor"); param != "" {
+		cursor, err = strconv.ParseInt(param, 10, 64)
+		if err != nil || cursor < 0 {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "cursor")})
+			return
+		}
+	}
+
+	limit := defaultPullLimit
+	if param := req.QueryParameter("limit"); param != "" {
+		limit, err = strconv.Atoi(param)
+		if err != nil || limit <= 0 {
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "limit")})
+			return
+		}
+		if limit > maxPullLimit {
+			limit = maxPullLimit
+		}
+	}
+
+	cond := condition.CreateCondition()
+	cond.Field("cursor").Gt(cursor)
+	cond.Field(common.BKOwnerIDField).Eq(ownerID)
+	cond.Field(common.CreateTimeField).Lt(time.Now().UTC().Add(-pullSettleDelay))
+	if param := req.QueryParameter("types"); param != "" {
+		eventNames := []string{}
+		for _, name := range strings.Split(param, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				eventNames = append(eventNames, name)
+			}
+		}
+		cond.Field("event_name").In(eventNames)
+	}
+
+	histories := []metadata.EventHistory{}
+	if err := s.db.GetMutilByCondition(common.BKTableNameEventHistory, nil, cond.ToMapStr(), &histories, "cursor", 0, limit); err != nil {
+		blog.Errorf("pull events after cursor %d failed, err: %v", cursor, err)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventHistorySelectFailed)})
+		return
+	}
+
+	result := metadata.RspEventPull{Cursor: cursor, Info: histories}
+	if len(histories) > 0 {
+		result.Cursor = histories[len(histories)-1].Cursor
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
@@ -0,0 +1,84 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/http/resttest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+type pullResult struct {
+	metadata.BaseResp
+	Data metadata.RspEventPull `json:"data"`
+}
+
+func TestPullEvents(t *testing.T) {
+	db := memclient.NewMemCli("cmdb")
+	settled := time.Now().UTC().Add(-time.Minute)
+	histories := []metadata.EventHistory{
+		{Cursor: 1, EventName: "hostcreate", OwnerID: "0", CreateTime: settled},
+		{Cursor: 2, EventName: "hostupdate", OwnerID: "0", CreateTime: settled},
+		{Cursor: 3, EventName: "hostupdate", OwnerID: "1", CreateTime: settled},
+		{Cursor: 4, EventName: "hostdelete", OwnerID: "0", CreateTime: settled},
+		// not settled, an event with a smaller cursor may be still inserting
+		{Cursor: 5, EventName: "hostupdate", OwnerID: "0", CreateTime: time.Now().UTC()},
+	}
+	for _, history := range histories {
+		_, err := db.Insert(common.BKTableNameEventHistory, history)
+		require.NoError(t, err)
+	}
+	s := &Service{Engine: resttest.NewEngine()}
+	s.SetDB(db)
+	container := resttest.NewContainer(s.WebService())
+
+	result := pullResult{}
+	code := resttest.DoRequest(t, container, http.MethodGet, "/event/v3/events?limit=2", nil, &result)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, result.Data.Info, 2)
+	assert.Equal(t, int64(1), result.Data.Info[0].Cursor)
+	assert.Equal(t, int64(2), result.Data.Cursor)
+
+	// resume from the cursor, the events of the other supplier and the unsettled ones are not returned
+	result = pullResult{}
+	code = resttest.DoRequest(t, container, http.MethodGet, "/event/v3/events?cursor=2", nil, &result)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, result.Data.Info, 1)
+	assert.Equal(t, int64(4), result.Data.Cursor)
+
+	result = pullResult{}
+	code = resttest.DoRequest(t, container, http.MethodGet, "/event/v3/events?cursor=4", nil, &result)
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, result.Data.Info)
+	assert.Equal(t, int64(4), result.Data.Cursor, "the cursor is kept when there are no more events")
+
+	result = pullResult{}
+	code = resttest.DoRequest(t, container, http.MethodGet, "/event/v3/events?types=hostupdate,hostdelete", nil, &result)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, result.Data.Info, 2)
+	assert.Equal(t, "hostupdate", result.Data.Info[0].EventName)
+	assert.Equal(t, "hostdelete", result.Data.Info[1].EventName)
+
+	code = resttest.DoRequest(t, container, http.MethodGet, "/event/v3/events?cursor=-1", nil, &metadata.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code = resttest.DoRequest(t, container, http.MethodGet, "/event/v3/events?limit=0", nil, &metadata.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -53,6 +53,11 @@ func (s *Service) WebService() *restful.WebService {
 	ws.Route(ws.POST("/subscribe/{ownerID}/{appID}").To(s.Subscribe))
 	ws.Route(ws.DELETE("/subscribe/{ownerID}/{appID}/{subscribeID}").To(s.UnSubscribe))
 	ws.Route(ws.PUT("/subscribe/{ownerID}/{appID}/{subscribeID}").To(s.Rebook))
+	ws.Route(ws.POST("/subscribe/{ownerID}/{appID}/{subscribeID}/deadletter/search").To(s.SearchDeadLetter))
+	ws.Route(ws.POST("/subscribe/{ownerID}/{appID}/{subscribeID}/deadletter/replay").To(s.ReplayDeadLetter))
+	ws.Route(ws.DELETE("/subscribe/{ownerID}/{appID}/{subscribeID}/deadletter").To(s.PurgeDeadLetter))
+	ws.Route(ws.GET("/subscribe/{ownerID}/{appID}/{subscribeID}/deliveries").To(s.SearchDeliveries))
+	ws.Route(ws.GET("/events").To(s.PullEvents))
 
 	ws.Route(ws.GET("/healthz").To(s.Healthz))
 
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/emicklei/go-restful"
 
@@ -30,6 +31,7 @@ import (
 	"configcenter/src/common/metadata"
 	commontypes "configcenter/src/common/types"
 	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/distribution"
 	"configcenter/src/scene_server/event_server/types"
 )
 
@@ -46,6 +48,11 @@ func (s *Service) Subscribe(req *restful.Request, resp *restful.Response) {
 		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
 		return
 	}
+	if field, ok := validateDelivery(sub); !ok {
+		blog.Errorf("add subscription, but %s is invalid, delivery type: %s", field, sub.DeliveryType)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, field)})
+		return
+	}
 	now := commontypes.Now()
 	sub.Operator = util.GetUser(req.Request.Header)
 	if sub.TimeOut <= 0 {
@@ -56,6 +63,11 @@ func (s *Service) Subscribe(req *restful.Request, resp *restful.Response) {
 	}
 	sub.LastTime = &now
 	sub.OwnerID = ownerID
+	if sub.SecretGracePeriod <= 0 {
+		sub.SecretGracePeriod = metadata.DefaultSecretGracePeriod
+	}
+	sub.PreviousSecret = ""
+	sub.PreviousSecretExpire = nil
 
 	sub.SubscriptionForm = strings.Replace(sub.SubscriptionForm, " ", "", 0)
 	events := strings.Split(sub.SubscriptionForm, ",")
@@ -96,6 +108,12 @@ func (s *Service) Subscribe(req *restful.Request, resp *restful.Response) {
 			}
 		}
 
+		if err := s.saveFilterCache(sub); err != nil {
+			blog.Errorf("create subscription failed, save filter error:%s", err.Error())
+			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrEventSubscribeInsertFailed)})
+			return
+		}
+
 		mesg, _ := json.Marshal(&sub)
 		s.cache.Publish(types.EventCacheProcessChannel, "create"+string(mesg))
 		s.cache.Del(types.EventCacheDistCallBackCountPrefix + fmt.Sprint(sub.SubscriptionID))
@@ -149,7 +167,11 @@ func (s *Service) UnSubscribe(req *restful.Request, resp *restful.Response) {
 
 	s.cache.Del(types.EventCacheDistIDPrefix+subID,
 		types.EventCacheDistQueuePrefix+subID,
-		types.EventCacheDistDonePrefix+subID)
+		types.EventCacheDistDonePrefix+subID,
+		types.EventCacheDistDeadLetterPrefix+subID,
+		types.EventCacheDistDeliveryPrefix+subID,
+		types.EventCacheDistLatencyPrefix+subID)
+	s.cache.HDel(types.EventCacheSubscriptionFilterKey, subID)
 
 	mesg, _ := json.Marshal(&sub)
 	s.cache.Publish(types.EventCacheProcessChannel, "delete"+string(mesg))
@@ -175,6 +197,11 @@ func (s *Service) Rebook(req *restful.Request, resp *restful.Response) {
 		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
 		return
 	}
+	if field, ok := validateDelivery(sub); !ok {
+		blog.Errorf("update subscription, but %s is invalid, delivery type: %s", field, sub.DeliveryType)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, field)})
+		return
+	}
 	sub.Operator = util.GetUser(req.Request.Header)
 	if err = s.rebook(id, ownerID, sub); err != nil {
 		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrEventSubscribeUpdateFailed)})
@@ -210,6 +237,7 @@ func (s *Service) rebook(id int64, ownerID string, sub *metadata.Subscription) e
 	now := commontypes.Now()
 	sub.LastTime = &now
 	sub.OwnerID = ownerID
+	rotateSecret(&oldsub, sub, now.Time)
 
 	sub.SubscriptionForm = strings.Replace(sub.SubscriptionForm, " ", "", 0)
 	events := strings.Split(sub.SubscriptionForm, ",")
@@ -240,6 +268,11 @@ func (s *Service) rebook(id int64, ownerID string, sub *metadata.Subscription) e
 		}
 	}
 
+	if err := s.saveFilterCache(sub); err != nil {
+		blog.Errorf("update subscription filter failed, error:%s", err.Error())
+		return err
+	}
+
 	mesg, err := json.Marshal(&sub)
 	if err != nil {
 		return err
@@ -247,6 +280,73 @@ func (s *Service) rebook(id int64, ownerID string, sub *metadata.Subscription) e
 	return s.cache.Publish(types.EventCacheProcessChannel, "update"+string(mesg)).Err()
 }
 
+// validateDelivery returns the invalid field when the delivery settings are wrong
+func validateDelivery(sub *metadata.Subscription) (string, bool) {
+	if !distribution.IsSupportedDeliveryType(sub.GetDeliveryType()) {
+		return "delivery_type", false
+	}
+	if sub.GetDeliveryType() == metadata.DeliveryTypeRedisStream && sub.DeliveryTarget == "" {
+		return "delivery_target", false
+	}
+	if sub.BatchSize < 0 {
+		return "batch_size", false
+	}
+	if sub.FlushInterval < 0 {
+		return "flush_interval", false
+	}
+	if sub.ClearSecret && sub.Secret != "" {
+		return "clear_secret", false
+	}
+	return sub.RetryPolicy.Validate()
+}
+
+// saveFilterCache saves the filter into cache, so that the events could be filtered before distributed
+func (s *Service) saveFilterCache(sub *metadata.Subscription) error {
+	subID := fmt.Sprint(sub.SubscriptionID)
+	if sub.Filter == nil {
+		return s.cache.HDel(types.EventCacheSubscriptionFilterKey, subID).Err()
+	}
+	out, err := json.Marshal(sub.Filter)
+	if err != nil {
+		return err
+	}
+	return s.cache.HSet(types.EventCacheSubscriptionFilterKey, subID, string(out)).Err()
+}
+
+// rotateSecret keeps the old secret valid in the grace period when the secret changed,
+// keeps the current secret when the new one is empty, and removes all of them when cleared
+func rotateSecret(oldsub, sub *metadata.Subscription, now time.Time) {
+	if sub.SecretGracePeriod <= 0 {
+		sub.SecretGracePeriod = oldsub.SecretGracePeriod
+	}
+	if sub.SecretGracePeriod <= 0 {
+		sub.SecretGracePeriod = metadata.DefaultSecretGracePeriod
+	}
+
+	if sub.ClearSecret {
+		sub.Secret = ""
+		sub.PreviousSecret = ""
+		sub.PreviousSecretExpire = nil
+		return
+	}
+
+	if sub.Secret == "" || sub.Secret == oldsub.Secret {
+		sub.Secret = oldsub.Secret
+		sub.PreviousSecret = oldsub.PreviousSecret
+		sub.PreviousSecretExpire = oldsub.PreviousSecretExpire
+		return
+	}
+
+	if oldsub.Secret == "" {
+		sub.PreviousSecret = ""
+		sub.PreviousSecretExpire = nil
+		return
+	}
+	expire := commontypes.Time{Time: now.Add(time.Duration(sub.SecretGracePeriod) * time.Second)}
+	sub.PreviousSecret = oldsub.Secret
+	sub.PreviousSecretExpire = &expire
+}
+
 func (s *Service) Query(req *restful.Request, resp *restful.Response) {
 	pheader := req.Request.Header
 	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
@@ -297,10 +397,14 @@ func (s *Service) Query(req *restful.Request, resp *restful.Response) {
 		if nil != err {
 			blog.Errorf("get total value error %s", err.Error())
 		}
+		p50, p95 := s.getLatencyPercentiles(results[index].SubscriptionID)
 		results[index].Statistics = &metadata.Statistics{
-			Total:   total,
-			Failure: failue,
+			Total:      total,
+			Failure:    failue,
+			P50Latency: p50,
+			P95Latency: p95,
 		}
+		results[index].HideSecrets()
 	}
 
 	info := make(map[string]interface{})
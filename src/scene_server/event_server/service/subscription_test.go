@@ -0,0 +1,75 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"configcenter/src/common/metadata"
+	commontypes "configcenter/src/common/types"
+)
+
+func TestRotateSecret(t *testing.T) {
+	now := time.Now()
+	previousExpire := commontypes.Time{Time: now.Add(time.Hour)}
+	oldsub := metadata.Subscription{Secret: "old", PreviousSecret: "older", PreviousSecretExpire: &previousExpire, SecretGracePeriod: 60}
+
+	testCases := []struct {
+		name           string
+		sub            metadata.Subscription
+		secret         string
+		previousSecret string
+		expire         *time.Time
+	}{
+		{name: "keep the secret when empty", sub: metadata.Subscription{}, secret: "old", previousSecret: "older", expire: &previousExpire.Time},
+		{name: "keep the secret when unchanged", sub: metadata.Subscription{Secret: "old"}, secret: "old", previousSecret: "older", expire: &previousExpire.Time},
+		{name: "rotate the secret", sub: metadata.Subscription{Secret: "new"}, secret: "new", previousSecret: "old", expire: timePtr(now.Add(time.Minute))},
+		{name: "clear the secrets", sub: metadata.Subscription{ClearSecret: true}, secret: "", previousSecret: "", expire: nil},
+	}
+	for _, tc := range testCases {
+		sub := tc.sub
+		rotateSecret(&oldsub, &sub, now)
+		assert.Equal(t, tc.secret, sub.Secret, tc.name)
+		assert.Equal(t, tc.previousSecret, sub.PreviousSecret, tc.name)
+		assert.Equal(t, int64(60), sub.SecretGracePeriod, tc.name)
+		if tc.expire == nil {
+			assert.Nil(t, sub.PreviousSecretExpire, tc.name)
+			continue
+		}
+		if assert.NotNil(t, sub.PreviousSecretExpire, tc.name) {
+			assert.True(t, tc.expire.Equal(sub.PreviousSecretExpire.Time), tc.name)
+		}
+	}
+
+	// nothing to rotate from
+	sub := metadata.Subscription{Secret: "new"}
+	rotateSecret(&metadata.Subscription{}, &sub, now)
+	assert.Equal(t, "new", sub.Secret)
+	assert.Empty(t, sub.PreviousSecret)
+	assert.Equal(t, int64(metadata.DefaultSecretGracePeriod), sub.SecretGracePeriod)
+}
+
+func TestValidateClearSecret(t *testing.T) {
+	_, ok := validateDelivery(&metadata.Subscription{ClearSecret: true})
+	assert.True(t, ok)
+	field, ok := validateDelivery(&metadata.Subscription{ClearSecret: true, Secret: "new"})
+	assert.False(t, ok)
+	assert.Equal(t, "clear_secret", field)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -37,14 +37,35 @@ const (
 
 	EventCacheDistCallBackCountPrefix = common.BKCacheKeyV3Prefix + "event:dist_callback_"
 
+	// EventCacheDistDeadLetterPrefix the dead letter list of the subscription,
+	// keeps the events which still fail after all the retries
+	EventCacheDistDeadLetterPrefix = common.BKCacheKeyV3Prefix + "event:dist_deadletter_"
+	// EventCacheDistRetryPrefix the failed dists of the subscription waiting to be retried, sorted by the due time
+	EventCacheDistRetryPrefix = common.BKCacheKeyV3Prefix + "event:dist_retry_"
+	// EventCacheDistDeliveryPrefix the recent delivery records of the subscription, the newest first
+	EventCacheDistDeliveryPrefix = common.BKCacheKeyV3Prefix + "event:dist_delivery_"
+	// EventCacheDistLatencyPrefix the latencies of the recent deliveries of the subscription, millisecond
+	EventCacheDistLatencyPrefix = common.BKCacheKeyV3Prefix + "event:dist_latency_"
+
 	// EventCacheSubscribeformKey the key prefix in cache
 	EventCacheSubscribeformKey = common.BKCacheKeyV3Prefix + "event:subscribeform:"
 	EventCacheSubscribesKey    = common.BKCacheKeyV3Prefix + "event:subscribers"
-	EventCacheProcessChannel   = common.BKCacheKeyV3Prefix + "event_process_channel"
+	// EventCacheSubscriptionFilterKey the hash of subscription id to the json of its filter
+	EventCacheSubscriptionFilterKey = common.BKCacheKeyV3Prefix + "event:subscription_filter"
+	EventCacheProcessChannel        = common.BKCacheKeyV3Prefix + "event_process_channel"
 
 	EventCacheIdentInstPrefix = common.BKCacheKeyV3Prefix + "ident:inst_"
 )
 
+// EventDeadLetterMaxLength the max length of the dead letter list of every subscription
+const EventDeadLetterMaxLength = 10000
+
+// EventDeliveryMaxLength the max count of the delivery records and latencies kept for every subscription
+const EventDeliveryMaxLength = 1000
+
+// EventDeliveryResponseMaxLength the max length of the response body kept in a delivery record
+const EventDeliveryResponseMaxLength = 512
+
 // EventSubscriberCacheKey returns EventSubscriberCacheKey
 func EventSubscriberCacheKey(ownerID, eventtype string) string {
 	return EventCacheSubscribeformKey + ownerID + ":" + eventtype
@@ -13,6 +13,8 @@
 package options
 
 import (
+	"time"
+
 	"configcenter/src/common/core/cc/config"
 	"github.com/spf13/pflag"
 )
@@ -46,6 +48,13 @@ type Gse struct {
 	RedisPassword string
 }
 
+// DynamicGroup the schedule of the dynamic host group evaluation
+type DynamicGroup struct {
+	// Interval the default evaluation interval, 0 means disabled
+	Interval time.Duration
+}
+
 type Config struct {
-	Gse Gse
+	Gse          Gse
+	DynamicGroup DynamicGroup
 }
@@ -16,11 +16,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"sync"
+	"time"
 
 	"configcenter/src/apimachinery"
 	"configcenter/src/apimachinery/util"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/types"
 	"configcenter/src/common/version"
 	"configcenter/src/scene_server/host_server/app/options"
@@ -79,15 +83,21 @@ func Run(ctx context.Context, op *options.ServerOption) error {
 	hostSvr.Core = engine
 	hostSvr.Service = service
 	hostSvr.Logic = service.Logics
+	go service.Logics.RunDynamicGroupEvaluation(ctx, hostSvr.getDynamicGroupInterval)
 	select {}
 	return nil
 }
 
+// defaultDynamicGroupInterval used when dynamicgroup.interval is not configured
+const defaultDynamicGroupInterval = 300 * time.Second
+
 type HostServer struct {
 	Core    *backbone.Engine
 	Config  options.Config
 	Service *hostsvc.Service
 	Logic   *logics.Logics
+
+	dynamicGroupLock sync.RWMutex
 }
 
 func (h *HostServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
@@ -96,6 +106,25 @@ func (h *HostServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 	h.Config.Gse.ZkPassword = current.ConfigMap["gse.pwd"]
 	h.Config.Gse.RedisPort = current.ConfigMap["gse.port"]
 	h.Config.Gse.RedisPassword = current.ConfigMap["gse.redis_pwd"]
+
+	interval := defaultDynamicGroupInterval
+	if val, ok := current.ConfigMap["dynamicgroup.interval"]; ok && "" != val {
+		seconds, err := strconv.ParseInt(val, 10, 64)
+		if nil != err || seconds < 0 {
+			blog.Errorf("invalid dynamicgroup.interval %s, use default %v", val, defaultDynamicGroupInterval)
+		} else {
+			interval = time.Duration(seconds) * time.Second
+		}
+	}
+	h.dynamicGroupLock.Lock()
+	h.Config.DynamicGroup.Interval = interval
+	h.dynamicGroupLock.Unlock()
+}
+
+func (h *HostServer) getDynamicGroupInterval() time.Duration {
+	h.dynamicGroupLock.RLock()
+	defer h.dynamicGroupLock.RUnlock()
+	return h.Config.DynamicGroup.Interval
 }
 
 func newServerInfo(op *options.ServerOption) (*types.ServerInfo, error) {
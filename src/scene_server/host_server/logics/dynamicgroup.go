@@ -0,0 +1,131 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	meta "configcenter/src/common/metadata"
+)
+
+const (
+	// dynamicGroupPageSize the count of the hosts searched at a time in the evaluation
+	dynamicGroupPageSize = 1000
+	// dynamicGroupCheckInterval how often the dynamic groups are checked whether they are due
+	dynamicGroupCheckInterval = time.Minute
+)
+
+// EvalDynamicGroup search the hosts of the dynamic group, and save them as its snapshot,
+// the hosts which joined or left the group since the last evaluation are returned.
+// The scheduled evaluation is skipped if the group is evaluated by another host server meanwhile.
+func (lgc *Logics) EvalDynamicGroup(pheader http.Header, group *meta.UserConfigMeta, scheduled bool) (*meta.DynamicGroupDelta, error) {
+	input := new(meta.HostCommonSearch)
+	if err := json.Unmarshal([]byte(group.Info), input); err != nil {
+		return nil, fmt.Errorf("invalid query of dynamic group %s, err: %v", group.ID, err)
+	}
+	input.AppID = group.AppID
+
+	hostIDs := make([]int64, 0)
+	err := lgc.SearchHostByPage(pheader, input, false, dynamicGroupPageSize, func(hosts []mapstr.MapStr) error {
+		for _, info := range hosts {
+			host, err := info.MapStr(common.BKInnerObjIDHost)
+			if err != nil {
+				return fmt.Errorf("invalid host, err: %v", err)
+			}
+			hostID, err := host.Int64(common.BKHostIDField)
+			if err != nil {
+				return fmt.Errorf("invalid host id, err: %v", err)
+			}
+			hostIDs = append(hostIDs, hostID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search hosts of dynamic group %s failed, err: %v", group.ID, err)
+	}
+
+	appID := strconv.FormatInt(group.AppID, 10)
+	params := &meta.DynamicGroupSnapshotParams{HostIDs: hostIDs, Scheduled: scheduled, PreEvalTime: group.EvalTime}
+	result, err := lgc.CoreAPI.HostController().User().UpdateUserConfigSnapshot(context.Background(), appID, group.ID, pheader, params)
+	if err != nil || (err == nil && !result.Result) {
+		if err == nil {
+			err = errors.New(result.ErrMsg)
+		}
+		return nil, fmt.Errorf("save snapshot of dynamic group %s failed, err: %v", group.ID, err)
+	}
+
+	return &result.Data, nil
+}
+
+// RunDynamicGroupEvaluation evaluate the dynamic groups of all the suppliers when they are due,
+// until the ctx is done. The default interval is read each time, and 0 disables the evaluation.
+// It runs on every host server, a due group is saved by the first one evaluating it.
+func (lgc *Logics) RunDynamicGroupEvaluation(ctx context.Context, defaultInterval func() time.Duration) {
+	ticker := time.NewTicker(dynamicGroupCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		interval := defaultInterval()
+		if interval <= 0 {
+			continue
+		}
+		lgc.evalDueDynamicGroups(interval)
+	}
+}
+
+func (lgc *Logics) evalDueDynamicGroups(interval time.Duration) {
+	pheader := make(http.Header)
+	pheader.Set(common.BKHTTPOwnerID, common.BKSuperOwnerID)
+	pheader.Set(common.BKHTTPHeaderUser, common.CCSystemOperatorUserName)
+	result, err := lgc.CoreAPI.HostController().User().GetDynamicUserConfigs(context.Background(), pheader)
+	if err != nil || (err == nil && !result.Result) {
+		blog.Errorf("get dynamic groups failed, err: %v, %v", err, result)
+		return
+	}
+
+	now := time.Now()
+	for idx := range result.Data {
+		group := &result.Data[idx]
+		if !group.IsEvalDue(now, interval) {
+			continue
+		}
+
+		groupHeader := make(http.Header)
+		groupHeader.Set(common.BKHTTPOwnerID, group.OwnerID)
+		groupHeader.Set(common.BKHTTPHeaderUser, common.CCSystemOperatorUserName)
+		delta, err := lgc.EvalDynamicGroup(groupHeader, group, true)
+		if err != nil {
+			blog.Errorf("evaluate dynamic group %s failed, err: %v", group.ID, err)
+			continue
+		}
+		if delta.Skipped {
+			blog.V(3).Infof("dynamic group %s is evaluated by another host server, skip it", group.ID)
+			continue
+		}
+		blog.V(3).Infof("evaluate dynamic group %s, %d hosts joined, %d hosts left", group.ID, len(delta.Joined), len(delta.Left))
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// SearchHostByPage search the hosts page by page in the order of the host id, and pass each
+// page to the handler. The next page starts after the last host of the previous page instead
+// of an offset, so the hosts created or deleted during the search do not shift the pages.
+// The next page starts after the last host read from the page, including the hosts dropped
+// for having no topology, so the pages of such hosts are skipped instead of aborting the search.
+func (lgc *Logics) SearchHostByPage(pheader http.Header, data *metadata.HostCommonSearch, isDetail bool, pageSize int, handle func(hosts []mapstr.MapStr) error) error {
+	var lastID int64
+	for {
+		after := metadata.SearchConditionTree{
+			ObjectID: common.BKInnerObjIDHost,
+			Condition: []metadata.ConditionItem{
+				{Field: common.BKHostIDField, Operator: common.BKDBGT, Value: lastID},
+			},
+		}
+		page := *data
+		page.Page = metadata.BasePage{Start: 0, Limit: pageSize, Sort: common.BKHostIDField}
+		page.Filter = &after
+		if nil != data.Filter {
+			page.Filter = &metadata.SearchConditionTree{
+				Operator: metadata.SearchTreeAnd,
+				Children: []metadata.SearchConditionTree{*data.Filter, after},
+			}
+		}
+
+		result, maxHostID, err := lgc.searchHost(pheader, &page, isDetail)
+		if err != nil {
+			return err
+		}
+
+		// the hosts without topology are dropped, so a page could be empty while more hosts follow
+		if 0 != len(result.Info) {
+			if err := handle(result.Info); err != nil {
+				return err
+			}
+		}
+		if result.Count <= pageSize || maxHostID <= lastID {
+			return nil
+		}
+		lastID = maxHostID
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"configcenter/src/common"
 	"configcenter/src/common/auditoplog"
@@ -304,6 +305,13 @@ const (
 )
 
 func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSearch, isDetail bool) (*metadata.SearchHost, error) {
+	result, _, err := lgc.searchHost(pheader, data, isDetail)
+	return result, err
+}
+
+// searchHost search the hosts, and return the max id of the hosts matched by the host condition,
+// the hosts without topology are dropped from the result, but still counted in the max id
+func (lgc *Logics) searchHost(pheader http.Header, data *metadata.HostCommonSearch, isDetail bool) (*metadata.SearchHost, int64, error) {
 	var hostCond, appCond, setCond, moduleCond, mainlineCond metadata.SearchCondition
 	objectCondMap := make(map[string][]metadata.ConditionItem, 0)
 	appIDArr := make([]int64, 0)
@@ -358,14 +366,14 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 	if len(appCond.Condition) > 0 {
 		appIDArr, err = lgc.GetAppIDByCond(pheader, appCond.Condition)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 	//search mainline object by cond
 	if len(mainlineCond.Condition) > 0 {
 		objSetIDArr, err = lgc.GetSetIDByObjectCond(pheader, data.AppID, mainlineCond.Condition)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 	//search set by appcond
@@ -386,7 +394,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		}
 		setIDArr, err = lgc.GetSetIDByCond(pheader, setCond.Condition)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
@@ -396,11 +404,11 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		for objID, objCond := range objectCondMap {
 			instIDArr, err := lgc.GetObjectInstByCond(pheader, objID, objCond)
 			if err != nil {
-				return nil, err
+				return nil, 0, err
 			}
 			instHostIDArr, err := lgc.GetHostIDByInstID(pheader, objID, instIDArr)
 			if err != nil {
-				return nil, err
+				return nil, 0, err
 			}
 			if firstCond {
 				instAsstHostIDArr = instHostIDArr
@@ -430,7 +438,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		//search module by cond
 		moduleIDArr, err = lgc.GetModuleIDByCond(pheader, moduleCond.Condition)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
@@ -448,7 +456,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 	}
 	hostIDArr, err = lgc.GetHostIDByCond(pheader, moduleHostConfig)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	if len(appCond.Condition) > 0 || len(setCond.Condition) > 0 || len(moduleCond.Condition) > 0 || len(objectCondMap) > 0 || -1 != data.AppID {
@@ -465,6 +473,23 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 	condition := make(map[string]interface{})
 	hostParse.ParseHostParams(hostCond.Condition, condition)
 	hostParse.ParseHostIPParams(data.Ip, condition)
+	if nil != data.Filter {
+		if err := data.Filter.Validate(); err != nil {
+			return nil, 0, err
+		}
+		treeCond, err := lgc.GetHostCondByTree(pheader, data.AppID, data.Filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		condition = map[string]interface{}{
+			common.BKDBAND: []interface{}{condition, treeCond},
+		}
+	}
+	if data.NotReportedHours > 0 {
+		condition = map[string]interface{}{
+			common.BKDBAND: []interface{}{condition, metadata.NotReportedCondition(data.NotReportedHours, time.Now())},
+		}
+	}
 
 	query := &metadata.QueryInput{
 		Condition: condition,
@@ -473,9 +498,13 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		Sort:      data.Page.Sort,
 	}
 	gResult, err := lgc.CoreAPI.HostController().Host().GetHosts(context.Background(), pheader, query)
-	if err != nil || (err == nil && !gResult.Result) {
+	if err != nil {
 		blog.Errorf("get hosts failed, err: %v", err)
-		return nil, err
+		return nil, 0, err
+	}
+	if !gResult.Result {
+		blog.Errorf("get hosts failed, err: %s", gResult.ErrMsg)
+		return nil, 0, lgc.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader)).New(gResult.Code, gResult.ErrMsg)
 	}
 
 	hostResult := gResult.Data.Info
@@ -490,7 +519,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 	}
 	if nil != retStrErr {
 		blog.Errorf("failed to replace association object, error code is %s, input:%v", retStrErr.Error(), data)
-		return nil, retStrErr
+		return nil, 0, retStrErr
 	}
 
 	resHostIDArr := make([]int64, 0)
@@ -498,7 +527,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 	for _, j := range hostResult {
 		hostID, err := util.GetInt64ByInterface(j[common.BKHostIDField])
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		resHostIDArr = append(resHostIDArr, hostID)
 	}
@@ -506,7 +535,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 
 	mhconfig, err := lgc.GetConfigByCond(pheader, queryCond)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	blog.V(3).Infof("get modulehostconfig map:%v", mhconfig)
 	for _, mh := range mhconfig {
@@ -534,7 +563,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		fields := strings.Join(appCond.Fields, ",")
 		hostAppMap, err = lgc.GetAppMapByCond(pheader, fields, cond)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 	if nil != setCond.Fields {
@@ -549,7 +578,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		fields := strings.Join(setCond.Fields, ",")
 		hostSetMap, err = lgc.GetSetMapByCond(pheader, fields, cond)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 	if nil != moduleCond.Fields {
@@ -564,7 +593,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		fields := strings.Join(moduleCond.Fields, ",")
 		hostModuleMap, err = lgc.GetModuleMapByCond(pheader, fields, cond)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
@@ -572,7 +601,7 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 	for _, host := range hostResult {
 		hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
 		if err != nil {
-			return nil, fmt.Errorf("invalid hostid: %v", err)
+			return nil, 0, fmt.Errorf("invalid hostid: %v", err)
 		}
 
 		//appdata
@@ -690,10 +719,57 @@ func (lgc *Logics) SearchHost(pheader http.Header, data *metadata.HostCommonSear
 		totalInfo = append(totalInfo, hostData)
 	}
 
+	if err := lgc.filterReadableFields(pheader, totalInfo); err != nil {
+		blog.Errorf("search host failed, filter the readable fields err: %v", err)
+		return nil, 0, err
+	}
+
+	var maxHostID int64
+	for _, hostID := range resHostIDArr {
+		if hostID > maxHostID {
+			maxHostID = hostID
+		}
+	}
+
 	return &metadata.SearchHost{
 		Info:  totalInfo,
 		Count: gResult.Data.Count,
-	}, nil
+	}, maxHostID, nil
+}
+
+// filterReadableFields remove the fields which the request user can not read from the hosts and their topology
+func (lgc *Logics) filterReadableFields(pheader http.Header, infos []mapstr.MapStr) error {
+	if lgc.Engine == nil || lgc.Engine.Authorizer == nil {
+		return nil
+	}
+
+	objDatas := make(map[string][]map[string]interface{})
+	for _, info := range infos {
+		for objID, val := range info {
+			switch data := val.(type) {
+			case mapstr.MapStr:
+				objDatas[objID] = append(objDatas[objID], data)
+			case map[string]interface{}:
+				objDatas[objID] = append(objDatas[objID], data)
+			case []interface{}:
+				for _, item := range data {
+					switch itemData := item.(type) {
+					case mapstr.MapStr:
+						objDatas[objID] = append(objDatas[objID], itemData)
+					case map[string]interface{}:
+						objDatas[objID] = append(objDatas[objID], itemData)
+					}
+				}
+			}
+		}
+	}
+
+	for objID, datas := range objDatas {
+		if err := lgc.Engine.Authorizer.FilterReadableFields(pheader, objID, datas...); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (lgc *Logics) GetHostIDByCond(pheader http.Header, cond map[string][]int64) ([]int64, error) {
@@ -95,6 +95,9 @@ func (lgc *Logics) UpdateHost(input map[string]interface{}, appID int64, header
 	res, err := phpapi.UpdateHostMain(hostCondition, data, appID)
 	if nil != err {
 		blog.Errorf("updateHostMain error:%v", err)
+		if isNoFieldPrivilegeErr(err) {
+			return nil, http.StatusForbidden, err
+		}
 		return nil, http.StatusBadGateway, defErr.Error(common.CCErrHostModifyFail)
 	}
 
@@ -158,6 +161,9 @@ func (lgc *Logics) UpdateHostByAppID(input *meta.UpdateHostParams, appID int64,
 			hostIDNew, err := phpapi.AddHost(proMap)
 			if nil != err {
 				blog.Errorf("addHost error:%v", err)
+				if isNoFieldPrivilegeErr(err) {
+					return nil, http.StatusForbidden, err
+				}
 				return nil, http.StatusBadGateway, defErr.Error(common.CCErrHostCreateFail)
 			}
 
@@ -193,6 +199,9 @@ func (lgc *Logics) UpdateHostByAppID(input *meta.UpdateHostParams, appID int64,
 			_, err := phpapi.UpdateHostMain(hostCondition, data, appID)
 			if nil != err {
 				blog.Errorf("updateHostMain error:%v", err)
+				if isNoFieldPrivilegeErr(err) {
+					return nil, http.StatusForbidden, err
+				}
 				return nil, http.StatusBadGateway, defErr.Error(common.CCErrHostModifyFail)
 			}
 		}
@@ -338,6 +347,9 @@ func (lgc *Logics) UpdateCustomProperty(hostID, appID int64, proeprtyJson map[st
 	res, err := phpapi.UpdateHostMain(hostCondition, params, appID)
 	if nil != err {
 		blog.Errorf("UpdateCustomProperty error:%s,, hostID:%d, appID:%d, property:%s", hostID, appID, proeprtyJson)
+		if isNoFieldPrivilegeErr(err) {
+			return nil, err
+		}
 		return nil, defErr.Error(common.CCErrHostModifyFail)
 	}
 
@@ -459,6 +471,28 @@ func (lgc *Logics) CloneHostProperty(input *meta.CloneHostPropertyParams, appID,
 		moduleIDs = append(moduleIDs, moduleID)
 	}
 
+	// check the field rights of all the destination hosts before any of them is modified
+	for dstIpV := range dstIPMap {
+		if dstIpV == input.OrgIP {
+			continue
+		}
+		cloneData := make(map[string]interface{}, len(updateHostData))
+		for key, val := range updateHostData {
+			cloneData[key] = val
+		}
+		delete(cloneData, common.BKHostIDField)
+		cloneData[common.BKHostInnerIPField] = dstIpV
+
+		var preData map[string]interface{}
+		if hostID, ok := existIPMap[dstIpV]; ok {
+			preData = dstHostMap[hostID]
+		}
+		if err := phpapi.checkWritableFields(cloneData, preData); nil != err {
+			blog.Errorf("CloneHostProperty clone to host %s failed, input:%v, err:%v", dstIpV, input, err)
+			return nil, err
+		}
+	}
+
 	// 克隆主机, 已存在的修改，不存在的新增；dstIpArr: 全部要克隆的主机，existIpArr：已存在的要克隆的主机
 	blog.V(3).Infof("existIpArr:%v, input:%v", existIPMap, input)
 	for dstIpV, _ := range dstIPMap {
@@ -20,6 +20,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	ccErr "configcenter/src/common/errors"
 	meta "configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	hutil "configcenter/src/scene_server/host_server/util"
@@ -31,7 +32,7 @@ func (phpapi *PHPAPI) UpdateHostMain(hostCondition, data map[string]interface{},
 	//blog.V(3).Infof("updateHostMain start")
 	blog.V(3).Infof("hostCondition:%v", hostCondition)
 
-	_, hostIDArr, err := phpapi.GetHostMapByCond(hostCondition)
+	hostMap, hostIDArr, err := phpapi.GetHostMapByCond(hostCondition)
 
 	blog.V(3).Infof("hostIDArr:%v", hostIDArr)
 	if nil != err {
@@ -44,6 +45,11 @@ func (phpapi *PHPAPI) UpdateHostMain(hostCondition, data map[string]interface{},
 		return "", errors.New("not find host info ")
 	}
 
+	// the unchanged protected fields are allowed, so check them with the current data
+	if err := phpapi.checkWritableFields(data, hostMap[hostIDArr[0]]); nil != err {
+		return "", err
+	}
+
 	ownerID := util.GetOwnerID(phpapi.header)
 	valid := validator.NewValidMapWithKeyFields(ownerID, common.BKInnerObjIDHost, []string{common.CreateTimeField, common.LastTimeField, common.BKChildStr, common.BKOwnerIDField}, phpapi.header, phpapi.logic.Engine)
 	validErr := valid.ValidMap(data, common.ValidUpdate, hostIDArr[0])
@@ -104,6 +110,9 @@ func (phpapi *PHPAPI) UpdateHostMain(hostCondition, data map[string]interface{},
 }
 
 func (phpapi *PHPAPI) AddHost(data map[string]interface{}) (int64, error) {
+	if err := phpapi.checkWritableFields(data, nil); nil != err {
+		return 0, err
+	}
 	hostID, err := phpapi.addObj(data, common.BKInnerObjIDHost)
 	if nil == err {
 		err = phpapi.handleHostAssocation(hostID, data)
@@ -111,6 +120,33 @@ func (phpapi *PHPAPI) AddHost(data map[string]interface{}) (int64, error) {
 	return hostID, err
 }
 
+// checkWritableFields reject the modification of the host fields which the request user can not write,
+// every submitted protected field is treated as modified if the host has no current data
+func (phpapi *PHPAPI) checkWritableFields(data, preData map[string]interface{}) error {
+	if nil == phpapi.logic.Engine || nil == phpapi.logic.Engine.Authorizer {
+		return nil
+	}
+	return phpapi.logic.Engine.Authorizer.CheckWritableFields(phpapi.header, common.BKInnerObjIDHost, data, preData)
+}
+
+// filterReadableFields remove the host fields which the request user can not read
+func (phpapi *PHPAPI) filterReadableFields(hostMap map[int64]map[string]interface{}) error {
+	if nil == phpapi.logic.Engine || nil == phpapi.logic.Engine.Authorizer {
+		return nil
+	}
+	hosts := make([]map[string]interface{}, 0, len(hostMap))
+	for _, host := range hostMap {
+		hosts = append(hosts, host)
+	}
+	return phpapi.logic.Engine.Authorizer.FilterReadableFields(phpapi.header, common.BKInnerObjIDHost, hosts...)
+}
+
+// isNoFieldPrivilegeErr return true if the host is not modified because the user can not write the fields
+func isNoFieldPrivilegeErr(err error) bool {
+	coder, ok := err.(ccErr.CCErrorCoder)
+	return ok && common.CCErrCommNoFieldPrivilege == coder.GetCode()
+}
+
 func (phpapi *PHPAPI) AddModuleHostConfig(hostID, appID int64, moduleIDs []int64) error {
 	data := &meta.ModuleHostConfigParams{
 		ApplicationID: appID,
@@ -198,6 +234,9 @@ func (phpapi *PHPAPI) SetHostData(moduleHostConfig []map[string]int64, hostMap m
 	if err != nil {
 		return hostData, err
 	}
+	if err := phpapi.filterReadableFields(hostMap); err != nil {
+		return hostData, err
+	}
 	for _, config := range moduleHostConfig {
 		host, hasHost := hostMap[config[common.BKHostIDField]]
 		if !hasHost {
@@ -0,0 +1,84 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"net/http"
+
+	"configcenter/src/common"
+	meta "configcenter/src/common/metadata"
+	hostParse "configcenter/src/common/paraparse"
+)
+
+// GetHostCondByTree translate the condition tree to the mongo condition of the hosts. The
+// conditions of the host are used as they are, and the conditions of the other objects are
+// translated to the ids of the hosts which belong to the instances matched.
+func (lgc *Logics) GetHostCondByTree(pheader http.Header, appID int64, tree *meta.SearchConditionTree) (map[string]interface{}, error) {
+	return tree.BuildCondition(func(leaf *meta.SearchConditionTree) (map[string]interface{}, error) {
+		if leaf.ObjectID == common.BKInnerObjIDHost {
+			cond := make(map[string]interface{})
+			hostParse.ParseHostParams(leaf.Condition, cond)
+			return cond, nil
+		}
+
+		hostIDArr, err := lgc.getHostIDByObjectCond(pheader, appID, leaf.ObjectID, leaf.Condition)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDArr},
+		}, nil
+	})
+}
+
+// getHostIDByObjectCond get the ids of the hosts which belong to the instances of the object matched
+func (lgc *Logics) getHostIDByObjectCond(pheader http.Header, appID int64, objID string, cond []meta.ConditionItem) ([]int64, error) {
+	var err error
+	instIDArr := make([]int64, 0)
+	switch objID {
+	case common.BKInnerObjIDApp:
+		instIDArr, err = lgc.GetAppIDByCond(pheader, cond)
+		if err != nil {
+			return nil, err
+		}
+		return lgc.GetHostIDByCond(pheader, map[string][]int64{common.BKAppIDField: instIDArr})
+
+	case common.BKInnerObjIDSet:
+		instIDArr, err = lgc.GetSetIDByCond(pheader, cond)
+		if err != nil {
+			return nil, err
+		}
+		return lgc.GetHostIDByCond(pheader, map[string][]int64{common.BKSetIDField: instIDArr})
+
+	case common.BKInnerObjIDModule:
+		instIDArr, err = lgc.GetModuleIDByCond(pheader, cond)
+		if err != nil {
+			return nil, err
+		}
+		return lgc.GetHostIDByCond(pheader, map[string][]int64{common.BKModuleIDField: instIDArr})
+
+	case common.BKINnerObjIDObject:
+		instIDArr, err = lgc.GetSetIDByObjectCond(pheader, appID, cond)
+		if err != nil {
+			return nil, err
+		}
+		return lgc.GetHostIDByCond(pheader, map[string][]int64{common.BKSetIDField: instIDArr})
+
+	default:
+		instIDArr, err = lgc.GetObjectInstByCond(pheader, objID, cond)
+		if err != nil {
+			return nil, err
+		}
+		return lgc.GetHostIDByInstID(pheader, objID, instIDArr)
+	}
+}
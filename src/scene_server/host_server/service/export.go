@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	hutil "configcenter/src/scene_server/host_server/util"
+)
+
+const (
+	defaultExportPageSize = 500
+	maxExportPageSize     = 2000
+
+	// exportErrorHeader the trailer which carries the error happened after the export started
+	exportErrorHeader = "X-Bk-Export-Error"
+)
+
+// ExportHost stream the hosts searched as ndjson or csv. The hosts are searched page by page,
+// and each page is written before the next is searched, so the memory used does not grow
+// with the count of the hosts.
+func (s *Service) ExportHost(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+
+	body := new(meta.HostCommonSearch)
+	if err := json.NewDecoder(req.Request.Body).Decode(body); err != nil {
+		blog.Errorf("export host failed with decode body err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if nil != body.Filter {
+		if err := body.Filter.Validate(); err != nil {
+			blog.Errorf("export host failed with invalid filter, err: %v", err)
+			resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "filter")})
+			return
+		}
+	}
+	if body.NotReportedHours < 0 {
+		blog.Errorf("export host failed with invalid not_reported_hours %d", body.NotReportedHours)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "not_reported_hours")})
+		return
+	}
+
+	pageSize := defaultExportPageSize
+	if size := req.QueryParameter("page_size"); "" != size {
+		var err error
+		pageSize, err = strconv.Atoi(size)
+		if err != nil || pageSize <= 0 || pageSize > maxExportPageSize {
+			blog.Errorf("export host failed with invalid page size %s", size)
+			resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "page_size")})
+			return
+		}
+	}
+
+	var fields []string
+	for _, cond := range body.Condition {
+		if cond.ObjectID == common.BKInnerObjIDHost {
+			fields = cond.Fields
+		}
+	}
+	writer, err := hutil.NewExportWriter(req.QueryParameter("format"), resp.ResponseWriter, fields)
+	if err != nil {
+		blog.Errorf("export host failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "format")})
+		return
+	}
+
+	isDetail := "true" == req.QueryParameter("detail")
+	started := false
+	err = s.Logics.SearchHostByPage(pheader, body, isDetail, pageSize, func(hosts []mapstr.MapStr) error {
+		if !started {
+			resp.Header().Set("Content-Type", writer.ContentType())
+			resp.Header().Set("Trailer", exportErrorHeader)
+			resp.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := writer.Write(hosts); err != nil {
+			return err
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if flusher, ok := resp.ResponseWriter.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		blog.Errorf("export host failed, err: %v", err)
+		if started {
+			writer.Abort(err.Error())
+			writer.Flush()
+			resp.Header().Set(exportErrorHeader, err.Error())
+			return
+		}
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrHostGetFail)})
+		return
+	}
+	if !started {
+		resp.Header().Set("Content-Type", writer.ContentType())
+		resp.WriteHeader(http.StatusOK)
+		writer.Flush()
+	}
+}
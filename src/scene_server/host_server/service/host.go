@@ -23,6 +23,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/auditoplog"
+	"configcenter/src/common/backbone"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/mapstr"
 	meta "configcenter/src/common/metadata"
@@ -181,11 +182,24 @@ func (s *Service) GetHostInstanceProperties(req *restful.Request, resp *restful.
 		return
 	}
 
+	var privilege *backbone.UserPrivilege
+	if s.Engine != nil && s.Engine.Authorizer != nil {
+		privilege, err = s.Engine.Authorizer.GetRequestPrivilege(pheader)
+		if err != nil {
+			blog.Errorf("get host properties failed, get the field rights err: %v", err)
+			resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+			return
+		}
+	}
+
 	result := make([]meta.HostInstanceProperties, 0)
 	for _, attr := range attribute {
 		if attr.PropertyID == common.BKChildStr {
 			continue
 		}
+		if privilege != nil && !privilege.CanReadField(common.BKInnerObjIDHost, attr.PropertyID) {
+			continue
+		}
 		result = append(result, meta.HostInstanceProperties{
 			PropertyID:    attr.PropertyID,
 			PropertyName:  attr.PropertyName,
@@ -394,6 +408,18 @@ func (s *Service) SearchHost(req *restful.Request, resp *restful.Response) {
 		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
 		return
 	}
+	if nil != body.Filter {
+		if err := body.Filter.Validate(); err != nil {
+			blog.Errorf("search host failed with invalid filter, err: %v", err)
+			resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "filter")})
+			return
+		}
+	}
+	if body.NotReportedHours < 0 {
+		blog.Errorf("search host failed with invalid not_reported_hours %d", body.NotReportedHours)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "not_reported_hours")})
+		return
+	}
 
 	host, err := s.Logics.SearchHost(pheader, body, false)
 	if err != nil {
@@ -418,6 +444,18 @@ func (s *Service) SearchHostWithAsstDetail(req *restful.Request, resp *restful.R
 		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
 		return
 	}
+	if nil != body.Filter {
+		if err := body.Filter.Validate(); err != nil {
+			blog.Errorf("search host failed with invalid filter, err: %v", err)
+			resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "filter")})
+			return
+		}
+	}
+	if body.NotReportedHours < 0 {
+		blog.Errorf("search host failed with invalid not_reported_hours %d", body.NotReportedHours)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "not_reported_hours")})
+		return
+	}
 
 	host, err := s.Logics.SearchHost(pheader, body, true)
 	if err != nil {
@@ -432,6 +470,14 @@ func (s *Service) SearchHostWithAsstDetail(req *restful.Request, resp *restful.R
 	})
 }
 
+// checkWritableFields reject the modification of the host fields which the request user can not write
+func (s *Service) checkWritableFields(pheader http.Header, data, preData map[string]interface{}) error {
+	if s.Engine == nil || s.Engine.Authorizer == nil {
+		return nil
+	}
+	return s.Engine.Authorizer.CheckWritableFields(pheader, common.BKInnerObjIDHost, data, preData)
+}
+
 func (s *Service) UpdateHostBatch(req *restful.Request, resp *restful.Response) {
 	pheader := req.Request.Header
 	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
@@ -486,6 +532,14 @@ func (s *Service) UpdateHostBatch(req *restful.Request, resp *restful.Response)
 			return
 		}
 		logPreConents[hostID] = *audit.AuditLog(hostID)
+
+		// the unchanged protected fields are allowed, so check them with the previous data
+		preData, _ := audit.GetContent(hostID).PreData.(map[string]interface{})
+		if err := s.checkWritableFields(pheader, data, preData); err != nil {
+			blog.Errorf("update host batch failed, host[%s], err: %v", id, err)
+			resp.WriteError(http.StatusForbidden, &meta.RespError{Msg: err})
+			return
+		}
 	}
 
 	opt := common.KvMap{"condition": common.KvMap{common.BKHostIDField: common.KvMap{common.BKDBIN: hostIDs}}, "data": data}
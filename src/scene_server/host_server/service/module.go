@@ -206,31 +206,13 @@ func (s *Service) HostModuleRelation(req *restful.Request, resp *restful.Respons
 			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Errorf(common.CCErrHostNotINAPP, hostID)})
 			return
 		}
+	}
 
-		opt := metadata.ModuleHostConfigParams{
-			ApplicationID: config.ApplicationID,
-			HostID:        hostID,
-		}
-
-		var result *metadata.BaseResp
-		if config.IsIncrement {
-			result, err = s.CoreAPI.HostController().Module().DelDefaultModuleHostConfig(context.Background(), pheader, &opt)
-		} else {
-			result, err = s.CoreAPI.HostController().Module().DelModuleHostConfig(context.Background(), pheader, &opt)
-		}
-		if err != nil || (err == nil && !result.Result) {
-			blog.Errorf("update host module relation, but delete default config failed, err: %v, %v", err, result.ErrMsg)
-			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrHostDELResourcePool)})
-			return
-		}
-
-		opt.ModuleID = config.ModuleID
-		result, err = s.CoreAPI.HostController().Module().AddModuleHostConfig(context.Background(), pheader, &opt)
-		if err != nil || (err == nil && !result.Result) {
-			blog.Errorf("update host module relation, but add config failed, err: %v, %v", err, result.ErrMsg)
-			resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrHostAddRelationFail)})
-			return
-		}
+	result, err := s.CoreAPI.HostController().Module().TransferHostModule(context.Background(), pheader, config)
+	if err != nil || (err == nil && !result.Result) {
+		blog.Errorf("update host module relation, but transfer host module failed, err: %v, %v", err, result.ErrMsg)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrHostTransferModule)})
+		return
 	}
 
 	user := util.GetUser(pheader)
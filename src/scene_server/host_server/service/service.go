@@ -42,12 +42,15 @@ func (s *Service) WebService() *restful.WebService {
 	//restful.DefaultRequestContentType(restful.MIME_JSON)
 	//restful.DefaultResponseContentType(restful.MIME_JSON)
 
-	ws.Route(ws.DELETE("/hosts/batch").To(s.DeleteHostBatch))
-	ws.Route(ws.GET("/hosts/{bk_supplier_account}/{bk_host_id}").To(s.GetHostInstanceProperties))
-	ws.Route(ws.GET("/hosts/snapshot/{bk_host_id}").To(s.HostSnapInfo))
-	ws.Route(ws.POST("/hosts/add").To(s.AddHost))
-	ws.Route(ws.POST("/host/add/agent").To(s.AddHostFromAgent))
-	ws.Route(ws.POST("/hosts/sync/new/host").To(s.NewHostSyncAppTopo))
+	// the routes without the privilege filter are exempt deliberately: the favourites, histories and
+	// custom settings of the request user, the read only custom query definitions, the platform
+	// and agent status lookups, and the health check
+	ws.Route(ws.DELETE("/hosts/batch").Filter(s.privilegeFilter(backbone.ResourcePrivilege(backbone.PrivilegeHostUpdate))).To(s.DeleteHostBatch))
+	ws.Route(ws.GET("/hosts/{bk_supplier_account}/{bk_host_id}").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.GetHostInstanceProperties))
+	ws.Route(ws.GET("/hosts/snapshot/{bk_host_id}").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.HostSnapInfo))
+	ws.Route(ws.POST("/hosts/add").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostUpdate))).To(s.AddHost))
+	ws.Route(ws.POST("/host/add/agent").Filter(s.privilegeFilter(backbone.ResourcePrivilege(backbone.PrivilegeHostUpdate))).To(s.AddHostFromAgent))
+	ws.Route(ws.POST("/hosts/sync/new/host").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostUpdate))).To(s.NewHostSyncAppTopo))
 	ws.Route(ws.POST("hosts/favorites/search").To(s.GetHostFavourites))
 	ws.Route(ws.POST("hosts/favorites").To(s.AddHostFavourite))
 	ws.Route(ws.PUT("hosts/favorites/{id}").To(s.UpdateHostFavouriteByID))
@@ -55,52 +58,65 @@ func (s *Service) WebService() *restful.WebService {
 	ws.Route(ws.PUT("/hosts/favorites/{id}/incr").To(s.IncrHostFavouritesCount))
 	ws.Route(ws.POST("/hosts/history").To(s.AddHistory))
 	ws.Route(ws.GET("/hosts/history/{start}/{limit}").To(s.GetHistorys))
-	ws.Route(ws.POST("/hosts/modules/biz/mutiple").To(s.AddHostMultiAppModuleRelation))
-	ws.Route(ws.POST("/hosts/modules").To(s.HostModuleRelation))
-	ws.Route(ws.POST("/hosts/modules/idle").To(s.MoveHost2EmptyModule))
-	ws.Route(ws.POST("/hosts/modules/fault").To(s.MoveHost2FaultModule))
-	ws.Route(ws.POST("/hosts/modules/resource").To(s.MoveHostToResourcePool))
-	ws.Route(ws.POST("/hosts/modules/resource/idle").To(s.AssignHostToApp))
-	ws.Route(ws.POST("/host/add/module").To(s.AssignHostToAppModule))
+	ws.Route(ws.POST("/hosts/modules/biz/mutiple").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostTrans))).To(s.AddHostMultiAppModuleRelation))
+	ws.Route(ws.POST("/hosts/modules").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostTrans))).To(s.HostModuleRelation))
+	ws.Route(ws.POST("/hosts/modules/idle").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostTrans))).To(s.MoveHost2EmptyModule))
+	ws.Route(ws.POST("/hosts/modules/fault").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostTrans))).To(s.MoveHost2FaultModule))
+	ws.Route(ws.POST("/hosts/modules/resource").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostTrans))).To(s.MoveHostToResourcePool))
+	ws.Route(ws.POST("/hosts/modules/resource/idle").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostTrans))).To(s.AssignHostToApp))
+	ws.Route(ws.POST("/host/add/module").Filter(s.privilegeFilter(backbone.ResourcePrivilege(backbone.PrivilegeHostTrans))).To(s.AssignHostToAppModule))
 	ws.Route(ws.POST("/usercustom").To(s.SaveUserCustom))
 	ws.Route(ws.POST("/usercustom/user/search").To(s.GetUserCustom))
 	ws.Route(ws.POST("/usercustom/default/search").To(s.GetDefaultCustom))
-	ws.Route(ws.POST("/hosts/search").To(s.SearchHost))
-	ws.Route(ws.POST("/hosts/search/asstdetail").To(s.SearchHostWithAsstDetail))
-	ws.Route(ws.PUT("/hosts/batch").To(s.UpdateHostBatch))
-	ws.Route(ws.PUT("/hosts/property/clone").To(s.CloneHostProperty))
-	ws.Route(ws.POST("/hosts/modules/idle/set").To(s.MoveSetHost2IdleModule))
-
-	ws.Route(ws.POST("/userapi").To(s.AddUserCustomQuery))
-	ws.Route(ws.PUT("/userapi/{bk_biz_id}/{id}").To(s.UpdateUserCustomQuery))
-	ws.Route(ws.DELETE("/userapi/{bk_biz_id}/{id}").To(s.DeleteUserCustomQuery))
+	ws.Route(ws.POST("/hosts/search").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.SearchHost))
+	ws.Route(ws.POST("/hosts/search/asstdetail").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.SearchHostWithAsstDetail))
+	ws.Route(ws.POST("/hosts/export").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.ExportHost))
+	ws.Route(ws.PUT("/hosts/batch").Filter(s.privilegeFilter(backbone.ResourcePrivilege(backbone.PrivilegeHostUpdate))).To(s.UpdateHostBatch))
+	ws.Route(ws.PUT("/hosts/property/clone").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostUpdate))).To(s.CloneHostProperty))
+	ws.Route(ws.POST("/hosts/modules/idle/set").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostTrans))).To(s.MoveSetHost2IdleModule))
+
+	ws.Route(ws.POST("/userapi").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeCustomAPI))).To(s.AddUserCustomQuery))
+	ws.Route(ws.PUT("/userapi/{bk_biz_id}/{id}").Filter(s.privilegeFilter(backbone.BizPrivilege(common.BKAppIDField, backbone.PrivilegeCustomAPI))).To(s.UpdateUserCustomQuery))
+	ws.Route(ws.DELETE("/userapi/{bk_biz_id}/{id}").Filter(s.privilegeFilter(backbone.BizPrivilege(common.BKAppIDField, backbone.PrivilegeCustomAPI))).To(s.DeleteUserCustomQuery))
 	ws.Route(ws.POST("/userapi/search/{bk_biz_id}").To(s.GetUserCustomQuery))
 	ws.Route(ws.GET("/userapi/detail/{bk_biz_id}/{id}").To(s.GetUserCustomQueryDetail))
-	ws.Route(ws.GET("/userapi/data/{bk_biz_id}/{id}/{start}/{limit}").To(s.GetUserCustomQueryResult))
+	ws.Route(ws.GET("/userapi/data/{bk_biz_id}/{id}/{start}/{limit}").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.GetUserCustomQueryResult))
+	ws.Route(ws.GET("/userapi/snapshot/{bk_biz_id}/{id}").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.GetUserCustomQuerySnapshot))
+	ws.Route(ws.POST("/userapi/snapshot/{bk_biz_id}/{id}").Filter(s.privilegeFilter(backbone.BizPrivilege(common.BKAppIDField, backbone.PrivilegeCustomAPI))).To(s.EvalUserCustomQuery))
 
-	ws.Route(ws.GET("/host/getHostListByAppidAndField/{" + common.BKAppIDField + "}/{field}").To(s.getHostListByAppidAndField))
+	ws.Route(ws.GET("/host/getHostListByAppidAndField/{" + common.BKAppIDField + "}/{field}").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.getHostListByAppidAndField))
 	ws.Route(ws.GET("getAgentStatus/{appid}").To(s.GetAgentStatus))
-	ws.Route(ws.PUT("/openapi/host/{" + common.BKAppIDField + "}").To(s.UpdateHost))
-	ws.Route(ws.PUT("/host/updateHostByAppID/{appid}").To(s.UpdateHostByAppID))
-	ws.Route(ws.POST("/gethostlistbyip").To(s.HostSearchByIP))
-	ws.Route(ws.POST("/gethostlistbyconds").To(s.HostSearchByConds))
-	ws.Route(ws.POST("/getmodulehostlist").To(s.HostSearchByModuleID))
-	ws.Route(ws.POST("/getsethostlist").To(s.HostSearchBySetID))
-	ws.Route(ws.POST("/getapphostlist").To(s.HostSearchByAppID))
-	ws.Route(ws.POST("/gethostsbyproperty").To(s.HostSearchByProperty))
-	ws.Route(ws.POST("/getIPAndProxyByCompany").To(s.GetIPAndProxyByCompany))
-	ws.Route(ws.PUT("/openapi/updatecustomproperty").To(s.UpdateCustomProperty))
-	ws.Route(ws.POST("/openapi/host/getHostAppByCompanyId").To(s.GetHostAppByCompanyId))
-	ws.Route(ws.DELETE("/openapi/host/delhostinapp").To(s.DelHostInApp))
-	ws.Route(ws.POST("/openapi/host/getGitServerIp").To(s.GetGitServerIp))
+	ws.Route(ws.PUT("/openapi/host/{" + common.BKAppIDField + "}").Filter(s.privilegeFilter(backbone.BizPrivilege(common.BKAppIDField, backbone.PrivilegeHostUpdate))).To(s.UpdateHost))
+	ws.Route(ws.PUT("/host/updateHostByAppID/{appid}").Filter(s.privilegeFilter(backbone.BizPrivilege("appid", backbone.PrivilegeHostUpdate))).To(s.UpdateHostByAppID))
+	ws.Route(ws.POST("/gethostlistbyip").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.HostSearchByIP))
+	ws.Route(ws.POST("/gethostlistbyconds").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.HostSearchByConds))
+	ws.Route(ws.POST("/getmodulehostlist").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.HostSearchByModuleID))
+	ws.Route(ws.POST("/getsethostlist").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.HostSearchBySetID))
+	ws.Route(ws.POST("/getapphostlist").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.HostSearchByAppID))
+	ws.Route(ws.POST("/gethostsbyproperty").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.HostSearchByProperty))
+	ws.Route(ws.POST("/getIPAndProxyByCompany").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.GetIPAndProxyByCompany))
+	ws.Route(ws.PUT("/openapi/updatecustomproperty").Filter(s.privilegeFilter(backbone.BizFieldPrivilege(common.BKAppIDField, backbone.PrivilegeHostUpdate))).To(s.UpdateCustomProperty))
+	ws.Route(ws.POST("/openapi/host/getHostAppByCompanyId").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.GetHostAppByCompanyId))
+	ws.Route(ws.DELETE("/openapi/host/delhostinapp").Filter(s.privilegeFilter(backbone.BizFieldPrivilege("appId", backbone.PrivilegeHostTrans))).To(s.DelHostInApp))
+	ws.Route(ws.POST("/openapi/host/getGitServerIp").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDHost, backbone.PrivilegeSearch))).To(s.GetGitServerIp))
 	ws.Route(ws.GET("/plat").To(s.GetPlat))
-	ws.Route(ws.POST("/plat").To(s.CreatePlat))
-	ws.Route(ws.DELETE("/plat/{bk_cloud_id}").To(s.DelPlat))
+	ws.Route(ws.POST("/plat").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDPlat, backbone.PrivilegeCreate))).To(s.CreatePlat))
+	ws.Route(ws.DELETE("/plat/{bk_cloud_id}").Filter(s.privilegeFilter(backbone.ObjectPrivilege(common.BKInnerObjIDPlat, backbone.PrivilegeDelete))).To(s.DelPlat))
 	ws.Route(ws.GET("/healthz").To(s.Healthz))
 
 	return ws
 }
 
+// privilegeFilter the engine is set after the web service is created, so the authorizer is got on each request
+func (s *Service) privilegeFilter(rule backbone.PrivilegeRule) restful.FilterFunction {
+	return backbone.PrivilegeFilter(func() *backbone.Authorizer {
+		if nil == s.Engine {
+			return nil
+		}
+		return s.Engine.Authorizer
+	}, rule)
+}
+
 func (s *Service) Healthz(req *restful.Request, resp *restful.Response) {
 	meta := metric.HealthMeta{IsHealthy: true}
 
@@ -15,6 +15,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -50,6 +51,15 @@ func (s *Service) AddUserCustomQuery(req *restful.Request, resp *restful.Respons
 		return
 	}
 
+	if ucq.Dynamic {
+		input := new(meta.HostCommonSearch)
+		if err := json.Unmarshal([]byte(ucq.Info), input); nil != err {
+			blog.Errorf("AddUserCustomQuery add dynamic group with invalid info, err: %v, input:%v", err, ucq)
+			resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "info")})
+			return
+		}
+	}
+
 	ucq.CreateUser = util.GetUser(req.Request.Header)
 	result, err := s.CoreAPI.HostController().User().AddUserConfig(context.Background(), req.Request.Header, ucq)
 	if nil != err || (nil == err && !result.Result) {
@@ -194,6 +204,67 @@ func (s *Service) GetUserCustomQueryDetail(req *restful.Request, resp *restful.R
 
 }
 
+// GetUserCustomQuerySnapshot get the hosts of the dynamic group at its last evaluation
+func (s *Service) GetUserCustomQuerySnapshot(req *restful.Request, resp *restful.Response) {
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(req.Request.Header))
+	appID := req.PathParameter("bk_biz_id")
+	ID := req.PathParameter("id")
+
+	result, err := s.CoreAPI.HostController().User().GetUserConfigSnapshot(context.Background(), appID, ID, req.Request.Header)
+	if nil != err || (nil == err && !result.Result) {
+		if nil == err {
+			err = errors.New(result.ErrMsg)
+		}
+		blog.Errorf("get dynamic group snapshot failed, err: %v, appid:%s, id:%s", err, appID, ID)
+		resp.WriteError(http.StatusBadGateway, &meta.RespError{Msg: defErr.Errorf(common.CCErrHostDynamicGroupSnapshotFail, err.Error())})
+		return
+	}
+
+	resp.WriteEntity(meta.Response{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     result.Data,
+	})
+}
+
+// EvalUserCustomQuery evaluate the dynamic group now instead of waiting for the schedule
+func (s *Service) EvalUserCustomQuery(req *restful.Request, resp *restful.Response) {
+	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(req.Request.Header))
+	appID := req.PathParameter("bk_biz_id")
+	ID := req.PathParameter("id")
+
+	result, err := s.CoreAPI.HostController().User().GetUserConfigDetail(context.Background(), appID, ID, req.Request.Header)
+	if nil != err || (nil == err && !result.Result) {
+		if nil == err {
+			err = errors.New(result.ErrMsg)
+		}
+		blog.Errorf("evaluate dynamic group failed, err: %v, appid:%s, id:%s", err, appID, ID)
+		resp.WriteError(http.StatusBadGateway, &meta.RespError{Msg: defErr.Errorf(common.CCErrGetUserCustomQueryDetailFaild, err.Error())})
+		return
+	}
+	if "" == result.Data.Name {
+		blog.Errorf("evaluate dynamic group failed, custom query not found, appid:%s, id:%s", appID, ID)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+		return
+	}
+	if !result.Data.IsDynamic() {
+		blog.Errorf("evaluate dynamic group failed, custom query is not dynamic, appid:%s, id:%s", appID, ID)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "dynamic")})
+		return
+	}
+
+	delta, err := s.Logics.EvalDynamicGroup(req.Request.Header, &result.Data, false)
+	if nil != err {
+		blog.Errorf("evaluate dynamic group failed, err: %v, appid:%s, id:%s", err, appID, ID)
+		resp.WriteError(http.StatusBadGateway, &meta.RespError{Msg: defErr.Errorf(common.CCErrHostDynamicGroupEvalFailed, err.Error())})
+		return
+	}
+
+	resp.WriteEntity(meta.Response{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     delta,
+	})
+}
+
 func (s *Service) GetUserCustomQueryResult(req *restful.Request, resp *restful.Response) {
 
 	language := util.GetLanguage(req.Request.Header)
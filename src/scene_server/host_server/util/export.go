@@ -0,0 +1,190 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// the formats of the host export
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+)
+
+// ExportWriter write the pages of the host search result
+type ExportWriter interface {
+	// ContentType the content type of the output
+	ContentType() string
+	// Write write a page of the hosts
+	Write(hosts []mapstr.MapStr) error
+	// Flush flush the rows buffered to the output
+	Flush() error
+	// Abort mark the output as incomplete when the output supports it
+	Abort(errMsg string) error
+}
+
+// NewExportWriter create the ExportWriter of the format, the csv has the host fields given,
+// or the fields of the first host when none is given, and the topology names of each host
+func NewExportWriter(format string, w io.Writer, fields []string) (ExportWriter, error) {
+	switch format {
+	case "", ExportFormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case ExportFormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w), fields: fields}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %s", format)
+	}
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (n *ndjsonWriter) Write(hosts []mapstr.MapStr) error {
+	for _, host := range hosts {
+		if err := n.encoder.Encode(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// Abort end the output with a failed response, so the readers can tell it from a complete output
+func (n *ndjsonWriter) Abort(errMsg string) error {
+	return n.encoder.Encode(metadata.BaseResp{Result: false, Code: common.CCErrHostGetFail, ErrMsg: errMsg})
+}
+
+// the topology columns of the csv, and the name field of each
+var csvTopoColumns = []struct {
+	objID string
+	field string
+}{
+	{common.BKInnerObjIDApp, common.BKAppNameField},
+	{common.BKInnerObjIDSet, common.BKSetNameField},
+	{common.BKInnerObjIDModule, common.BKModuleNameField},
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	fields []string
+	header bool
+}
+
+func (c *csvWriter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (c *csvWriter) Write(hosts []mapstr.MapStr) error {
+	for _, info := range hosts {
+		host, err := info.MapStr(common.BKInnerObjIDHost)
+		if err != nil {
+			return fmt.Errorf("invalid host %v, err: %v", info, err)
+		}
+
+		if !c.header {
+			if 0 == len(c.fields) {
+				for field := range host {
+					c.fields = append(c.fields, field)
+				}
+				sort.Strings(c.fields)
+			}
+			header := append([]string{}, c.fields...)
+			for _, column := range csvTopoColumns {
+				header = append(header, column.field)
+			}
+			if err := c.writer.Write(header); err != nil {
+				return err
+			}
+			c.header = true
+		}
+
+		row := make([]string, 0, len(c.fields)+len(csvTopoColumns))
+		for _, field := range c.fields {
+			row = append(row, csvValue(host[field]))
+		}
+		for _, column := range csvTopoColumns {
+			row = append(row, strings.Join(topoNames(info[column.objID], column.field), ","))
+		}
+		if err := c.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// Abort do nothing, the csv can not carry the error
+func (c *csvWriter) Abort(errMsg string) error {
+	return nil
+}
+
+func csvValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool, int, int64, float64, json.Number:
+		return fmt.Sprintf("%v", v)
+	default:
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(out)
+	}
+}
+
+// topoNames get the names of the topology instances of the host
+func topoNames(insts interface{}, field string) []string {
+	arr, ok := insts.([]interface{})
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(arr))
+	for _, inst := range arr {
+		var name interface{}
+		switch item := inst.(type) {
+		case mapstr.MapStr:
+			name = item[field]
+		case map[string]interface{}:
+			name = item[field]
+		}
+		if nil != name {
+			names = append(names, csvValue(name))
+		}
+	}
+	return names
+}
@@ -0,0 +1,102 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"configcenter/src/common/mapstr"
+)
+
+func exportPage() []mapstr.MapStr {
+	return []mapstr.MapStr{
+		{
+			"host":   map[string]interface{}{"bk_host_id": 1, "bk_host_innerip": "127.0.0.1", "bk_cloud_id": []interface{}{map[string]interface{}{"id": "0"}}},
+			"biz":    []interface{}{mapstr.MapStr{"bk_biz_name": "bk"}},
+			"set":    []interface{}{map[string]interface{}{"bk_set_name": "set1"}},
+			"module": []interface{}{map[string]interface{}{"bk_module_name": "m1"}, map[string]interface{}{"bk_module_name": "m2"}},
+		},
+		{
+			"host": map[string]interface{}{"bk_host_id": 2, "bk_host_innerip": "127.0.0.2"},
+		},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	out := new(bytes.Buffer)
+	writer, err := NewExportWriter(ExportFormatCSV, out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write(exportPage()); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write(exportPage()[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := strings.Join([]string{
+		"bk_cloud_id,bk_host_id,bk_host_innerip,bk_biz_name,bk_set_name,bk_module_name",
+		`"[{""id"":""0""}]",1,127.0.0.1,bk,set1,"m1,m2"`,
+		",2,127.0.0.2,,,",
+		",2,127.0.0.2,,,",
+		"",
+	}, "\n")
+	if out.String() != expect {
+		t.Errorf("got csv:\n%s\nwant:\n%s", out.String(), expect)
+	}
+
+	out.Reset()
+	writer, _ = NewExportWriter(ExportFormatCSV, out, []string{"bk_host_innerip"})
+	writer.Write(exportPage()[1:])
+	writer.Flush()
+	if expect := "bk_host_innerip,bk_biz_name,bk_set_name,bk_module_name\n127.0.0.2,,,\n"; out.String() != expect {
+		t.Errorf("got csv:\n%s\nwant:\n%s", out.String(), expect)
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	writer, err := NewExportWriter("", out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write(exportPage())
+	writer.Abort("failed")
+	writer.Flush()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), out.String())
+	}
+	host := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[1]), &host); err != nil {
+		t.Fatal(err)
+	}
+	if host["host"].(map[string]interface{})["bk_host_innerip"] != "127.0.0.2" {
+		t.Errorf("unexpected host %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"result":false`) || !strings.Contains(lines[2], `"bk_error_msg":"failed"`) {
+		t.Errorf("unexpected abort line %s", lines[2])
+	}
+
+	if _, err := NewExportWriter("xlsx", out, nil); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
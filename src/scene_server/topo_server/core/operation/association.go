@@ -42,6 +42,13 @@ type AssociationOperationInterface interface {
 	CreateCommonInstAssociation(params types.ContextParams, data *metadata.InstAsst) error
 	DeleteInstAssociation(params types.ContextParams, cond condition.Condition) error
 
+	CreateAssociationType(params types.ContextParams, data *metadata.AssociationType) (*metadata.AssociationType, error)
+	SearchAssociationType(params types.ContextParams, cond condition.Condition) ([]metadata.AssociationType, error)
+	UpdateAssociationType(params types.ContextParams, id int64, data frtypes.MapStr) error
+	DeleteAssociationType(params types.ContextParams, id int64) error
+	CheckAssociationType(params types.ContextParams, data *metadata.Association) error
+	CheckInstAssociationMapping(params types.ContextParams, asst metadata.Association, instID int64, asstInstIDS []int64) error
+
 	SetProxy(cls ClassificationOperationInterface, obj ObjectOperationInterface, grp GroupOperationInterface, attr AttributeOperationInterface, inst InstOperationInterface, targetModel model.Factory, targetInst inst.Factory)
 }
 
@@ -121,6 +128,10 @@ func (a *association) SearchInstAssociation(params types.ContextParams, query *m
 
 func (a *association) CreateCommonAssociation(params types.ContextParams, data *metadata.Association) error {
 
+	if err := a.CheckAssociationType(params, data); nil != err {
+		return err
+	}
+
 	//  check the association
 	cond := condition.CreateCondition()
 	cond.Field(metadata.AssociationFieldAssociationObjectID).Eq(data.AsstObjID)
@@ -0,0 +1,244 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	frtypes "configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/topo_server/core/types"
+)
+
+// the association type fields which could be changed after created
+var asstTypeMutableFields = []string{"bk_asst_type_name", "src_des", "dest_des", "on_delete"}
+
+func (a *association) CreateAssociationType(params types.ContextParams, data *metadata.AssociationType) (*metadata.AssociationType, error) {
+
+	if err := data.Validate(); nil != err {
+		blog.Errorf("[operation-asst] the association type (%#v) is invalid, the field %s is wrong", data, err.Error())
+		return nil, params.Err.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
+	}
+
+	// check the objects
+	for _, objID := range []string{data.ObjectID, data.AsstObjID} {
+		if err := a.obj.IsValidObject(params, objID); nil != err {
+			blog.Errorf("[operation-asst] the object (%s) of the association type is invalid, error info is %s", objID, err.Error())
+			return nil, err
+		}
+	}
+
+	rsp, err := a.clientSet.ObjectController().Meta().CreateAssociationType(context.Background(), params.Header, data)
+	if nil != err {
+		blog.Errorf("[operation-asst] failed to request object controller, error info is %s", err.Error())
+		return nil, params.Err.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !rsp.Result {
+		blog.Errorf("[operation-asst] failed to create the association type (%#v), error info is %s", data, rsp.ErrMsg)
+		return nil, params.Err.New(rsp.Code, rsp.ErrMsg)
+	}
+
+	return &rsp.Data, nil
+}
+
+func (a *association) SearchAssociationType(params types.ContextParams, cond condition.Condition) ([]metadata.AssociationType, error) {
+
+	rsp, err := a.clientSet.ObjectController().Meta().SelectAssociationTypes(context.Background(), params.Header, cond.ToMapStr())
+	if nil != err {
+		blog.Errorf("[operation-asst] failed to request object controller, error info is %s", err.Error())
+		return nil, params.Err.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !rsp.Result {
+		blog.Errorf("[operation-asst] failed to search the association types by the condition (%#v), error info is %s", cond.ToMapStr(), rsp.ErrMsg)
+		return nil, params.Err.New(rsp.Code, rsp.ErrMsg)
+	}
+
+	return rsp.Data, nil
+}
+
+func (a *association) UpdateAssociationType(params types.ContextParams, id int64, data frtypes.MapStr) error {
+
+	if _, err := a.findAssociationTypeByID(params, id); nil != err {
+		return err
+	}
+
+	// the objects and the mapping are referenced by the existing instance associations
+	updateData := frtypes.New()
+	for _, field := range asstTypeMutableFields {
+		if val, exists := data.Get(field); exists {
+			updateData.Set(field, val)
+		}
+	}
+
+	if val, exists := updateData.Get("on_delete"); exists {
+		switch val {
+		case metadata.AssociationOnDeleteRestrict, metadata.AssociationOnDeleteUnlink:
+		default:
+			return params.Err.Errorf(common.CCErrCommParamsIsInvalid, "on_delete")
+		}
+	}
+
+	if 0 == len(updateData) {
+		return nil
+	}
+
+	rsp, err := a.clientSet.ObjectController().Meta().UpdateAssociationType(context.Background(), id, params.Header, updateData)
+	if nil != err {
+		blog.Errorf("[operation-asst] failed to request object controller, error info is %s", err.Error())
+		return params.Err.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !rsp.Result {
+		blog.Errorf("[operation-asst] failed to update the association type (%d), error info is %s", id, rsp.ErrMsg)
+		return params.Err.New(rsp.Code, rsp.ErrMsg)
+	}
+
+	return nil
+}
+
+func (a *association) DeleteAssociationType(params types.ContextParams, id int64) error {
+
+	asstType, err := a.findAssociationTypeByID(params, id)
+	if nil != err {
+		return err
+	}
+
+	// the association type could not be deleted until no object association uses it
+	cond := condition.CreateCondition()
+	cond.Field(metadata.AssociationFieldSupplierAccount).Eq(params.SupplierAccount)
+	cond.Field(metadata.AssociationFieldAssociationTypeID).Eq(asstType.AsstTypeID)
+	asstRsp, err := a.clientSet.ObjectController().Meta().SelectObjectAssociations(context.Background(), params.Header, cond.ToMapStr())
+	if nil != err {
+		blog.Errorf("[operation-asst] failed to request object controller, error info is %s", err.Error())
+		return params.Err.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !asstRsp.Result {
+		blog.Errorf("[operation-asst] failed to search the object associations by the condition (%#v), error info is %s", cond.ToMapStr(), asstRsp.ErrMsg)
+		return params.Err.New(asstRsp.Code, asstRsp.ErrMsg)
+	}
+
+	if 0 != len(asstRsp.Data) {
+		return params.Err.Errorf(common.CCErrTopoAsstTypeInUse, asstType.AsstTypeID)
+	}
+
+	rsp, err := a.clientSet.ObjectController().Meta().DeleteAssociationType(context.Background(), id, params.Header)
+	if nil != err {
+		blog.Errorf("[operation-asst] failed to request object controller, error info is %s", err.Error())
+		return params.Err.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !rsp.Result {
+		blog.Errorf("[operation-asst] failed to delete the association type (%d), error info is %s", id, rsp.ErrMsg)
+		return params.Err.New(rsp.Code, rsp.ErrMsg)
+	}
+
+	return nil
+}
+
+// CheckAssociationType check the object association matches the objects of its association type
+func (a *association) CheckAssociationType(params types.ContextParams, data *metadata.Association) error {
+
+	if 0 == len(data.AsstTypeID) {
+		return nil
+	}
+
+	asstType, err := a.findAssociationType(params, data.AsstTypeID)
+	if nil != err {
+		return err
+	}
+
+	if asstType.ObjectID != data.ObjectID || asstType.AsstObjID != data.AsstObjID {
+		blog.Errorf("[operation-asst] the association type (%s) is defined between %s and %s, not %s and %s", asstType.AsstTypeID, asstType.ObjectID, asstType.AsstObjID, data.ObjectID, data.AsstObjID)
+		return params.Err.Errorf(common.CCErrTopoAsstTypeObjectMismatch, asstType.AsstTypeID)
+	}
+
+	return nil
+}
+
+// CheckInstAssociationMapping check the instance could link the target instances by the mapping of the association type
+func (a *association) CheckInstAssociationMapping(params types.ContextParams, asst metadata.Association, instID int64, asstInstIDS []int64) error {
+
+	if 0 == len(asst.AsstTypeID) || 0 == len(asstInstIDS) {
+		return nil
+	}
+
+	asstType, err := a.findAssociationType(params, asst.AsstTypeID)
+	if nil != err {
+		return err
+	}
+
+	if !asstType.MultiTargets() && 1 < len(asstInstIDS) {
+		return params.Err.Errorf(common.CCErrTopoInstAsstMappingViolated, asstType.AsstTypeID, asstType.Mapping)
+	}
+
+	if asstType.MultiSources() {
+		return nil
+	}
+
+	// the target instances should not be linked by other source instances
+	cond := condition.CreateCondition()
+	cond.Field(common.BKObjIDField).Eq(asst.ObjectID)
+	cond.Field(common.BKAsstObjIDField).Eq(asst.AsstObjID)
+	cond.Field(common.BKAsstTypeIDField).Eq(asst.AsstTypeID)
+	cond.Field(common.BKAsstInstIDField).In(asstInstIDS)
+	cond.Field(common.BKInstIDField).NotEq(instID)
+	exists, err := a.SearchInstAssociation(params, &metadata.QueryInput{Condition: cond.ToMapStr(), Limit: common.BKNoLimit})
+	if nil != err {
+		return err
+	}
+
+	if 0 != len(exists) {
+		blog.Errorf("[operation-asst] the target instance (%s:%d) has been linked by the instance (%s:%d)", exists[0].AsstObjectID, exists[0].AsstInstID, exists[0].ObjectID, exists[0].InstID)
+		return params.Err.Errorf(common.CCErrTopoInstAsstMappingViolated, asstType.AsstTypeID, asstType.Mapping)
+	}
+
+	return nil
+}
+
+func (a *association) findAssociationType(params types.ContextParams, asstTypeID string) (*metadata.AssociationType, error) {
+
+	cond := condition.CreateCondition()
+	cond.Field(common.BKAsstTypeIDField).Eq(asstTypeID)
+	items, err := a.SearchAssociationType(params, cond)
+	if nil != err {
+		return nil, err
+	}
+
+	if 0 == len(items) {
+		return nil, params.Err.Errorf(common.CCErrTopoAsstTypeNotFound, asstTypeID)
+	}
+
+	return &items[0], nil
+}
+
+func (a *association) findAssociationTypeByID(params types.ContextParams, id int64) (*metadata.AssociationType, error) {
+
+	cond := condition.CreateCondition()
+	cond.Field(common.BKFieldID).Eq(id)
+	items, err := a.SearchAssociationType(params, cond)
+	if nil != err {
+		return nil, err
+	}
+
+	if 0 == len(items) {
+		return nil, params.Err.Errorf(common.CCErrTopoAsstTypeNotFound, id)
+	}
+
+	return &items[0], nil
+}
@@ -81,14 +81,7 @@ func (a *attribute) CreateObjectAttribute(params types.ContextParams, data frtyp
 		return nil, params.Err.New(common.CCErrTopoObjectAttributeCreateFailed, err.Error())
 	}
 
-	// create a new one
-	err = att.Create()
-	if nil != err {
-		blog.Errorf("[operation-attr] failed to save the attribute data (%#v), error info is %s", data, err.Error())
-		return nil, err
-	}
-
-	// create association
+	// check the association before saving the attribute
 	attrMeta := &metadata.Association{}
 	if err = data.MarshalJSONInto(attrMeta); nil != err {
 		blog.Errorf("[operation-attr] failed to parse the association data, error info is %s", err.Error())
@@ -103,6 +96,23 @@ func (a *attribute) CreateObjectAttribute(params types.ContextParams, data frtyp
 			return nil, params.Err.New(common.CCErrTopoObjectAttributeCreateFailed, err.Error())
 		}
 
+		// check the association type
+		if err = a.asst.CheckAssociationType(params, attrMeta); nil != err {
+			blog.Errorf("[operation-attr] the association type of the attribute (%#v) is invalid, error info is %s", data, err.Error())
+			return nil, err
+		}
+	}
+
+	// create a new one
+	err = att.Create()
+	if nil != err {
+		blog.Errorf("[operation-attr] failed to save the attribute data (%#v), error info is %s", data, err.Error())
+		return nil, err
+	}
+
+	// create association
+	if 0 != len(attrMeta.AsstObjID) {
+
 		attrMeta.ObjectAttID = att.GetID() // the structural difference
 		if err := a.asst.CreateCommonAssociation(params, attrMeta); nil != err {
 			blog.Errorf("[operation-attr] failed to create the association(%v), error info is %s", attrMeta, err.Error())
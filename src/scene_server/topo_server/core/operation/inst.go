@@ -25,6 +25,7 @@ import (
 	frtypes "configcenter/src/common/mapstr"
 	metatype "configcenter/src/common/metadata"
 	gparams "configcenter/src/common/paraparse"
+	"configcenter/src/common/util"
 	"configcenter/src/scene_server/topo_server/core/inst"
 	"configcenter/src/scene_server/topo_server/core/model"
 	"configcenter/src/scene_server/topo_server/core/types"
@@ -34,14 +35,17 @@ import (
 type InstOperationInterface interface {
 	CreateInst(params types.ContextParams, obj model.Object, data frtypes.MapStr) (inst.Inst, error)
 	CreateInstBatch(params types.ContextParams, obj model.Object, batchInfo *InstBatchInfo) (*BatchResult, error)
-	DeleteInst(params types.ContextParams, obj model.Object, cond condition.Condition, needCheckHost bool) error
-	DeleteInstByInstID(params types.ContextParams, obj model.Object, instID []int64, needCheckHost bool) error
+	DeleteInst(params types.ContextParams, obj model.Object, cond condition.Condition, needCheckHost bool, impact *metatype.InstDeleteImpact) error
+	DeleteInstByInstID(params types.ContextParams, obj model.Object, instID []int64, needCheckHost bool, impact *metatype.InstDeleteImpact) error
 	FindOriginInst(params types.ContextParams, obj model.Object, cond *metatype.QueryInput) (*metatype.InstResult, error)
 	FindInst(params types.ContextParams, obj model.Object, cond *metatype.QueryInput, needAsstDetail bool) (count int, results []inst.Inst, err error)
 	FindInstByAssociationInst(params types.ContextParams, obj model.Object, data frtypes.MapStr) (cont int, results []inst.Inst, err error)
 	FindInstChildTopo(params types.ContextParams, obj model.Object, instID int64, query *metatype.QueryInput) (count int, results []interface{}, err error)
 	FindInstParentTopo(params types.ContextParams, obj model.Object, instID int64, query *metatype.QueryInput) (count int, results []interface{}, err error)
 	FindInstTopo(params types.ContextParams, obj model.Object, instID int64, query *metatype.QueryInput) (count int, results []commonInstTopoV2, err error)
+	FindInstGraph(params types.ContextParams, query *metatype.InstGraphQuery) (*metatype.InstGraph, error)
+	AnalyzeDeleteImpact(params types.ContextParams, obj model.Object, instIDS []int64) (*metatype.InstDeleteImpact, error)
+	CheckDeleteImpact(params types.ContextParams, obj model.Object, instIDS []int64, cascade string) (*metatype.InstDeleteImpact, error)
 	UpdateInst(params types.ContextParams, data frtypes.MapStr, obj model.Object, cond condition.Condition, instID int64) error
 
 	SetProxy(modelFactory model.Factory, instFactory inst.Factory, asst AssociationOperationInterface, obj ObjectOperationInterface)
@@ -260,6 +264,12 @@ func (c *commonInst) setInstAsst(params types.ContextParams, obj model.Object, i
 				continue
 			}
 
+			// check the mapping of the association type before relinking
+			if err = c.asst.CheckInstAssociationMapping(params, asst, currInstID, asstInstIDS); nil != err {
+				blog.Errorf("[operation-inst] the inst(%s:%d) could not link the insts(%v) of the object(%s), error info is %s", obj.GetID(), currInstID, asstInstIDS, asst.AsstObjID, err.Error())
+				return err
+			}
+
 			// delete the inst asst
 			innerCond := condition.CreateCondition()
 			innerCond.Field(common.BKObjIDField).Eq(obj.GetID())
@@ -281,7 +291,7 @@ func (c *commonInst) setInstAsst(params types.ContextParams, obj model.Object, i
 				}
 				validInstIDS = append(validInstIDS, strconv.Itoa(int(asstInstID)))
 				// create a new inst in inst asst table
-				if err = c.asst.CreateCommonInstAssociation(params, &metatype.InstAsst{InstID: currInstID, ObjectID: obj.GetID(), AsstInstID: asstInstID, AsstObjectID: asst.AsstObjID}); nil != err {
+				if err = c.asst.CreateCommonInstAssociation(params, &metatype.InstAsst{InstID: currInstID, ObjectID: obj.GetID(), AsstInstID: asstInstID, AsstObjectID: asst.AsstObjID, AsstTypeID: asst.AsstTypeID}); nil != err {
 					blog.Errorf("[operation-inst] failed to create inst association, error info is %s", err.Error())
 					return err
 				}
@@ -386,7 +396,9 @@ func (c *commonInst) hasHost(params types.ContextParams, targetInst inst.Inst, c
 	return instIDS, false, nil
 }
 
-func (c *commonInst) DeleteInstByInstID(params types.ContextParams, obj model.Object, instID []int64, needCheckHost bool) error {
+// DeleteInstByInstID delete the insts and their mainline children,
+// the impact returned by CheckDeleteImpact is resolved after the checks of the deletion pass, it could be nil
+func (c *commonInst) DeleteInstByInstID(params types.ContextParams, obj model.Object, instID []int64, needCheckHost bool, impact *metatype.InstDeleteImpact) error {
 
 	cond := condition.CreateCondition()
 	cond.Field(common.BKOwnerIDField).Eq(params.SupplierAccount)
@@ -417,15 +429,23 @@ func (c *commonInst) DeleteInstByInstID(params types.ContextParams, obj model.Ob
 		deleteIDS = append(deleteIDS, ids...)
 	}
 
+	// check the associations which block the delete before any association is unlinked,
+	// the cascade unlinks all of them, and the blocking dependents have been checked with the impact
+	if nil == impact {
+		if err := c.checkBeAssociations(params, obj, deleteIDS); nil != err {
+			return err
+		}
+	}
+
+	if err := c.resolveDeleteImpact(params, impact, deleteIDS); nil != err {
+		return err
+	}
+
 	for _, delInst := range deleteIDS {
 		preAudit := NewSupplementary().Audit(params, c.clientSet, obj, c).CreateSnapshot(delInst.instID, condition.CreateCondition().ToMapStr())
 
-		innerCond := condition.CreateCondition()
-		innerCond.Field(common.BKAsstObjIDField).Eq(obj.GetID())
-		innerCond.Field(common.BKOwnerIDField).Eq(params.SupplierAccount)
-		innerCond.Field(common.BKAsstInstIDField).Eq(delInst.instID)
-		err := c.asst.CheckBeAssociation(params, obj, innerCond)
-		if nil != err {
+		innerCond := c.beAssociationCond(params, obj, delInst.instID)
+		if err := c.unlinkBeAssociation(params, innerCond); nil != err {
 			return err
 		}
 
@@ -462,7 +482,157 @@ func (c *commonInst) DeleteInstByInstID(params types.ContextParams, obj model.Ob
 	return nil
 }
 
-func (c *commonInst) DeleteInst(params types.ContextParams, obj model.Object, cond condition.Condition, needCheckHost bool) error {
+// checkBeAssociations return an error if any inst is referenced by the associations which do not unlink on delete
+func (c *commonInst) checkBeAssociations(params types.ContextParams, obj model.Object, deleteIDS []deletedInst) error {
+
+	for _, delInst := range deleteIDS {
+		unlinkTypes, err := c.findUnlinkAsstTypes(params, c.beAssociationCond(params, obj, delInst.instID))
+		if nil != err {
+			return err
+		}
+
+		checkCond := c.beAssociationCond(params, obj, delInst.instID)
+		if 0 != len(unlinkTypes) {
+			checkCond.Field(common.BKAsstTypeIDField).NotIn(unlinkTypes)
+		}
+		if err := c.asst.CheckBeAssociation(params, obj, checkCond); nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// beAssociationCond the condition of the associations which point to the inst
+func (c *commonInst) beAssociationCond(params types.ContextParams, obj model.Object, instID int64) condition.Condition {
+	cond := condition.CreateCondition()
+	cond.Field(common.BKAsstObjIDField).Eq(obj.GetID())
+	cond.Field(common.BKOwnerIDField).Eq(params.SupplierAccount)
+	cond.Field(common.BKAsstInstIDField).Eq(instID)
+	return cond
+}
+
+// findUnlinkAsstTypes return the association types which unlink on delete among the associations matched by the condition
+func (c *commonInst) findUnlinkAsstTypes(params types.ContextParams, cond condition.Condition) ([]string, error) {
+
+	exists, err := c.asst.SearchInstAssociation(params, &metatype.QueryInput{Condition: cond.ToMapStr(), Limit: common.BKNoLimit})
+	if nil != err {
+		return nil, err
+	}
+
+	asstTypeIDS := []string{}
+	for _, asst := range exists {
+		if 0 != len(asst.AsstTypeID) {
+			asstTypeIDS = append(asstTypeIDS, asst.AsstTypeID)
+		}
+	}
+	if 0 == len(asstTypeIDS) {
+		return []string{}, nil
+	}
+
+	typeCond := condition.CreateCondition()
+	typeCond.Field(common.BKAsstTypeIDField).In(util.RemoveDuplicatesAndEmpty(asstTypeIDS))
+	typeCond.Field("on_delete").Eq(metatype.AssociationOnDeleteUnlink)
+	asstTypes, err := c.asst.SearchAssociationType(params, typeCond)
+	if nil != err {
+		return nil, err
+	}
+
+	unlinkTypes := []string{}
+	for _, asstType := range asstTypes {
+		unlinkTypes = append(unlinkTypes, asstType.AsstTypeID)
+	}
+	return unlinkTypes, nil
+}
+
+// unlinkBeAssociation remove the associations whose association type unlinks on delete,
+// the deleted inst id is also removed from the association attribute of the source inst,
+// it must be called after the blocking associations are checked, the unlinked associations are not restored
+func (c *commonInst) unlinkBeAssociation(params types.ContextParams, cond condition.Condition) error {
+
+	unlinkTypes, err := c.findUnlinkAsstTypes(params, cond)
+	if nil != err || 0 == len(unlinkTypes) {
+		return err
+	}
+
+	exists, err := c.asst.SearchInstAssociation(params, &metatype.QueryInput{Condition: cond.ToMapStr(), Limit: common.BKNoLimit})
+	if nil != err {
+		return err
+	}
+
+	for _, asst := range exists {
+		if !util.InStrArr(unlinkTypes, asst.AsstTypeID) {
+			continue
+		}
+
+		if err := c.unlinkInstAsst(params, asst); nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unlinkInstAsst remove the associated inst id from the association attribute of the source inst
+func (c *commonInst) unlinkInstAsst(params types.ContextParams, asst metatype.InstAsst) error {
+
+	srcObj, err := c.obj.FindSingleObject(params, asst.ObjectID)
+	if nil != err {
+		return err
+	}
+
+	objAssts, err := c.asst.SearchObjectAssociation(params, asst.ObjectID)
+	if nil != err {
+		return err
+	}
+
+	for _, objAsst := range objAssts {
+		if objAsst.AsstObjID != asst.AsstObjectID || objAsst.AsstTypeID != asst.AsstTypeID {
+			continue
+		}
+
+		srcCond := condition.CreateCondition()
+		srcCond.Field(srcObj.GetInstIDFieldName()).Eq(asst.InstID)
+		if srcObj.IsCommon() {
+			srcCond.Field(common.BKObjIDField).Eq(srcObj.GetID())
+		}
+		srcInsts, err := c.FindOriginInst(params, srcObj, &metatype.QueryInput{Condition: srcCond.ToMapStr(), Limit: common.BKNoLimit})
+		if nil != err {
+			return err
+		}
+
+		for _, srcInst := range srcInsts.Info {
+			asstVal, err := srcInst.String(objAsst.ObjectAttID)
+			if nil != err {
+				continue
+			}
+
+			keepIDS := []string{}
+			for _, asstID := range strings.Split(asstVal, common.InstAsstIDSplit) {
+				if 0 == len(strings.TrimSpace(asstID)) || strconv.FormatInt(asst.AsstInstID, 10) == asstID {
+					continue
+				}
+				keepIDS = append(keepIDS, asstID)
+			}
+
+			keepVal := strings.Join(keepIDS, common.InstAsstIDSplit)
+			if keepVal == asstVal {
+				continue
+			}
+
+			// the association attribute is updated on behalf of the deletion, not checked by the field rights of the user
+			updateData := frtypes.MapStr{objAsst.ObjectAttID: keepVal}
+			if err = c.updateInst(params, updateData, srcObj, srcCond, asst.InstID, false); nil != err {
+				blog.Errorf("[operation-inst] failed to unlink the inst(%s:%d) from the inst(%s:%d), error info is %s", asst.ObjectID, asst.InstID, asst.AsstObjectID, asst.AsstInstID, err.Error())
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *commonInst) DeleteInst(params types.ContextParams, obj model.Object, cond condition.Condition, needCheckHost bool, impact *metatype.InstDeleteImpact) error {
 
 	// clear inst associations
 	query := &metatype.QueryInput{}
@@ -485,7 +655,7 @@ func (c *commonInst) DeleteInst(params types.ContextParams, obj model.Object, co
 		if nil != err {
 			return err
 		}
-		err = c.DeleteInstByInstID(params, obj, []int64{targetInstID}, needCheckHost)
+		err = c.DeleteInstByInstID(params, obj, []int64{targetInstID}, needCheckHost, impact)
 		if nil != err {
 			return err
 		}
@@ -995,6 +1165,11 @@ func (c *commonInst) FindInst(params types.ContextParams, obj model.Object, cond
 }
 
 func (c *commonInst) UpdateInst(params types.ContextParams, data frtypes.MapStr, obj model.Object, cond condition.Condition, instID int64) error {
+	return c.updateInst(params, data, obj, cond, instID, true)
+}
+
+// updateInst update the insts, the fields which the request user can not write are rejected if checkFields is true
+func (c *commonInst) updateInst(params types.ContextParams, data frtypes.MapStr, obj model.Object, cond condition.Condition, instID int64, checkFields bool) error {
 
 	if err := NewSupplementary().Validator(c).ValidatorUpdate(params, obj, data, instID, cond); nil != err {
 		return err
@@ -1014,6 +1189,18 @@ func (c *commonInst) UpdateInst(params types.ContextParams, data frtypes.MapStr,
 		blog.Errorf("[operation-inst] failed to search insts by the condition(%#v), error info is %s", cond.ToMapStr(), err.Error())
 		return err
 	}
+
+	// the protected fields could be submitted with the current values
+	if checkFields && nil != params.Engin && nil != params.Engin.Authorizer {
+		origins := make([]map[string]interface{}, 0, len(insts))
+		for _, inst := range insts {
+			origins = append(origins, inst.GetValues())
+		}
+		if err := params.Engin.Authorizer.CheckWritableFields(params.Header, obj.GetID(), data, origins...); nil != err {
+			return err
+		}
+	}
+
 	for _, inst := range insts {
 
 		data.ForEach(func(key string, val interface{}) {
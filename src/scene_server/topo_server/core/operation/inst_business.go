@@ -195,7 +195,7 @@ func (b *business) DeleteBusiness(params types.ContextParams, obj model.Object,
 		return err
 	}
 
-	if err = b.set.DeleteSet(params, setObj, bizID, nil); nil != err {
+	if err = b.set.DeleteSet(params, setObj, bizID, nil, nil); nil != err {
 		blog.Errorf("[operation-biz] failed to delete the set, error info is %s", err.Error())
 		return params.Err.New(common.CCErrTopoAppDeleteFailed, err.Error())
 	}
@@ -204,7 +204,7 @@ func (b *business) DeleteBusiness(params types.ContextParams, obj model.Object,
 	innerCond.Field(common.BKOwnerIDField).Eq(params.SupplierAccount)
 	innerCond.Field(common.BKAppIDField).Eq(bizID)
 
-	return b.inst.DeleteInst(params, bizObj, innerCond, true)
+	return b.inst.DeleteInst(params, bizObj, innerCond, true, nil)
 }
 
 func (b *business) FindBusiness(params types.ContextParams, obj model.Object, fields []string, cond condition.Condition) (count int, results []inst.Inst, err error) {
@@ -0,0 +1,420 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	frtypes "configcenter/src/common/mapstr"
+	metatype "configcenter/src/common/metadata"
+	"configcenter/src/scene_server/topo_server/core/model"
+	"configcenter/src/scene_server/topo_server/core/types"
+)
+
+// instGraphNode the node to be expanded in the next hop
+type instGraphNode struct {
+	objID  string
+	instID int64
+	data   frtypes.MapStr
+}
+
+// instGraph accumulate the nodes and the edges of the instance graph traversal
+type instGraph struct {
+	query *metatype.InstGraphQuery
+	// privilege the privilege of the request user, nil if the privilege is not checked for the user
+	privilege *backbone.UserPrivilege
+	nodes     map[string]bool
+	edges     map[string]bool
+	result    *metatype.InstGraph
+}
+
+func newInstGraph(query *metatype.InstGraphQuery, privilege *backbone.UserPrivilege) *instGraph {
+	return &instGraph{
+		query:     query,
+		privilege: privilege,
+		nodes:     map[string]bool{},
+		edges:     map[string]bool{},
+		result: &metatype.InstGraph{
+			Nodes: []metatype.InstGraphNode{},
+			Edges: []metatype.InstGraphEdge{},
+		},
+	}
+}
+
+func instGraphKey(objID string, instID int64) string {
+	return objID + ":" + strconv.FormatInt(instID, 10)
+}
+
+// addInsts add the insts into the graph, the new nodes are returned
+func (g *instGraph) addInsts(objID, idField, nameField string, insts []frtypes.MapStr, depth int) []instGraphNode {
+
+	added := []instGraphNode{}
+	for _, inst := range insts {
+
+		instID, err := inst.Int64(idField)
+		if nil != err {
+			blog.Warnf("[operation-inst] the inst(%#v) of the object(%s) has no valid id, error info is %s", inst, objID, err.Error())
+			continue
+		}
+
+		key := instGraphKey(objID, instID)
+		if g.nodes[key] {
+			continue
+		}
+
+		if len(g.result.Nodes) >= metatype.InstGraphMaxNodes {
+			g.result.Truncated = true
+			break
+		}
+
+		instName := ""
+		if g.canReadField(objID, nameField) {
+			instName, _ = inst.String(nameField)
+		}
+		g.nodes[key] = true
+		g.result.Nodes = append(g.result.Nodes, metatype.InstGraphNode{ObjectID: objID, InstID: instID, InstName: instName, Depth: depth})
+		added = append(added, instGraphNode{objID: objID, instID: instID, data: inst})
+	}
+
+	return added
+}
+
+// canSearch return true if the user can search the insts of the object
+func (g *instGraph) canSearch(objID string) bool {
+	return nil == g.privilege || g.privilege.HasObjectPrivilege(objID, backbone.PrivilegeSearch)
+}
+
+// canReadField return true if the user can read the field of the object
+func (g *instGraph) canReadField(objID, propertyID string) bool {
+	return nil == g.privilege || g.privilege.CanReadField(objID, propertyID)
+}
+
+// candidates return the ends of the edges which are not in the graph yet, grouped by the object id,
+// the insts of the objects which the user can not search are dropped, so are their edges
+func (g *instGraph) candidates(edges []metatype.InstGraphEdge) map[string][]int64 {
+
+	results := map[string][]int64{}
+	seen := map[string]bool{}
+	add := func(objID string, instID int64) {
+		key := instGraphKey(objID, instID)
+		if g.nodes[key] || seen[key] || !g.query.AllowObject(objID) || !g.canSearch(objID) {
+			return
+		}
+		seen[key] = true
+		results[objID] = append(results[objID], instID)
+	}
+
+	for _, edge := range edges {
+		add(edge.ObjectID, edge.InstID)
+		add(edge.AsstObjectID, edge.AsstInstID)
+	}
+
+	return results
+}
+
+// addEdges add the edges whose ends are both in the graph
+func (g *instGraph) addEdges(edges []metatype.InstGraphEdge) {
+
+	for _, edge := range edges {
+		if !g.nodes[instGraphKey(edge.ObjectID, edge.InstID)] || !g.nodes[instGraphKey(edge.AsstObjectID, edge.AsstInstID)] {
+			continue
+		}
+
+		key := instGraphKey(edge.ObjectID, edge.InstID) + "-" + edge.AsstTypeID + "-" + instGraphKey(edge.AsstObjectID, edge.AsstInstID)
+		if g.edges[key] {
+			continue
+		}
+		g.edges[key] = true
+		g.result.Edges = append(g.result.Edges, edge)
+	}
+}
+
+func sortedObjectIDs(groups map[string][]int64) []string {
+	objIDs := make([]string, 0, len(groups))
+	for objID := range groups {
+		objIDs = append(objIDs, objID)
+	}
+	sort.Strings(objIDs)
+	return objIDs
+}
+
+// FindInstGraph start from the insts matching the condition and follow the associations hop by hop,
+// only the insts of the objects the request user can search are returned, and the unreadable names are hidden
+func (c *commonInst) FindInstGraph(params types.ContextParams, query *metatype.InstGraphQuery) (*metatype.InstGraph, error) {
+
+	if err := query.Validate(); nil != err {
+		blog.Errorf("[operation-inst] the graph query (%#v) is invalid, the field %s is wrong", query, err.Error())
+		return nil, params.Err.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
+	}
+
+	var privilege *backbone.UserPrivilege
+	if nil != params.Engin && nil != params.Engin.Authorizer {
+		var err error
+		privilege, err = params.Engin.Authorizer.GetRequestPrivilege(params.Header)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	objects := map[string]model.Object{}
+	obj, err := c.findGraphObject(params, objects, query.ObjectID)
+	if nil != err {
+		return nil, err
+	}
+
+	startInsts, err := c.findGraphInsts(params, obj, nil, query.Condition)
+	if nil != err {
+		return nil, err
+	}
+
+	graph := newInstGraph(query, privilege)
+	frontier := graph.addInsts(obj.GetID(), obj.GetInstIDFieldName(), obj.GetInstNameFieldName(), startInsts, 0)
+	for depth := 1; depth <= query.Depth && 0 != len(frontier) && !graph.result.Truncated; depth++ {
+
+		edges, err := c.findGraphEdges(params, query, objects, frontier)
+		if nil != err {
+			return nil, err
+		}
+
+		next := []instGraphNode{}
+		candidates := graph.candidates(edges)
+		for _, objID := range sortedObjectIDs(candidates) {
+
+			candObj, err := c.findGraphObject(params, objects, objID)
+			if nil != err {
+				return nil, err
+			}
+
+			insts, err := c.findGraphInsts(params, candObj, candidates[objID], query.NodeFilter[objID])
+			if nil != err {
+				return nil, err
+			}
+
+			next = append(next, graph.addInsts(objID, candObj.GetInstIDFieldName(), candObj.GetInstNameFieldName(), insts, depth)...)
+		}
+
+		graph.addEdges(edges)
+		frontier = next
+	}
+
+	return graph.result, nil
+}
+
+func (c *commonInst) findGraphObject(params types.ContextParams, objects map[string]model.Object, objID string) (model.Object, error) {
+
+	if obj, exists := objects[objID]; exists {
+		return obj, nil
+	}
+
+	obj, err := c.obj.FindSingleObject(params, objID)
+	if nil != err {
+		blog.Errorf("[operation-inst] failed to find the object(%s), error info is %s", objID, err.Error())
+		return nil, err
+	}
+
+	objects[objID] = obj
+	return obj, nil
+}
+
+// findGraphInsts search the insts of the object, the inst ids are not limited if the ids is nil
+func (c *commonInst) findGraphInsts(params types.ContextParams, obj model.Object, ids []int64, filter frtypes.MapStr) ([]frtypes.MapStr, error) {
+
+	cond := condition.CreateCondition()
+	cond.Field(common.BKOwnerIDField).Eq(params.SupplierAccount)
+	if obj.IsCommon() {
+		cond.Field(common.BKObjIDField).Eq(obj.GetID())
+	}
+	if nil != ids {
+		cond.Field(obj.GetInstIDFieldName()).In(ids)
+	}
+
+	queryCond := frtypes.New()
+	queryCond.Merge(filter)
+	queryCond.Merge(cond.ToMapStr())
+
+	rsp, err := c.FindOriginInst(params, obj, &metatype.QueryInput{Condition: queryCond, Limit: common.BKNoLimit})
+	if nil != err {
+		return nil, err
+	}
+
+	return rsp.Info, nil
+}
+
+// findGraphEdges search the associations of the frontier insts
+func (c *commonInst) findGraphEdges(params types.ContextParams, query *metatype.InstGraphQuery, objects map[string]model.Object, frontier []instGraphNode) ([]metatype.InstGraphEdge, error) {
+
+	groups := map[string][]int64{}
+	nodes := map[string][]instGraphNode{}
+	for _, node := range frontier {
+		groups[node.objID] = append(groups[node.objID], node.instID)
+		nodes[node.objID] = append(nodes[node.objID], node)
+	}
+
+	edges := []metatype.InstGraphEdge{}
+	for _, objID := range sortedObjectIDs(groups) {
+
+		if query.FollowOut() {
+			items, err := c.searchGraphInstAsst(params, query, common.BKObjIDField, common.BKInstIDField, objID, groups[objID])
+			if nil != err {
+				return nil, err
+			}
+			edges = append(edges, items...)
+		}
+
+		if query.FollowIn() {
+			items, err := c.searchGraphInstAsst(params, query, common.BKAsstObjIDField, common.BKAsstInstIDField, objID, groups[objID])
+			if nil != err {
+				return nil, err
+			}
+			edges = append(edges, items...)
+		}
+
+		if query.WithMainline {
+			obj, err := c.findGraphObject(params, objects, objID)
+			if nil != err {
+				return nil, err
+			}
+
+			items, err := c.findMainlineGraphEdges(params, query, obj, nodes[objID], groups[objID])
+			if nil != err {
+				return nil, err
+			}
+			edges = append(edges, items...)
+		}
+	}
+
+	return edges, nil
+}
+
+func (c *commonInst) searchGraphInstAsst(params types.ContextParams, query *metatype.InstGraphQuery, objField, instField, objID string, ids []int64) ([]metatype.InstGraphEdge, error) {
+
+	cond := condition.CreateCondition()
+	cond.Field(objField).Eq(objID)
+	cond.Field(instField).In(ids)
+	if 0 != len(query.AsstTypeIDs) {
+		cond.Field(common.BKAsstTypeIDField).In(query.AsstTypeIDs)
+	}
+
+	assts, err := c.asst.SearchInstAssociation(params, &metatype.QueryInput{Condition: cond.ToMapStr(), Limit: common.BKNoLimit})
+	if nil != err {
+		return nil, err
+	}
+
+	edges := make([]metatype.InstGraphEdge, 0, len(assts))
+	for _, asst := range assts {
+		edges = append(edges, metatype.InstGraphEdge{
+			ObjectID:     asst.ObjectID,
+			InstID:       asst.InstID,
+			AsstObjectID: asst.AsstObjectID,
+			AsstInstID:   asst.AsstInstID,
+			AsstTypeID:   asst.AsstTypeID,
+		})
+	}
+
+	return edges, nil
+}
+
+// findMainlineGraphEdges search the mainline parents and children of the insts, the hosts are the children of the modules
+func (c *commonInst) findMainlineGraphEdges(params types.ContextParams, query *metatype.InstGraphQuery, obj model.Object, nodes []instGraphNode, ids []int64) ([]metatype.InstGraphEdge, error) {
+
+	edges := []metatype.InstGraphEdge{}
+	switch obj.GetID() {
+	case common.BKInnerObjIDHost:
+		if !query.FollowOut() {
+			return edges, nil
+		}
+		return c.searchModuleHostGraphEdges(params, common.BKHostIDField, ids)
+
+	case common.BKInnerObjIDModule:
+		if query.FollowIn() {
+			items, err := c.searchModuleHostGraphEdges(params, common.BKModuleIDField, ids)
+			if nil != err {
+				return nil, err
+			}
+			edges = append(edges, items...)
+		}
+	}
+
+	if query.FollowOut() {
+		parentObj, err := obj.GetMainlineParentObject()
+		switch {
+		case io.EOF == err:
+		case nil != err:
+			blog.Errorf("[operation-inst] failed to find the mainline parent of the object(%s), error info is %s", obj.GetID(), err.Error())
+			return nil, err
+		default:
+			for _, node := range nodes {
+				parentID, err := node.data.Int64(common.BKInstParentStr)
+				if nil != err || 0 >= parentID {
+					continue
+				}
+				edges = append(edges, metatype.InstGraphEdge{ObjectID: obj.GetID(), InstID: node.instID, AsstObjectID: parentObj.GetID(), AsstInstID: parentID, AsstTypeID: metatype.AssociationTypeMainline})
+			}
+		}
+	}
+
+	if query.FollowIn() && common.BKInnerObjIDModule != obj.GetID() {
+		childObj, err := obj.GetMainlineChildObject()
+		if io.EOF == err {
+			return edges, nil
+		}
+		if nil != err {
+			blog.Errorf("[operation-inst] failed to find the mainline child of the object(%s), error info is %s", obj.GetID(), err.Error())
+			return nil, err
+		}
+
+		children, err := c.findGraphInsts(params, childObj, nil, frtypes.MapStr{common.BKInstParentStr: frtypes.MapStr{common.BKDBIN: ids}})
+		if nil != err {
+			return nil, err
+		}
+
+		for _, child := range children {
+			childID, idErr := child.Int64(childObj.GetInstIDFieldName())
+			parentID, parentErr := child.Int64(common.BKInstParentStr)
+			if nil != idErr || nil != parentErr {
+				continue
+			}
+			edges = append(edges, metatype.InstGraphEdge{ObjectID: childObj.GetID(), InstID: childID, AsstObjectID: obj.GetID(), AsstInstID: parentID, AsstTypeID: metatype.AssociationTypeMainline})
+		}
+	}
+
+	return edges, nil
+}
+
+func (c *commonInst) searchModuleHostGraphEdges(params types.ContextParams, field string, ids []int64) ([]metatype.InstGraphEdge, error) {
+
+	rsp, err := c.clientSet.HostController().Module().GetModulesHostConfig(context.Background(), params.Header, map[string][]int64{field: ids})
+	if nil != err {
+		blog.Errorf("[operation-inst] failed to request the host controller, error info is %s", err.Error())
+		return nil, params.Err.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+
+	if !rsp.Result {
+		blog.Errorf("[operation-inst] failed to search the host module configures, error info is %s", rsp.ErrMsg)
+		return nil, params.Err.New(rsp.Code, rsp.ErrMsg)
+	}
+
+	edges := make([]metatype.InstGraphEdge, 0, len(rsp.Data))
+	for _, item := range rsp.Data {
+		edges = append(edges, metatype.InstGraphEdge{ObjectID: common.BKInnerObjIDHost, InstID: item.HostID, AsstObjectID: common.BKInnerObjIDModule, AsstInstID: item.ModuleID, AsstTypeID: metatype.AssociationTypeMainline})
+	}
+
+	return edges, nil
+}
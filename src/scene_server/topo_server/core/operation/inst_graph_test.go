@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	frtypes "configcenter/src/common/mapstr"
+	metatype "configcenter/src/common/metadata"
+)
+
+func TestInstGraph(t *testing.T) {
+	query := &metatype.InstGraphQuery{ObjectID: "db", ObjectIDs: []string{"host", "module"}}
+	graph := newInstGraph(query, nil)
+
+	start := graph.addInsts("db", common.BKInstIDField, common.BKInstNameField, []frtypes.MapStr{
+		{common.BKInstIDField: 1, common.BKInstNameField: "db1"},
+		{common.BKInstIDField: 1, common.BKInstNameField: "db1"},
+		{common.BKInstNameField: "no id"},
+	}, 0)
+	assert.Len(t, start, 1)
+
+	edges := []metatype.InstGraphEdge{
+		{ObjectID: "db", InstID: 1, AsstObjectID: "host", AsstInstID: 10, AsstTypeID: "runs_on"},
+		{ObjectID: "db", InstID: 1, AsstObjectID: "host", AsstInstID: 11, AsstTypeID: "runs_on"},
+		{ObjectID: "db", InstID: 1, AsstObjectID: "host", AsstInstID: 10, AsstTypeID: "runs_on"},
+		{ObjectID: "app", InstID: 5, AsstObjectID: "db", AsstInstID: 1, AsstTypeID: "uses"},
+	}
+	candidates := graph.candidates(edges)
+	assert.Equal(t, map[string][]int64{"host": {10, 11}}, candidates)
+
+	// the host 11 is filtered out, so are its edges
+	next := graph.addInsts("host", common.BKHostIDField, common.BKHostInnerIPField, []frtypes.MapStr{
+		{common.BKHostIDField: int64(10), common.BKHostInnerIPField: "127.0.0.1"},
+	}, 1)
+	assert.Len(t, next, 1)
+
+	graph.addEdges(edges)
+	assert.Equal(t, []metatype.InstGraphNode{
+		{ObjectID: "db", InstID: 1, InstName: "db1", Depth: 0},
+		{ObjectID: "host", InstID: 10, InstName: "127.0.0.1", Depth: 1},
+	}, graph.result.Nodes)
+	assert.Equal(t, []metatype.InstGraphEdge{edges[0]}, graph.result.Edges)
+	assert.False(t, graph.result.Truncated)
+}
+
+func TestInstGraphTruncated(t *testing.T) {
+	graph := newInstGraph(&metatype.InstGraphQuery{ObjectID: "host"}, nil)
+
+	insts := make([]frtypes.MapStr, 0, metatype.InstGraphMaxNodes+1)
+	for i := 0; i <= metatype.InstGraphMaxNodes; i++ {
+		insts = append(insts, frtypes.MapStr{common.BKHostIDField: i + 1})
+	}
+
+	added := graph.addInsts("host", common.BKHostIDField, common.BKHostInnerIPField, insts, 0)
+	assert.Len(t, added, metatype.InstGraphMaxNodes)
+	assert.True(t, graph.result.Truncated)
+}
+
+func TestInstGraphPrivilege(t *testing.T) {
+	privilege := backbone.NewUserPrivilege([]metatype.Privilege{{
+		ModelConfig: map[string]map[string][]string{"db": {"db": {backbone.PrivilegeSearch}, "app": {backbone.PrivilegeSearch}}},
+		FieldConfig: map[string]map[string][]string{"app": {common.BKInstNameField: {}}},
+	}})
+	graph := newInstGraph(&metatype.InstGraphQuery{ObjectID: "db"}, privilege)
+	graph.addInsts("db", common.BKInstIDField, common.BKInstNameField, []frtypes.MapStr{{common.BKInstIDField: 1, common.BKInstNameField: "db1"}}, 0)
+
+	// the host can not be searched, so it is not expanded
+	edges := []metatype.InstGraphEdge{
+		{ObjectID: "db", InstID: 1, AsstObjectID: "host", AsstInstID: 10, AsstTypeID: "runs_on"},
+		{ObjectID: "app", InstID: 5, AsstObjectID: "db", AsstInstID: 1, AsstTypeID: "uses"},
+	}
+	assert.Equal(t, map[string][]int64{"app": {5}}, graph.candidates(edges))
+
+	// the name of the app is not readable
+	graph.addInsts("app", common.BKInstIDField, common.BKInstNameField, []frtypes.MapStr{{common.BKInstIDField: 5, common.BKInstNameField: "pay"}}, 1)
+	graph.addEdges(edges)
+	assert.Equal(t, []metatype.InstGraphNode{
+		{ObjectID: "db", InstID: 1, InstName: "db1", Depth: 0},
+		{ObjectID: "app", InstID: 5, InstName: "", Depth: 1},
+	}, graph.result.Nodes)
+	assert.Equal(t, []metatype.InstGraphEdge{edges[1]}, graph.result.Edges)
+}
@@ -0,0 +1,561 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	frtypes "configcenter/src/common/mapstr"
+	metatype "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/topo_server/core/model"
+	"configcenter/src/scene_server/topo_server/core/types"
+)
+
+// instDeleteScope the insts to be deleted, including the mainline children of the requested insts
+type instDeleteScope struct {
+	objects map[string]model.Object
+	ids     map[string][]int64
+	datas   map[string][]frtypes.MapStr
+	deleted map[string]bool
+}
+
+func (s *instDeleteScope) isDeleted(objID string, instID int64) bool {
+	return s.deleted[instGraphKey(objID, instID)]
+}
+
+// AnalyzeDeleteImpact search the records which depend on the insts to be deleted, and whether they block the deletion
+func (c *commonInst) AnalyzeDeleteImpact(params types.ContextParams, obj model.Object, instIDS []int64) (*metatype.InstDeleteImpact, error) {
+
+	impact := &metatype.InstDeleteImpact{
+		ObjectID:   obj.GetID(),
+		InstIDs:    instIDS,
+		Deleted:    []metatype.InstRef{},
+		Dependents: []metatype.InstDependentGroup{},
+	}
+	if 0 == len(instIDS) {
+		return impact, nil
+	}
+
+	scope, err := c.findDeleteScope(params, obj, instIDS)
+	if nil != err {
+		return nil, err
+	}
+
+	for _, objID := range sortedObjectIDs(scope.ids) {
+		scopeObj := scope.objects[objID]
+		for _, data := range scope.datas[objID] {
+			instID, err := data.Int64(scopeObj.GetInstIDFieldName())
+			if nil != err {
+				continue
+			}
+			instName, _ := data.String(scopeObj.GetInstNameFieldName())
+			impact.Deleted = append(impact.Deleted, metatype.InstRef{ObjectID: objID, InstID: instID, InstName: instName})
+		}
+	}
+
+	if err := c.analyzeHostDependents(params, scope, impact); nil != err {
+		return nil, err
+	}
+
+	if err := c.analyzeProcessDependents(params, scope, impact); nil != err {
+		return nil, err
+	}
+
+	if err := c.analyzeAssociationDependents(params, scope, impact); nil != err {
+		return nil, err
+	}
+
+	if err := c.analyzeUserAPIDependents(params, scope, impact); nil != err {
+		return nil, err
+	}
+
+	return impact, nil
+}
+
+// CheckDeleteImpact apply the cascade policy before the insts are deleted, nothing is changed by the check,
+// the impact returned should be passed to the delete which resolves it after its own checks pass,
+// it is nil if the policy is empty or nothing has to be resolved
+func (c *commonInst) CheckDeleteImpact(params types.ContextParams, obj model.Object, instIDS []int64, cascade string) (*metatype.InstDeleteImpact, error) {
+
+	if 0 == len(cascade) {
+		return nil, nil
+	}
+
+	if !metatype.ValidDeleteCascade(cascade) {
+		blog.Errorf("[operation-inst] the cascade policy (%s) is invalid", cascade)
+		return nil, params.Err.Errorf(common.CCErrCommParamsIsInvalid, "cascade")
+	}
+
+	impact, err := c.AnalyzeDeleteImpact(params, obj, instIDS)
+	if nil != err {
+		return nil, err
+	}
+
+	ignoreKinds := []string{}
+	if metatype.DeleteCascadeUnlink == cascade {
+		ignoreKinds = append(ignoreKinds, metatype.InstDependentAssociation)
+	}
+
+	if blocked := impact.BlockedBy(ignoreKinds...); 0 != len(blocked) {
+		blog.Errorf("[operation-inst] the insts(%s:%v) are still referenced by %v", obj.GetID(), instIDS, blocked)
+		return nil, params.Err.Errorf(common.CCErrTopoInstDeleteBlocked, strings.Join(blocked, ","))
+	}
+
+	if metatype.DeleteCascadeRestrict == cascade {
+		return nil, nil
+	}
+
+	return impact, nil
+}
+
+// resolveDeleteImpact unbind the processes and unlink the association references of the deleted insts,
+// only the dependents which reference the deleted insts are resolved, so the impact could be shared by the nested deletes.
+// it is not transactional, the bindings and the references are not restored if the deletion fails afterwards,
+// so it is called right before the insts are deleted, after all the checks of the deletion pass
+func (c *commonInst) resolveDeleteImpact(params types.ContextParams, impact *metatype.InstDeleteImpact, deleted []deletedInst) error {
+
+	if nil == impact {
+		return nil
+	}
+
+	deletedKeys := map[string]bool{}
+	for _, item := range deleted {
+		deletedKeys[instGraphKey(item.obj.GetID(), item.instID)] = true
+	}
+
+	for _, group := range impact.Dependents {
+		for _, item := range group.Items {
+			if !deletedKeys[instGraphKey(item.RefObjectID, item.RefInstID)] {
+				continue
+			}
+
+			switch group.Kind {
+			case metatype.InstDependentProcess:
+				cond := frtypes.MapStr{
+					common.BKAppIDField:      item.BizID,
+					common.BKModuleNameField: item.Reference,
+					common.BKProcIDField:     item.InstID,
+				}
+				rsp, err := c.clientSet.ProcController().DeleteProc2Module(context.Background(), params.Header, cond)
+				if nil != err {
+					blog.Errorf("[operation-inst] failed to request the proc controller, error info is %s", err.Error())
+					return params.Err.Error(common.CCErrCommHTTPDoRequestFailed)
+				}
+
+				if !rsp.Result {
+					blog.Errorf("[operation-inst] failed to unbind the process(%d) from the module(%s), error info is %s", item.InstID, item.Reference, rsp.ErrMsg)
+					return params.Err.New(rsp.Code, rsp.ErrMsg)
+				}
+
+			case metatype.InstDependentAssociation:
+				asst := metatype.InstAsst{
+					ObjectID:     group.ObjectID,
+					InstID:       item.InstID,
+					AsstObjectID: item.RefObjectID,
+					AsstInstID:   item.RefInstID,
+					AsstTypeID:   item.Reference,
+				}
+				if err := c.unlinkInstAsst(params, asst); nil != err {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// findDeleteScope search the insts and their mainline children to be deleted
+func (c *commonInst) findDeleteScope(params types.ContextParams, obj model.Object, instIDS []int64) (*instDeleteScope, error) {
+
+	cond := condition.CreateCondition()
+	cond.Field(common.BKOwnerIDField).Eq(params.SupplierAccount)
+	cond.Field(obj.GetInstIDFieldName()).In(instIDS)
+	if obj.IsCommon() {
+		cond.Field(common.BKObjIDField).Eq(obj.GetID())
+	}
+
+	_, insts, err := c.FindInst(params, obj, &metatype.QueryInput{Condition: cond.ToMapStr(), Limit: common.BKNoLimit}, false)
+	if nil != err {
+		return nil, err
+	}
+
+	scope := &instDeleteScope{
+		objects: map[string]model.Object{obj.GetID(): obj},
+		ids:     map[string][]int64{},
+		datas:   map[string][]frtypes.MapStr{},
+		deleted: map[string]bool{},
+	}
+
+	for _, inst := range insts {
+		items, _, err := c.hasHost(params, inst, false)
+		if nil != err {
+			return nil, err
+		}
+
+		for _, item := range items {
+			objID := item.obj.GetID()
+			if scope.isDeleted(objID, item.instID) {
+				continue
+			}
+			scope.objects[objID] = item.obj
+			scope.ids[objID] = append(scope.ids[objID], item.instID)
+			scope.deleted[instGraphKey(objID, item.instID)] = true
+		}
+	}
+
+	for objID, ids := range scope.ids {
+		datas, err := c.findGraphInsts(params, scope.objects[objID], ids, nil)
+		if nil != err {
+			return nil, err
+		}
+		scope.datas[objID] = datas
+	}
+
+	return scope, nil
+}
+
+// findDependentNames search the names of the insts of the object
+func (c *commonInst) findDependentNames(params types.ContextParams, objects map[string]model.Object, objID string, ids []int64) (map[int64]string, error) {
+
+	names := map[int64]string{}
+	if 0 == len(ids) {
+		return names, nil
+	}
+
+	obj, err := c.findGraphObject(params, objects, objID)
+	if nil != err {
+		return nil, err
+	}
+
+	insts, err := c.findGraphInsts(params, obj, ids, nil)
+	if nil != err {
+		return nil, err
+	}
+
+	for _, inst := range insts {
+		instID, err := inst.Int64(obj.GetInstIDFieldName())
+		if nil != err {
+			continue
+		}
+		names[instID], _ = inst.String(obj.GetInstNameFieldName())
+	}
+
+	return names, nil
+}
+
+// analyzeHostDependents the hosts in the deleted modules block the deletion
+func (c *commonInst) analyzeHostDependents(params types.ContextParams, scope *instDeleteScope, impact *metatype.InstDeleteImpact) error {
+
+	moduleIDS := scope.ids[common.BKInnerObjIDModule]
+	if 0 == len(moduleIDS) {
+		return nil
+	}
+
+	edges, err := c.searchModuleHostGraphEdges(params, common.BKModuleIDField, moduleIDS)
+	if nil != err {
+		return err
+	}
+
+	hostIDS := []int64{}
+	for _, edge := range edges {
+		hostIDS = append(hostIDS, edge.InstID)
+	}
+
+	names, err := c.findDependentNames(params, scope.objects, common.BKInnerObjIDHost, hostIDS)
+	if nil != err {
+		return err
+	}
+
+	for _, edge := range edges {
+		impact.AddDependent(common.BKInnerObjIDHost, metatype.InstDependentHost, true, metatype.InstDependent{
+			InstID:      edge.InstID,
+			Name:        names[edge.InstID],
+			RefObjectID: edge.AsstObjectID,
+			RefInstID:   edge.AsstInstID,
+		})
+	}
+
+	return nil
+}
+
+// analyzeProcessDependents the processes lose their bindings if no module of the same name is left in the business
+func (c *commonInst) analyzeProcessDependents(params types.ContextParams, scope *instDeleteScope, impact *metatype.InstDeleteImpact) error {
+
+	moduleObj, exists := scope.objects[common.BKInnerObjIDModule]
+	if !exists {
+		return nil
+	}
+
+	type moduleRef struct {
+		bizID    int64
+		name     string
+		moduleID int64
+	}
+
+	refs := []moduleRef{}
+	checked := map[string]bool{}
+	for _, data := range scope.datas[common.BKInnerObjIDModule] {
+		ref := moduleRef{}
+		ref.bizID, _ = data.Int64(common.BKAppIDField)
+		ref.name, _ = data.String(common.BKModuleNameField)
+		ref.moduleID, _ = data.Int64(common.BKModuleIDField)
+
+		key := instGraphKey(ref.name, ref.bizID)
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		sameNames, err := c.findGraphInsts(params, moduleObj, nil, frtypes.MapStr{common.BKAppIDField: ref.bizID, common.BKModuleNameField: ref.name})
+		if nil != err {
+			return err
+		}
+
+		left := false
+		for _, sameName := range sameNames {
+			moduleID, err := sameName.Int64(common.BKModuleIDField)
+			if nil == err && !scope.isDeleted(common.BKInnerObjIDModule, moduleID) {
+				left = true
+				break
+			}
+		}
+
+		if !left {
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, ref := range refs {
+		cond := frtypes.MapStr{common.BKAppIDField: ref.bizID, common.BKModuleNameField: ref.name}
+		rsp, err := c.clientSet.ProcController().GetProc2Module(context.Background(), params.Header, cond)
+		if nil != err {
+			blog.Errorf("[operation-inst] failed to request the proc controller, error info is %s", err.Error())
+			return params.Err.Error(common.CCErrCommHTTPDoRequestFailed)
+		}
+
+		if !rsp.Result {
+			blog.Errorf("[operation-inst] failed to search the processes of the module(%s), error info is %s", ref.name, rsp.ErrMsg)
+			return params.Err.New(rsp.Code, rsp.ErrMsg)
+		}
+
+		procIDS := []int64{}
+		for _, item := range rsp.Data {
+			procIDS = append(procIDS, int64(item.ProcessID))
+		}
+
+		names, err := c.findDependentNames(params, scope.objects, common.BKInnerObjIDProc, procIDS)
+		if nil != err {
+			return err
+		}
+
+		for _, procID := range procIDS {
+			impact.AddDependent(common.BKInnerObjIDProc, metatype.InstDependentProcess, false, metatype.InstDependent{
+				InstID:      procID,
+				Name:        names[procID],
+				BizID:       ref.bizID,
+				RefObjectID: common.BKInnerObjIDModule,
+				RefInstID:   ref.moduleID,
+				Reference:   ref.name,
+			})
+		}
+	}
+
+	return nil
+}
+
+// analyzeAssociationDependents the insts which reference the deleted insts block the deletion,
+// unless their association type unlinks on delete
+func (c *commonInst) analyzeAssociationDependents(params types.ContextParams, scope *instDeleteScope, impact *metatype.InstDeleteImpact) error {
+
+	for _, objID := range sortedObjectIDs(scope.ids) {
+
+		cond := condition.CreateCondition()
+		cond.Field(common.BKOwnerIDField).Eq(params.SupplierAccount)
+		cond.Field(common.BKAsstObjIDField).Eq(objID)
+		cond.Field(common.BKAsstInstIDField).In(scope.ids[objID])
+		exists, err := c.asst.SearchInstAssociation(params, &metatype.QueryInput{Condition: cond.ToMapStr(), Limit: common.BKNoLimit})
+		if nil != err {
+			return err
+		}
+
+		assts := []metatype.InstAsst{}
+		asstTypeIDS := []string{}
+		srcIDS := map[string][]int64{}
+		for _, asst := range exists {
+			if scope.isDeleted(asst.ObjectID, asst.InstID) {
+				continue
+			}
+			assts = append(assts, asst)
+			srcIDS[asst.ObjectID] = append(srcIDS[asst.ObjectID], asst.InstID)
+			if 0 != len(asst.AsstTypeID) {
+				asstTypeIDS = append(asstTypeIDS, asst.AsstTypeID)
+			}
+		}
+
+		unlinkTypes := map[string]bool{}
+		if 0 != len(asstTypeIDS) {
+			typeCond := condition.CreateCondition()
+			typeCond.Field(common.BKAsstTypeIDField).In(asstTypeIDS)
+			typeCond.Field("on_delete").Eq(metatype.AssociationOnDeleteUnlink)
+			asstTypes, err := c.asst.SearchAssociationType(params, typeCond)
+			if nil != err {
+				return err
+			}
+			for _, asstType := range asstTypes {
+				unlinkTypes[asstType.AsstTypeID] = true
+			}
+		}
+
+		names := map[string]map[int64]string{}
+		for _, srcObjID := range sortedObjectIDs(srcIDS) {
+			if names[srcObjID], err = c.findDependentNames(params, scope.objects, srcObjID, srcIDS[srcObjID]); nil != err {
+				return err
+			}
+		}
+
+		for _, asst := range assts {
+			impact.AddDependent(asst.ObjectID, metatype.InstDependentAssociation, !unlinkTypes[asst.AsstTypeID], metatype.InstDependent{
+				InstID:      asst.InstID,
+				Name:        names[asst.ObjectID][asst.InstID],
+				RefObjectID: asst.AsstObjectID,
+				RefInstID:   asst.AsstInstID,
+				Reference:   asst.AsstTypeID,
+			})
+		}
+	}
+
+	return nil
+}
+
+// analyzeUserAPIDependents the saved host queries of the businesses whose conditions use the deleted sets or modules
+func (c *commonInst) analyzeUserAPIDependents(params types.ContextParams, scope *instDeleteScope, impact *metatype.InstDeleteImpact) error {
+
+	objIDS := []string{common.BKInnerObjIDSet, common.BKInnerObjIDModule}
+	bizIDS := []int64{}
+	for _, objID := range objIDS {
+		for _, data := range scope.datas[objID] {
+			bizID, err := data.Int64(common.BKAppIDField)
+			if nil == err && !util.ContainsInt64(bizIDS, bizID) {
+				bizIDS = append(bizIDS, bizID)
+			}
+		}
+	}
+
+	for _, bizID := range bizIDS {
+		query := &metatype.QueryInput{Condition: map[string]interface{}{common.BKAppIDField: bizID}, Limit: common.BKNoLimit}
+		rsp, err := c.clientSet.HostController().User().GetUserConfig(context.Background(), params.Header, query)
+		if nil != err {
+			blog.Errorf("[operation-inst] failed to request the host controller, error info is %s", err.Error())
+			return params.Err.Error(common.CCErrCommHTTPDoRequestFailed)
+		}
+
+		if !rsp.Result {
+			blog.Errorf("[operation-inst] failed to search the user api of the business(%d), error info is %s", bizID, rsp.ErrMsg)
+			return params.Err.New(rsp.Code, rsp.ErrMsg)
+		}
+
+		for _, info := range rsp.Data.Info {
+			userAPI, err := frtypes.NewFromInterface(info)
+			if nil != err {
+				blog.Warnf("[operation-inst] invalid user api (%#v), error info is %s", info, err.Error())
+				continue
+			}
+			userAPIID, _ := userAPI.String("id")
+			userAPIName, _ := userAPI.String("name")
+			userAPIInfo, _ := userAPI.String("info")
+
+			for _, objID := range objIDS {
+				obj, exists := scope.objects[objID]
+				if !exists {
+					continue
+				}
+
+				for _, refID := range userAPIReferences(userAPIInfo, objID, obj.GetInstIDFieldName(), scope.ids[objID]) {
+					impact.AddDependent(metatype.InstDependentUserAPI, metatype.InstDependentUserAPI, false, metatype.InstDependent{
+						ID:          userAPIID,
+						Name:        userAPIName,
+						BizID:       bizID,
+						RefObjectID: objID,
+						RefInstID:   refID,
+					})
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// userAPIReferences return the ids which are used by the equal or in conditions of the object in the saved host query
+func userAPIReferences(info, objID, idField string, ids []int64) []int64 {
+
+	input := metatype.HostCommonSearch{}
+	if err := json.Unmarshal([]byte(info), &input); nil != err {
+		return nil
+	}
+
+	items := []metatype.ConditionItem{}
+	for _, cond := range input.Condition {
+		if cond.ObjectID == objID {
+			items = append(items, cond.Condition...)
+		}
+	}
+
+	leaves := []*metatype.SearchConditionTree{}
+	if nil != input.Filter {
+		leaves = append(leaves, input.Filter)
+	}
+	for 0 != len(leaves) {
+		leaf := leaves[0]
+		leaves = leaves[1:]
+		for idx := range leaf.Children {
+			leaves = append(leaves, &leaf.Children[idx])
+		}
+		if leaf.ObjectID == objID {
+			items = append(items, leaf.Condition...)
+		}
+	}
+
+	refs := []int64{}
+	for _, item := range items {
+		if item.Field != idField {
+			continue
+		}
+
+		values := []interface{}{}
+		switch item.Operator {
+		case common.BKDBEQ:
+			values = append(values, item.Value)
+		case common.BKDBIN:
+			if arr, ok := item.Value.([]interface{}); ok {
+				values = append(values, arr...)
+			}
+		}
+
+		for _, value := range values {
+			refID, err := util.GetInt64ByInterface(value)
+			if nil == err && util.ContainsInt64(ids, refID) && !util.ContainsInt64(refs, refID) {
+				refs = append(refs, refID)
+			}
+		}
+	}
+
+	return refs
+}
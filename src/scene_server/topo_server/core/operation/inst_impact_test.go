@@ -0,0 +1,55 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"configcenter/src/common"
+	metatype "configcenter/src/common/metadata"
+	"configcenter/src/scene_server/topo_server/core/model"
+	"configcenter/src/scene_server/topo_server/core/types"
+)
+
+func TestUserAPIReferences(t *testing.T) {
+	info := `{"condition":[
+		{"bk_obj_id":"module","fields":[],"condition":[{"field":"bk_module_id","operator":"$eq","value":3}]},
+		{"bk_obj_id":"set","fields":[],"condition":[{"field":"bk_set_id","operator":"$in","value":[1,2]}]},
+		{"bk_obj_id":"host","fields":[],"condition":[{"field":"bk_module_id","operator":"$eq","value":4}]}
+	],"filter":{"operator":"or","children":[
+		{"bk_obj_id":"module","condition":[{"field":"bk_module_id","operator":"$in","value":[4,5]}]},
+		{"operator":"not","children":[{"bk_obj_id":"module","condition":[{"field":"bk_module_id","operator":"$ne","value":6}]}]}
+	]}}`
+
+	assert.Equal(t, []int64{3, 4}, userAPIReferences(info, common.BKInnerObjIDModule, common.BKModuleIDField, []int64{3, 4, 6}))
+	assert.Equal(t, []int64{2}, userAPIReferences(info, common.BKInnerObjIDSet, common.BKSetIDField, []int64{2, 7}))
+	assert.Empty(t, userAPIReferences(info, common.BKInnerObjIDSet, common.BKSetIDField, []int64{7}))
+	assert.Empty(t, userAPIReferences("invalid", common.BKInnerObjIDModule, common.BKModuleIDField, []int64{3}))
+}
+
+func TestResolveDeleteImpactSkipsOtherInsts(t *testing.T) {
+	params := types.ContextParams{}
+	moduleObj := model.CreateObject(params, nil, []metatype.Object{{ObjectID: common.BKInnerObjIDModule}})[0]
+	deleted := []deletedInst{{instID: 1, obj: moduleObj}}
+
+	impact := &metatype.InstDeleteImpact{ObjectID: common.BKInnerObjIDSet, InstIDs: []int64{5}}
+	impact.AddDependent(common.BKInnerObjIDProc, metatype.InstDependentProcess, false, metatype.InstDependent{InstID: 7, RefObjectID: common.BKInnerObjIDModule, RefInstID: 2, Reference: "gse"})
+	impact.AddDependent("db", metatype.InstDependentAssociation, true, metatype.InstDependent{InstID: 3, RefObjectID: common.BKInnerObjIDSet, RefInstID: 5, Reference: "belong"})
+
+	// the dependents of the other insts are resolved when those insts are deleted, so nothing is requested here
+	c := &commonInst{}
+	assert.NoError(t, c.resolveDeleteImpact(params, impact, deleted))
+	assert.NoError(t, c.resolveDeleteImpact(params, nil, deleted))
+}
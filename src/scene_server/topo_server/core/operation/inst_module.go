@@ -29,7 +29,7 @@ import (
 // ModuleOperationInterface module operation methods
 type ModuleOperationInterface interface {
 	CreateModule(params types.ContextParams, obj model.Object, bizID, setID int64, data mapstr.MapStr) (inst.Inst, error)
-	DeleteModule(params types.ContextParams, obj model.Object, bizID int64, setID, moduleIDS []int64) error
+	DeleteModule(params types.ContextParams, obj model.Object, bizID int64, setID, moduleIDS []int64, impact *metadata.InstDeleteImpact) error
 	FindModule(params types.ContextParams, obj model.Object, cond *metadata.QueryInput) (count int, results []inst.Inst, err error)
 	UpdateModule(params types.ContextParams, data mapstr.MapStr, obj model.Object, bizID, setID, moduleID int64) error
 
@@ -85,7 +85,7 @@ func (m *module) CreateModule(params types.ContextParams, obj model.Object, bizI
 	return m.inst.CreateInst(params, obj, data)
 }
 
-func (m *module) DeleteModule(params types.ContextParams, obj model.Object, bizID int64, setID, moduleIDS []int64) error {
+func (m *module) DeleteModule(params types.ContextParams, obj model.Object, bizID int64, setID, moduleIDS []int64, impact *metadata.InstDeleteImpact) error {
 
 	exists, err := m.hasHost(params, bizID, moduleIDS)
 	if nil != err {
@@ -108,7 +108,7 @@ func (m *module) DeleteModule(params types.ContextParams, obj model.Object, bizI
 		innerCond.Field(common.BKModuleIDField).In(moduleIDS)
 	}
 
-	return m.inst.DeleteInst(params, obj, innerCond, false)
+	return m.inst.DeleteInst(params, obj, innerCond, false, impact)
 }
 
 func (m *module) FindModule(params types.ContextParams, obj model.Object, cond *metadata.QueryInput) (count int, results []inst.Inst, err error) {
@@ -29,7 +29,7 @@ import (
 // SetOperationInterface set operation methods
 type SetOperationInterface interface {
 	CreateSet(params types.ContextParams, obj model.Object, bizID int64, data mapstr.MapStr) (inst.Inst, error)
-	DeleteSet(params types.ContextParams, obj model.Object, bizID int64, setIDS []int64) error
+	DeleteSet(params types.ContextParams, obj model.Object, bizID int64, setIDS []int64, impact *metadata.InstDeleteImpact) error
 	FindSet(params types.ContextParams, obj model.Object, cond *metadata.QueryInput) (count int, results []inst.Inst, err error)
 	UpdateSet(params types.ContextParams, data mapstr.MapStr, obj model.Object, bizID, setID int64) error
 
@@ -88,7 +88,7 @@ func (s *set) CreateSet(params types.ContextParams, obj model.Object, bizID int6
 	return s.inst.CreateInst(params, obj, data)
 }
 
-func (s *set) DeleteSet(params types.ContextParams, obj model.Object, bizID int64, setIDS []int64) error {
+func (s *set) DeleteSet(params types.ContextParams, obj model.Object, bizID int64, setIDS []int64, impact *metadata.InstDeleteImpact) error {
 
 	setCond := condition.CreateCondition()
 
@@ -117,13 +117,13 @@ func (s *set) DeleteSet(params types.ContextParams, obj model.Object, bizID int6
 		return err
 	}
 
-	if err = s.module.DeleteModule(params, moduleObj, bizID, setIDS, nil); nil != err {
+	if err = s.module.DeleteModule(params, moduleObj, bizID, setIDS, nil, impact); nil != err {
 		blog.Errorf("[operation-set] failed to delete the modules, error info is %s", err.Error())
 		return params.Err.New(common.CCErrTopoSetDeleteFailed, err.Error())
 	}
 
 	// clear the sets
-	return s.inst.DeleteInst(params, obj, setCond, false)
+	return s.inst.DeleteInst(params, obj, setCond, false, impact)
 }
 
 func (s *set) FindSet(params types.ContextParams, obj model.Object, cond *metadata.QueryInput) (count int, results []inst.Inst, err error) {
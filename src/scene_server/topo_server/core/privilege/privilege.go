@@ -68,7 +68,7 @@ func (u *userGroupPermission) SetUserGroupPermission(supplierAccount, groupID st
 		return u.params.Err.New(rsp.Code, rsp.ErrMsg)
 	}
 
-	if nil == rsp.Data.Privilege || (0 == len(rsp.Data.Privilege.ModelConfig) && nil == rsp.Data.Privilege.SysConfig) {
+	if nil == rsp.Data.Privilege || (0 == len(rsp.Data.Privilege.ModelConfig) && nil == rsp.Data.Privilege.SysConfig && 0 == len(rsp.Data.Privilege.FieldConfig)) {
 		rsp, err := u.client.ObjectController().Privilege().CreateUserGroupPrivi(context.Background(), supplierAccount, groupID, u.params.Header, permission)
 		if nil != err {
 			blog.Errorf("[privilege] failed to request object controller, error info is %s", err.Error())
@@ -113,6 +113,7 @@ func (u *userGroupPermission) GetUserPermission(supplierAccount, userName string
 	gPrivilege := metadata.Gprivilege{
 		IsHostCrossBiz: false,
 		ModelConfig:    map[string]map[string][]string{},
+		FieldConfig:    map[string]map[string][]string{},
 	}
 
 	// get cross biz permission
@@ -184,6 +185,15 @@ func (u *userGroupPermission) GetUserPermission(supplierAccount, userName string
 
 		}
 
+		for objID, fields := range grpPrivilege.Data.Privilege.FieldConfig {
+			if _, exists := gPrivilege.FieldConfig[objID]; !exists {
+				gPrivilege.FieldConfig[objID] = map[string][]string{}
+			}
+			for propertyID, rights := range fields {
+				gPrivilege.FieldConfig[objID][propertyID] = util.RemoveDuplicatesAndEmpty(append(gPrivilege.FieldConfig[objID][propertyID], rights...))
+			}
+		}
+
 	} // end for
 
 	umodelCls := util.RemoveDuplicatesAndEmpty(modelCls)
@@ -17,6 +17,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
 	frtypes "configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/scene_server/topo_server/core/types"
@@ -109,3 +110,50 @@ func (s *topoService) SearchMainLineChildInstTopo(params types.ContextParams, pa
 
 	return s.core.AssociationOperation().SearchMainlineAssociationInstTopo(params, obj, instID)
 }
+
+// CreateAssociationType create a new association type between two objects
+func (s *topoService) CreateAssociationType(params types.ContextParams, pathParams, queryParams ParamsGetter, data frtypes.MapStr) (interface{}, error) {
+
+	asstType := &metadata.AssociationType{}
+	if err := data.MarshalJSONInto(asstType); nil != err {
+		blog.Errorf("[api-asst] failed to parse the data(%#v), error info is %s", data, err.Error())
+		return nil, params.Err.New(common.CCErrCommJSONUnmarshalFailed, err.Error())
+	}
+
+	return s.core.AssociationOperation().CreateAssociationType(params, asstType)
+}
+
+// SearchAssociationType search the association types
+func (s *topoService) SearchAssociationType(params types.ContextParams, pathParams, queryParams ParamsGetter, data frtypes.MapStr) (interface{}, error) {
+
+	cond := condition.CreateCondition()
+	cond.Parse(data)
+
+	return s.core.AssociationOperation().SearchAssociationType(params, cond)
+}
+
+// UpdateAssociationType update the name, descriptions and on delete behaviour of the association type
+func (s *topoService) UpdateAssociationType(params types.ContextParams, pathParams, queryParams ParamsGetter, data frtypes.MapStr) (interface{}, error) {
+
+	id, err := strconv.ParseInt(pathParams("id"), 10, 64)
+	if nil != err {
+		blog.Errorf("[api-asst] failed to parse the path params id(%s), error info is %s ", pathParams("id"), err.Error())
+		return nil, params.Err.Errorf(common.CCErrCommParamsIsInvalid, "id")
+	}
+
+	err = s.core.AssociationOperation().UpdateAssociationType(params, id, data)
+	return nil, err
+}
+
+// DeleteAssociationType delete the association type which is not used by any object association
+func (s *topoService) DeleteAssociationType(params types.ContextParams, pathParams, queryParams ParamsGetter, data frtypes.MapStr) (interface{}, error) {
+
+	id, err := strconv.ParseInt(pathParams("id"), 10, 64)
+	if nil != err {
+		blog.Errorf("[api-asst] failed to parse the path params id(%s), error info is %s ", pathParams("id"), err.Error())
+		return nil, params.Err.Errorf(common.CCErrCommParamsIsInvalid, "id")
+	}
+
+	err = s.core.AssociationOperation().DeleteAssociationType(params, id)
+	return nil, err
+}
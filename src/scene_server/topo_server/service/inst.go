@@ -23,6 +23,7 @@ import (
 	frtypes "configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	paraparse "configcenter/src/common/paraparse"
+	"configcenter/src/scene_server/topo_server/core/inst"
 	"configcenter/src/scene_server/topo_server/core/operation"
 	"configcenter/src/scene_server/topo_server/core/types"
 )
@@ -72,7 +73,12 @@ func (s *topoService) DeleteInsts(params types.ContextParams, pathParams, queryP
 		return nil, err
 	}
 
-	return nil, s.core.InstOperation().DeleteInstByInstID(params, obj, deleteCondition.Delete.InstID, true)
+	impact, err := s.core.InstOperation().CheckDeleteImpact(params, obj, deleteCondition.Delete.InstID, queryParams("cascade"))
+	if nil != err {
+		return nil, err
+	}
+
+	return nil, s.core.InstOperation().DeleteInstByInstID(params, obj, deleteCondition.Delete.InstID, true, impact)
 }
 
 // DeleteInst delete the inst
@@ -94,7 +100,12 @@ func (s *topoService) DeleteInst(params types.ContextParams, pathParams, queryPa
 		return nil, err
 	}
 
-	err = s.core.InstOperation().DeleteInstByInstID(params, obj, []int64{instID}, true)
+	impact, err := s.core.InstOperation().CheckDeleteImpact(params, obj, []int64{instID}, queryParams("cascade"))
+	if nil != err {
+		return nil, err
+	}
+
+	err = s.core.InstOperation().DeleteInstByInstID(params, obj, []int64{instID}, true, impact)
 	return nil, err
 }
 func (s *topoService) UpdateInsts(params types.ContextParams, pathParams, queryParams ParamsGetter, data frtypes.MapStr) (interface{}, error) {
@@ -194,6 +205,10 @@ func (s *topoService) SearchInsts(params types.ContextParams, pathParams, queryP
 		return nil, err
 	}
 
+	if err := s.filterReadableFields(params, obj.GetID(), instItems); nil != err {
+		return nil, err
+	}
+
 	result := frtypes.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
@@ -236,6 +251,10 @@ func (s *topoService) SearchInstAndAssociationDetail(params types.ContextParams,
 		return nil, err
 	}
 
+	if err := s.filterReadableFields(params, obj.GetID(), instItems); nil != err {
+		return nil, err
+	}
+
 	result := frtypes.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
@@ -274,6 +293,10 @@ func (s *topoService) SearchInstByObject(params types.ContextParams, pathParams,
 		return nil, err
 	}
 
+	if err := s.filterReadableFields(params, obj.GetID(), instItems); nil != err {
+		return nil, err
+	}
+
 	result := frtypes.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
@@ -300,6 +323,10 @@ func (s *topoService) SearchInstByAssociation(params types.ContextParams, pathPa
 		return nil, err
 	}
 
+	if err := s.filterReadableFields(params, obj.GetID(), instItems); nil != err {
+		return nil, err
+	}
+
 	result := frtypes.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
@@ -335,6 +362,10 @@ func (s *topoService) SearchInstByInstID(params types.ContextParams, pathParams,
 		return nil, err
 	}
 
+	if err := s.filterReadableFields(params, obj.GetID(), instItems); nil != err {
+		return nil, err
+	}
+
 	result := frtypes.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
@@ -404,3 +435,51 @@ func (s *topoService) SearchInstTopo(params types.ContextParams, pathParams, que
 	_, instItems, err := s.core.InstOperation().FindInstTopo(params, obj, instID, query)
 	return instItems, err
 }
+
+// SearchInstGraph search the insts and the associations reachable from the insts matching the condition
+func (s *topoService) SearchInstGraph(params types.ContextParams, pathParams, queryParams ParamsGetter, data frtypes.MapStr) (interface{}, error) {
+	// /inst/association/graph/search/owner/{owner_id}/object/{obj_id}
+
+	query := &metadata.InstGraphQuery{}
+	if err := data.MarshalJSONInto(query); nil != err {
+		blog.Errorf("[api-inst] failed to parse the graph query(%#v), error info is %s", data, err.Error())
+		return nil, params.Err.New(common.CCErrCommJSONUnmarshalFailed, err.Error())
+	}
+	query.ObjectID = pathParams("obj_id")
+
+	return s.core.InstOperation().FindInstGraph(params, query)
+}
+
+// SearchInstDeleteImpact search the records which depend on the insts, and whether they block the deletion
+func (s *topoService) SearchInstDeleteImpact(params types.ContextParams, pathParams, queryParams ParamsGetter, data frtypes.MapStr) (interface{}, error) {
+	// /inst/delete/impact/owner/{owner_id}/object/{obj_id}
+
+	obj, err := s.core.ObjectOperation().FindSingleObject(params, pathParams("obj_id"))
+	if nil != err {
+		blog.Errorf("[api-inst] failed to find the objects(%s), error info is %s", pathParams("obj_id"), err.Error())
+		return nil, err
+	}
+
+	deleteCondition := &operation.OpCondition{}
+	if err := data.MarshalJSONInto(deleteCondition); nil != err {
+		blog.Errorf("[api-inst] failed to parse the input data(%v), error info is %s", data, err.Error())
+		return nil, params.Err.New(common.CCErrCommJSONUnmarshalFailed, err.Error())
+	}
+
+	return s.core.InstOperation().AnalyzeDeleteImpact(params, obj, deleteCondition.Delete.InstID)
+}
+
+// filterReadableFields remove the fields which the request user can not read from the insts
+func (s *topoService) filterReadableFields(params types.ContextParams, objID string, insts []inst.Inst) error {
+
+	authorizer := s.getAuthorizer()
+	if nil == authorizer {
+		return nil
+	}
+
+	datas := make([]map[string]interface{}, 0, len(insts))
+	for _, item := range insts {
+		datas = append(datas, item.GetValues())
+	}
+	return authorizer.FilterReadableFields(params.Header, objID, datas...)
+}
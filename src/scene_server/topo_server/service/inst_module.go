@@ -76,7 +76,12 @@ func (s *topoService) DeleteModule(params types.ContextParams, pathParams, query
 		return nil, params.Err.Errorf(common.CCErrCommParamsNeedInt, "module id")
 	}
 
-	return nil, s.core.ModuleOperation().DeleteModule(params, obj, bizID, []int64{setID}, []int64{moduleID})
+	impact, err := s.core.InstOperation().CheckDeleteImpact(params, obj, []int64{moduleID}, queryParams("cascade"))
+	if nil != err {
+		return nil, err
+	}
+
+	return nil, s.core.ModuleOperation().DeleteModule(params, obj, bizID, []int64{setID}, []int64{moduleID}, impact)
 
 }
 
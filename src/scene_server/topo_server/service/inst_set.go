@@ -63,7 +63,12 @@ func (s *topoService) DeleteSets(params types.ContextParams, pathParams, queryPa
 		return nil, params.Err.New(common.CCErrCommParamsIsInvalid, err.Error())
 	}
 
-	return nil, s.core.SetOperation().DeleteSet(params, obj, bizID, cond.Delete.InstID)
+	impact, err := s.core.InstOperation().CheckDeleteImpact(params, obj, cond.Delete.InstID, queryParams("cascade"))
+	if nil != err {
+		return nil, err
+	}
+
+	return nil, s.core.SetOperation().DeleteSet(params, obj, bizID, cond.Delete.InstID, impact)
 }
 
 // DeleteSet delete the set
@@ -92,7 +97,12 @@ func (s *topoService) DeleteSet(params types.ContextParams, pathParams, queryPar
 		return nil, err
 	}
 
-	return nil, s.core.SetOperation().DeleteSet(params, obj, bizID, []int64{setID})
+	impact, err := s.core.InstOperation().CheckDeleteImpact(params, obj, []int64{setID}, queryParams("cascade"))
+	if nil != err {
+		return nil, err
+	}
+
+	return nil, s.core.SetOperation().DeleteSet(params, obj, bizID, []int64{setID}, impact)
 }
 
 // UpdateSet update the set
@@ -62,6 +62,14 @@ func (s *topoService) SetConfig(cfg options.Config, engin *backbone.Engine) {
 	s.engin = engin
 }
 
+// getAuthorizer the authorizer is nil until the engine is set
+func (s *topoService) getAuthorizer() *backbone.Authorizer {
+	if nil == s.engin {
+		return nil
+	}
+	return s.engin.Authorizer
+}
+
 // SetOperation set the operation
 func (s *topoService) SetOperation(operation core.Core, err errors.CCErrorIf, language language.CCLanguageIf) {
 
@@ -91,18 +99,25 @@ func (s *topoService) WebService() *restful.WebService {
 	innerActions := s.Actions()
 
 	for _, actionItem := range innerActions {
+		var route *restful.RouteBuilder
 		switch actionItem.Verb {
 		case http.MethodPost:
-			ws.Route(ws.POST(actionItem.Path).To(actionItem.Handler))
+			route = ws.POST(actionItem.Path)
 		case http.MethodDelete:
-			ws.Route(ws.DELETE(actionItem.Path).To(actionItem.Handler))
+			route = ws.DELETE(actionItem.Path)
 		case http.MethodPut:
-			ws.Route(ws.PUT(actionItem.Path).To(actionItem.Handler))
+			route = ws.PUT(actionItem.Path)
 		case http.MethodGet:
-			ws.Route(ws.GET(actionItem.Path).To(actionItem.Handler))
+			route = ws.GET(actionItem.Path)
 		default:
 			blog.Errorf(" the url (%s), the http method (%s) is not supported", actionItem.Path, actionItem.Verb)
+			continue
 		}
+
+		for _, filter := range actionItem.FilterHandler {
+			route = route.Filter(filter)
+		}
+		ws.Route(route.To(actionItem.Handler))
 	}
 
 	return ws
@@ -145,7 +160,12 @@ func (s *topoService) Actions() []*httpserver.Action {
 
 		func(act action) {
 
-			httpactions = append(httpactions, &httpserver.Action{Verb: act.Method, Path: act.Path, Handler: func(req *restful.Request, resp *restful.Response) {
+			var filters []restful.FilterFunction
+			if (backbone.PrivilegeRule{}) != act.Privilege {
+				filters = append(filters, backbone.PrivilegeFilter(s.getAuthorizer, act.Privilege))
+			}
+
+			httpactions = append(httpactions, &httpserver.Action{Verb: act.Method, Path: act.Path, FilterHandler: filters, Handler: func(req *restful.Request, resp *restful.Response) {
 
 				ownerID := util.GetActionOnwerID(req)
 				user := util.GetActionUser(req)
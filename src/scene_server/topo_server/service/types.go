@@ -13,6 +13,7 @@
 package service
 
 import (
+	"configcenter/src/common/backbone"
 	"configcenter/src/common/http/httpserver"
 	"configcenter/src/common/mapstr"
 	frtypes "configcenter/src/common/mapstr"
@@ -36,6 +37,8 @@ type action struct {
 	Path                       string
 	HandlerFunc                LogicFunc
 	HandlerParseOriginDataFunc ParseOriginDataFunc
+	// Privilege the privilege required by the action, the action is not restricted if it is empty
+	Privilege backbone.PrivilegeRule
 }
 
 // API the API interface
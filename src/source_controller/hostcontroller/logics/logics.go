@@ -151,6 +151,41 @@ func (lgc *Logics) AddSingleHostModuleRelation(ec *eventclient.EventContext, hos
 	return true, nil
 }
 
+//TransferHostModule move the hosts to the modules, the hosts leave all their modules in the app,
+//or only the default modules when it is increment
+func (lgc *Logics) TransferHostModule(ec *eventclient.EventContext, params *metadata.HostsModuleRelation, ownerID string) error {
+	var defaultModuleIDs []int64
+	if params.IsIncrement {
+		var err error
+		defaultModuleIDs, err = lgc.GetDefaultModuleIDs(params.ApplicationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, hostID := range params.HostID {
+		moduleIDs := defaultModuleIDs
+		if !params.IsIncrement {
+			var err error
+			moduleIDs, err = lgc.GetModuleIDsByHostID(common.KvMap{common.BKHostIDField: hostID, common.BKAppIDField: params.ApplicationID})
+			if err != nil {
+				return err
+			}
+		}
+		for _, moduleID := range moduleIDs {
+			if _, err := lgc.DelSingleHostModuleRelation(ec, hostID, moduleID, params.ApplicationID, ownerID); err != nil {
+				return fmt.Errorf("delete relation of host %d and module %d failed, err: %v", hostID, moduleID, err)
+			}
+		}
+		for _, moduleID := range params.ModuleID {
+			if _, err := lgc.AddSingleHostModuleRelation(ec, hostID, moduleID, params.ApplicationID, ownerID); err != nil {
+				return fmt.Errorf("add relation of host %d and module %d failed, err: %v", hostID, moduleID, err)
+			}
+		}
+	}
+	return nil
+}
+
 //GetDefaultModuleIDs get default module ids
 func (lgc *Logics) GetDefaultModuleIDs(appID int64) ([]int64, error) {
 	defaultModuleCond := make(map[string]interface{})
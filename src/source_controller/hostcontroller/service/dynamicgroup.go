@@ -0,0 +1,248 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/rs/xid"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/eventclient"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage"
+)
+
+// GetDynamicUserConfigs get the user configs which are dynamic groups, the configs of all
+// the suppliers are returned for the super owner, so that they can be evaluated on a schedule
+func (s *Service) GetDynamicUserConfigs(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.Core.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	condition := util.SetModOwner(common.KvMap{"dynamic": true}, ownerID)
+	result := make([]meta.UserConfigMeta, 0)
+	err := s.Instance.GetMutilByCondition(UserQueryCollection, nil, condition, &result, common.BKFieldID, 0, common.BKNoLimit)
+	if err != nil {
+		blog.Errorf("get dynamic user configs failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	resp.WriteEntity(meta.DynamicGroupsResult{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     result,
+	})
+}
+
+// GetUserConfigSnapshot get the hosts of the dynamic group at its last evaluation
+func (s *Service) GetUserConfigSnapshot(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.Core.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	id := req.PathParameter("id")
+	appID, err := strconv.ParseInt(req.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		blog.Errorf("get user config[%s] snapshot failed, invalid appid, err: %v", id, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField)})
+		return
+	}
+
+	params := util.SetModOwner(common.KvMap{"id": id, common.BKAppIDField: appID}, ownerID)
+	snapshot := meta.DynamicGroupSnapshot{}
+	err = s.Instance.GetOneByCondition(common.BKTableNameDynamicGroupSnap, nil, params, &snapshot)
+	if err != nil {
+		if s.Instance.IsNotFoundErr(err) {
+			resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+			return
+		}
+		blog.Errorf("get user config[%s] snapshot failed, err: %v", id, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	resp.WriteEntity(meta.DynamicGroupSnapshotResult{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     snapshot,
+	})
+}
+
+// UpdateUserConfigSnapshot save the hosts of the dynamic group evaluated, and send
+// the events of the hosts which joined or left the group since the last evaluation
+func (s *Service) UpdateUserConfigSnapshot(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.Core.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	id := req.PathParameter("id")
+	appID, err := strconv.ParseInt(req.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		blog.Errorf("update user config[%s] snapshot failed, invalid appid, err: %v", id, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField)})
+		return
+	}
+
+	data := new(meta.DynamicGroupSnapshotParams)
+	if err := json.NewDecoder(req.Request.Body).Decode(data); err != nil {
+		blog.Errorf("update user config[%s] snapshot failed, err: %v", id, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	params := util.SetModOwner(common.KvMap{"id": id, common.BKAppIDField: appID}, ownerID)
+	config := meta.UserConfigMeta{}
+	if err := s.Instance.GetOneByCondition(UserQueryCollection, nil, params, &config); err != nil {
+		if s.Instance.IsNotFoundErr(err) {
+			resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommNotFound)})
+			return
+		}
+		blog.Errorf("update user config[%s] snapshot failed, err: %v", id, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	var delta meta.DynamicGroupDelta
+	now := time.Now().UTC()
+	ec := eventclient.NewEventContextByReq(pheader, s.Cache)
+	ec.Begin()
+	err = s.Instance.WithTransaction(func(db storage.DI) error {
+		// the scheduled evaluation claims the group by setting the eval_time only if it is not changed
+		// since the evaluation began, the evaluations of the other host servers are skipped
+		evalCond := common.KvMap{}
+		for key, val := range params {
+			evalCond[key] = val
+		}
+		if data.Scheduled {
+			if nil == data.PreEvalTime {
+				evalCond["eval_time"] = common.KvMap{"$exists": false}
+			} else {
+				evalCond["eval_time"] = *data.PreEvalTime
+			}
+		}
+		evalToken := xid.New().String()
+		if err := db.UpdateByCondition(UserQueryCollection, common.KvMap{"eval_time": now, "eval_token": evalToken}, evalCond); err != nil {
+			return err
+		}
+		if data.Scheduled {
+			claimed := map[string]interface{}{}
+			if err := db.GetOneByCondition(UserQueryCollection, []string{"eval_token"}, params, &claimed); err != nil {
+				return err
+			}
+			if evalToken != claimed["eval_token"] {
+				delta = meta.DynamicGroupDelta{Joined: []int64{}, Left: []int64{}, Skipped: true}
+				return nil
+			}
+		}
+
+		pre := meta.DynamicGroupSnapshot{}
+		exist := true
+		if err := db.GetOneByCondition(common.BKTableNameDynamicGroupSnap, nil, params, &pre); err != nil {
+			if !db.IsNotFoundErr(err) {
+				return err
+			}
+			exist = false
+		}
+
+		delta = diffHostIDs(pre.HostIDs, data.HostIDs)
+		snapshot := meta.DynamicGroupSnapshot{
+			ID:       id,
+			AppID:    appID,
+			HostIDs:  sortedHostIDs(data.HostIDs),
+			EvalTime: now,
+			OwnerID:  config.OwnerID,
+		}
+		if exist {
+			if err := db.UpdateByCondition(common.BKTableNameDynamicGroupSnap, snapshot, params); err != nil {
+				return err
+			}
+		} else if _, err := db.Insert(common.BKTableNameDynamicGroupSnap, snapshot); err != nil {
+			return err
+		}
+
+		for _, hostID := range delta.Joined {
+			member := meta.DynamicGroupMember{ID: id, Name: config.Name, AppID: appID, HostID: hostID}
+			if err := ec.InsertEvent(meta.EventTypeDynamicGroup, meta.EventTypeDynamicGroup, meta.EventActionJoin, member, nil); err != nil {
+				blog.Errorf("host %d joined dynamic group %s, but create event failed, err: %v", hostID, id, err)
+			}
+		}
+		for _, hostID := range delta.Left {
+			member := meta.DynamicGroupMember{ID: id, Name: config.Name, AppID: appID, HostID: hostID}
+			if err := ec.InsertEvent(meta.EventTypeDynamicGroup, meta.EventTypeDynamicGroup, meta.EventActionLeave, nil, member); err != nil {
+				blog.Errorf("host %d left dynamic group %s, but create event failed, err: %v", hostID, id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ec.Rollback()
+		blog.Errorf("update user config[%s] snapshot failed, err: %v", id, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBUpdateFailed)})
+		return
+	}
+	if err := ec.Commit(); err != nil {
+		blog.Errorf("update user config[%s] snapshot success, but send events failed, err: %v", id, err)
+	}
+
+	resp.WriteEntity(meta.DynamicGroupDeltaResult{
+		BaseResp: meta.SuccessBaseResp,
+		Data:     delta,
+	})
+}
+
+// diffHostIDs get the hosts in cur but not in pre as joined, and the hosts in pre but not in cur as left
+func diffHostIDs(pre, cur []int64) meta.DynamicGroupDelta {
+	preSet := make(map[int64]bool, len(pre))
+	for _, hostID := range pre {
+		preSet[hostID] = true
+	}
+	curSet := make(map[int64]bool, len(cur))
+	for _, hostID := range cur {
+		curSet[hostID] = true
+	}
+
+	delta := meta.DynamicGroupDelta{Joined: []int64{}, Left: []int64{}}
+	for hostID := range curSet {
+		if !preSet[hostID] {
+			delta.Joined = append(delta.Joined, hostID)
+		}
+	}
+	for hostID := range preSet {
+		if !curSet[hostID] {
+			delta.Left = append(delta.Left, hostID)
+		}
+	}
+	delta.Joined = sortedHostIDs(delta.Joined)
+	delta.Left = sortedHostIDs(delta.Left)
+	return delta
+}
+
+// sortedHostIDs sort the host ids and remove the duplicated ones
+func sortedHostIDs(hostIDs []int64) []int64 {
+	sorted := append([]int64{}, hostIDs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	unique := sorted[:0]
+	for idx, hostID := range sorted {
+		if 0 == idx || hostID != sorted[idx-1] {
+			unique = append(unique, hostID)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,126 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common/http/resttest"
+	meta "configcenter/src/common/metadata"
+)
+
+func TestDynamicGroupSnapshot(t *testing.T) {
+	container := newTestContainer()
+
+	created := meta.IDResult{}
+	query := meta.AddConfigQuery{AppID: 2, Name: "group1", Info: "{}", CreateUser: "admin", Dynamic: true, EvalInterval: 60}
+	code := resttest.DoRequest(t, container, http.MethodPost, "/host/v3/userapi", query, &created)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, created.Result)
+
+	static := meta.AddConfigQuery{AppID: 2, Name: "group2", Info: "{}", CreateUser: "admin"}
+	code = resttest.DoRequest(t, container, http.MethodPost, "/host/v3/userapi", static, &meta.IDResult{})
+	require.Equal(t, http.StatusOK, code)
+
+	groups := meta.DynamicGroupsResult{}
+	code = resttest.DoRequest(t, container, http.MethodGet, "/host/v3/userapi/dynamic", nil, &groups)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, groups.Data, 1)
+	assert.Equal(t, created.Data.ID, groups.Data[0].ID)
+	assert.True(t, groups.Data[0].IsDynamic())
+
+	snapshotURL := fmt.Sprintf("/host/v3/userapi/snapshot/2/%s", created.Data.ID)
+	delta := meta.DynamicGroupDeltaResult{}
+	code = resttest.DoRequest(t, container, http.MethodPut, snapshotURL, meta.DynamicGroupSnapshotParams{HostIDs: []int64{2, 1, 2}}, &delta)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{1, 2}, delta.Data.Joined)
+	assert.Empty(t, delta.Data.Left)
+
+	delta = meta.DynamicGroupDeltaResult{}
+	code = resttest.DoRequest(t, container, http.MethodPut, snapshotURL, meta.DynamicGroupSnapshotParams{HostIDs: []int64{3, 2}}, &delta)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{3}, delta.Data.Joined)
+	assert.Equal(t, []int64{1}, delta.Data.Left)
+
+	snapshot := meta.DynamicGroupSnapshotResult{}
+	code = resttest.DoRequest(t, container, http.MethodGet, snapshotURL, nil, &snapshot)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{2, 3}, snapshot.Data.HostIDs)
+	assert.False(t, snapshot.Data.EvalTime.IsZero())
+
+	code = resttest.DoRequest(t, container, http.MethodDelete, fmt.Sprintf("/host/v3/userapi/2/%s", created.Data.ID), nil, &meta.Response{})
+	require.Equal(t, http.StatusOK, code)
+
+	code = resttest.DoRequest(t, container, http.MethodGet, snapshotURL, nil, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestScheduledDynamicGroupSnapshot(t *testing.T) {
+	container := newTestContainer()
+
+	created := meta.IDResult{}
+	query := meta.AddConfigQuery{AppID: 2, Name: "group1", Info: "{}", CreateUser: "admin", Dynamic: true}
+	code := resttest.DoRequest(t, container, http.MethodPost, "/host/v3/userapi", query, &created)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, created.Result)
+
+	// the host servers evaluate the group never evaluated at the same time, only the first one is saved
+	snapshotURL := fmt.Sprintf("/host/v3/userapi/snapshot/2/%s", created.Data.ID)
+	delta := meta.DynamicGroupDeltaResult{}
+	code = resttest.DoRequest(t, container, http.MethodPut, snapshotURL, meta.DynamicGroupSnapshotParams{HostIDs: []int64{1}, Scheduled: true}, &delta)
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, delta.Data.Skipped)
+	assert.Equal(t, []int64{1}, delta.Data.Joined)
+
+	delta = meta.DynamicGroupDeltaResult{}
+	code = resttest.DoRequest(t, container, http.MethodPut, snapshotURL, meta.DynamicGroupSnapshotParams{HostIDs: []int64{1, 2}, Scheduled: true}, &delta)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, delta.Data.Skipped)
+	assert.Empty(t, delta.Data.Joined)
+
+	snapshot := meta.DynamicGroupSnapshotResult{}
+	code = resttest.DoRequest(t, container, http.MethodGet, snapshotURL, nil, &snapshot)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{1}, snapshot.Data.HostIDs)
+
+	// the next evaluation begins at the eval_time saved
+	evalTime := snapshot.Data.EvalTime
+	stale := evalTime.Add(-time.Minute)
+	delta = meta.DynamicGroupDeltaResult{}
+	code = resttest.DoRequest(t, container, http.MethodPut, snapshotURL, meta.DynamicGroupSnapshotParams{HostIDs: []int64{3}, Scheduled: true, PreEvalTime: &stale}, &delta)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, delta.Data.Skipped)
+
+	delta = meta.DynamicGroupDeltaResult{}
+	code = resttest.DoRequest(t, container, http.MethodPut, snapshotURL, meta.DynamicGroupSnapshotParams{HostIDs: []int64{3}, Scheduled: true, PreEvalTime: &evalTime}, &delta)
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, delta.Data.Skipped)
+	assert.Equal(t, []int64{3}, delta.Data.Joined)
+	assert.Equal(t, []int64{1}, delta.Data.Left)
+}
+
+func TestDiffHostIDs(t *testing.T) {
+	delta := diffHostIDs(nil, []int64{3, 1})
+	assert.Equal(t, []int64{1, 3}, delta.Joined)
+	assert.Empty(t, delta.Left)
+
+	delta = diffHostIDs([]int64{1, 2, 3}, []int64{4, 2})
+	assert.Equal(t, []int64{4}, delta.Joined)
+	assert.Equal(t, []int64{1, 3}, delta.Left)
+}
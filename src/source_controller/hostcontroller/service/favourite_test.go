@@ -0,0 +1,62 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common/http/resttest"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+func newTestContainer() *restful.Container {
+	s := &Service{
+		Core:     resttest.NewEngine(),
+		Instance: memclient.NewMemCli("cmdb"),
+	}
+	return resttest.NewContainer(s.WebService())
+}
+
+func TestHostFavourite(t *testing.T) {
+	container := newTestContainer()
+
+	created := meta.IDResult{}
+	code := resttest.DoRequest(t, container, http.MethodPost, "/host/v3/hosts/favorites/admin", meta.FavouriteParms{Name: "fav1", Info: "{}"}, &created)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, created.Result)
+	require.NotEmpty(t, created.Data.ID)
+
+	duplicate := meta.Response{}
+	code = resttest.DoRequest(t, container, http.MethodPost, "/host/v3/hosts/favorites/admin", meta.FavouriteParms{Name: "fav1"}, &duplicate)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code = resttest.DoRequest(t, container, http.MethodPost, "/host/v3/hosts/favorites/admin", meta.FavouriteParms{Name: "fav2"}, &meta.IDResult{})
+	require.Equal(t, http.StatusOK, code)
+
+	updated := meta.Response{}
+	code = resttest.DoRequest(t, container, http.MethodPut, "/host/v3/hosts/favorites/admin/"+created.Data.ID, meta.FavouriteParms{Name: "fav2"}, &updated)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	searched := meta.GetHostFavoriteResult{}
+	code = resttest.DoRequest(t, container, http.MethodPost, "/host/v3/hosts/favorites/search/admin", meta.ObjQueryInput{Sort: "name"}, &searched)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, 2, searched.Data.Count)
+	assert.Equal(t, "fav1", searched.Data.Info[0]["name"])
+	assert.Equal(t, "fav2", searched.Data.Info[1]["name"])
+}
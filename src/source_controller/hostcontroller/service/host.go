@@ -27,6 +27,8 @@ import (
 	"configcenter/src/common/eventclient"
 	meta "configcenter/src/common/metadata"
 	"configcenter/src/common/util"
+	"configcenter/src/source_controller/hostcontroller/logics"
+	"configcenter/src/storage"
 )
 
 const (
@@ -221,6 +223,39 @@ func (s *Service) AddModuleHostConfig(req *restful.Request, resp *restful.Respon
 	resp.WriteEntity(meta.NewSuccessResp(nil))
 }
 
+// TransferHostModule move the hosts to the modules in one transaction,
+// so that no host is left without module when the transfer fails halfway
+func (s *Service) TransferHostModule(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.Core.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
+	ownerID := util.GetOwnerID(pheader)
+
+	params := new(meta.HostsModuleRelation)
+	if err := json.NewDecoder(req.Request.Body).Decode(params); err != nil {
+		blog.Errorf("transfer host module failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	ec := eventclient.NewEventContextByReq(pheader, s.Cache)
+	ec.Begin()
+	err := s.Instance.WithTransaction(func(db storage.DI) error {
+		lgc := logics.Logics{Instance: db}
+		return lgc.TransferHostModule(ec, params, ownerID)
+	})
+	if err != nil {
+		ec.Rollback()
+		blog.Errorf("transfer host module failed, params: %+v, err: %v", params, err)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrHostTransferModule)})
+		return
+	}
+	if err := ec.Commit(); err != nil {
+		blog.Errorf("transfer host module success, but send events failed, err: %v", err)
+	}
+
+	resp.WriteEntity(meta.NewSuccessResp(nil))
+}
+
 func (s *Service) DelModuleHostConfig(req *restful.Request, resp *restful.Response) {
 	pheader := req.Request.Header
 	defErr := s.Core.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pheader))
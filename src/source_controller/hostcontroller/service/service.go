@@ -56,6 +56,7 @@ func (s *Service) WebService() *restful.WebService {
 	ws.Route(ws.POST("/meta/hosts/modules/search").To(s.GetHostModulesIDs))
 	ws.Route(ws.POST("/meta/hosts/modules").To(s.AddModuleHostConfig))
 	ws.Route(ws.DELETE("/meta/hosts/modules").To(s.DelModuleHostConfig))
+	ws.Route(ws.POST("/meta/hosts/modules/transfer").To(s.TransferHostModule))
 	ws.Route(ws.DELETE("/meta/hosts/defaultmodules").To(s.DelDefaultModuleHostConfig))
 	ws.Route(ws.PUT("/meta/hosts/resource").To(s.MoveHost2ResourcePool))
 	ws.Route(ws.POST("/meta/hosts/assign").To(s.AssignHostToApp))
@@ -65,6 +66,9 @@ func (s *Service) WebService() *restful.WebService {
 	ws.Route(ws.DELETE("/userapi/{bk_biz_id}/{id}").To(s.DeleteUserConfig))
 	ws.Route(ws.POST("/userapi/search").To(s.GetUserConfig))
 	ws.Route(ws.GET("/userapi/detail/{bk_biz_id}/{id}").To(s.UserConfigDetail))
+	ws.Route(ws.GET("/userapi/dynamic").To(s.GetDynamicUserConfigs))
+	ws.Route(ws.GET("/userapi/snapshot/{bk_biz_id}/{id}").To(s.GetUserConfigSnapshot))
+	ws.Route(ws.PUT("/userapi/snapshot/{bk_biz_id}/{id}").To(s.UpdateUserConfigSnapshot))
 	ws.Route(ws.POST("/usercustom/{bk_user}").To(s.AddUserCustom))
 	ws.Route(ws.PUT("/usercustom/{bk_user}/{id}").To(s.UpdateUserCustomByID))
 	ws.Route(ws.POST("/usercustom/user/search/{bk_user}").To(s.GetUserCustomByUser))
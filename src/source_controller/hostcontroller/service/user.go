@@ -23,6 +23,7 @@ import (
 	"configcenter/src/common/blog"
 	meta "configcenter/src/common/metadata"
 	"configcenter/src/common/util"
+	"configcenter/src/storage"
 	"github.com/emicklei/go-restful"
 	"github.com/rs/xid"
 )
@@ -82,6 +83,10 @@ func (s *Service) AddUserConfig(req *restful.Request, resp *restful.Response) {
 		ModifyUser: addQuery.CreateUser,
 		UpdateTime: time.Now().UTC(),
 	}
+	if addQuery.Dynamic {
+		userQuery.Dynamic = &addQuery.Dynamic
+		userQuery.EvalInterval = &addQuery.EvalInterval
+	}
 
 	_, err = s.Instance.Insert(UserQueryCollection, userQuery)
 	if err != nil {
@@ -146,6 +151,8 @@ func (s *Service) UpdateUserConfig(req *restful.Request, resp *restful.Response)
 		}
 	}
 
+	// the evaluation time is only updated by the evaluation
+	data.EvalTime = nil
 	data.UpdateTime = time.Now().UTC()
 	data.ModifyUser = util.GetUser(req.Request.Header)
 	data.AppID = appID
@@ -187,7 +194,12 @@ func (s *Service) DeleteUserConfig(req *restful.Request, resp *restful.Response)
 		return
 	}
 
-	err = s.Instance.DelByCondition(UserQueryCollection, params)
+	err = s.Instance.WithTransaction(func(db storage.DI) error {
+		if err := db.DelByCondition(UserQueryCollection, params); err != nil {
+			return err
+		}
+		return db.DelByCondition(common.BKTableNameDynamicGroupSnap, params)
+	})
 	if nil != err {
 		blog.Error("delete user api fail, error information is %s, params:%v", err.Error(), params)
 		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommDBDeleteFailed)})
@@ -0,0 +1,181 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// CreateAssociationType create the association type
+func (cli *Service) CreateAssociationType(req *restful.Request, resp *restful.Response) {
+
+	language := util.GetActionLanguage(req)
+	ownerID := util.GetOwnerID(req.Request.Header)
+	defErr := cli.Core.CCErr.CreateDefaultCCErrorIf(language)
+
+	value, err := ioutil.ReadAll(req.Request.Body)
+	if err != nil {
+		blog.Errorf("read http request body failed, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommHTTPReadBodyFailed, err.Error())})
+		return
+	}
+
+	asstType := &meta.AssociationType{}
+	if err = json.Unmarshal(value, asstType); nil != err {
+		blog.Errorf("fail to unmarshal json, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommJSONUnmarshalFailed, err.Error())})
+		return
+	}
+	if err = asstType.Validate(); nil != err {
+		blog.Errorf("create association type failed, the field %s is invalid", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, err.Error())})
+		return
+	}
+
+	cond := util.SetModOwner(map[string]interface{}{common.BKAsstTypeIDField: asstType.AsstTypeID}, ownerID)
+	cnt, err := cli.Instance.GetCntByCondition(common.BKTableNameAsstType, cond)
+	if nil != err {
+		blog.Errorf("failed to count the association type by condition(%#v), error is %s", cond, err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrObjectDBOpErrno, err.Error())})
+		return
+	}
+	if 0 != cnt {
+		blog.Errorf("the association type %s is duplicated", asstType.AsstTypeID)
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommDuplicateItem, asstType.AsstTypeID)})
+		return
+	}
+
+	id, err := cli.Instance.GetIncID(common.BKTableNameAsstType)
+	if err != nil {
+		blog.Errorf("failed to get id, error info is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrObjectDBOpErrno, err.Error())})
+		return
+	}
+
+	asstType.ID = id
+	asstType.OwnerID = ownerID
+	if 0 == len(asstType.Creator) {
+		asstType.Creator = util.GetUser(req.Request.Header)
+	}
+	if _, err = cli.Instance.Insert(common.BKTableNameAsstType, asstType); nil != err {
+		blog.Errorf("create association type failed, error:%s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrObjectDBOpErrno, err.Error())})
+		return
+	}
+
+	resp.WriteEntity(meta.Response{BaseResp: meta.SuccessBaseResp, Data: asstType})
+}
+
+// SelectAssociationTypes search the association types by condition
+func (cli *Service) SelectAssociationTypes(req *restful.Request, resp *restful.Response) {
+
+	language := util.GetActionLanguage(req)
+	ownerID := util.GetOwnerID(req.Request.Header)
+	defErr := cli.Core.CCErr.CreateDefaultCCErrorIf(language)
+
+	js, err := simplejson.NewFromReader(req.Request.Body)
+	if err != nil {
+		blog.Errorf("read request body failed, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommHTTPReadBodyFailed, err.Error())})
+		return
+	}
+
+	selector, err := js.Map()
+	if nil != err {
+		blog.Errorf("fail to unmarshal json, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommJSONUnmarshalFailed, err.Error())})
+		return
+	}
+	selector = util.SetModOwner(selector, ownerID)
+
+	results := make([]meta.AssociationType, 0)
+	if err = cli.Instance.GetMutilByCondition(common.BKTableNameAsstType, nil, selector, &results, common.BKAsstTypeIDField, 0, common.BKNoLimit); nil != err && !cli.Instance.IsNotFoundErr(err) {
+		blog.Errorf("select association types failed, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrObjectDBOpErrno, err.Error())})
+		return
+	}
+
+	resp.WriteEntity(meta.Response{BaseResp: meta.SuccessBaseResp, Data: results})
+}
+
+// UpdateAssociationType update the association type by id
+func (cli *Service) UpdateAssociationType(req *restful.Request, resp *restful.Response) {
+
+	language := util.GetActionLanguage(req)
+	ownerID := util.GetOwnerID(req.Request.Header)
+	defErr := cli.Core.CCErr.CreateDefaultCCErrorIf(language)
+
+	id, err := strconv.ParseInt(req.PathParameter("id"), 10, 64)
+	if nil != err {
+		blog.Errorf("failed to get params, error info is %s ", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommParamsInvalid, err.Error())})
+		return
+	}
+
+	js, err := simplejson.NewFromReader(req.Request.Body)
+	if err != nil {
+		blog.Errorf("read http request body failed, error:%s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommHTTPReadBodyFailed, err.Error())})
+		return
+	}
+	data, err := js.Map()
+	if nil != err {
+		blog.Errorf("unmarshal json failed, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommJSONUnmarshalFailed, err.Error())})
+		return
+	}
+
+	cond := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, ownerID)
+	if err = cli.Instance.UpdateByCondition(common.BKTableNameAsstType, data, cond); nil != err {
+		blog.Errorf("fail update association type by condition, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrObjectDBOpErrno, err.Error())})
+		return
+	}
+
+	resp.WriteEntity(meta.Response{BaseResp: meta.SuccessBaseResp})
+}
+
+// DeleteAssociationType delete the association type by id
+func (cli *Service) DeleteAssociationType(req *restful.Request, resp *restful.Response) {
+
+	language := util.GetActionLanguage(req)
+	ownerID := util.GetOwnerID(req.Request.Header)
+	defErr := cli.Core.CCErr.CreateDefaultCCErrorIf(language)
+
+	id, err := strconv.ParseInt(req.PathParameter("id"), 10, 64)
+	if nil != err {
+		blog.Errorf("failed to get params, error info is %s ", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrCommParamsInvalid, err.Error())})
+		return
+	}
+
+	cond := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, ownerID)
+	if err = cli.Instance.DelByCondition(common.BKTableNameAsstType, cond); nil != err && !cli.Instance.IsNotFoundErr(err) {
+		blog.Errorf("fail to delete association type by id, error information is %s", err.Error())
+		resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.New(common.CCErrObjectDBOpErrno, err.Error())})
+		return
+	}
+
+	resp.WriteEntity(meta.Response{BaseResp: meta.SuccessBaseResp})
+}
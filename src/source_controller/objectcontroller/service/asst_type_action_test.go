@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/common"
+	"configcenter/src/common/http/resttest"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/storage/memclient"
+)
+
+func newTestContainer() *restful.Container {
+	s := &Service{
+		Core:     resttest.NewEngine(),
+		Instance: memclient.NewMemCli("cmdb"),
+	}
+	return resttest.NewContainer(s.WebService())
+}
+
+func TestAssociationType(t *testing.T) {
+	container := newTestContainer()
+
+	invalid := meta.Response{}
+	code := resttest.DoRequest(t, container, http.MethodPost, "/object/v3/meta/asst_type", meta.AssociationType{AsstTypeID: "runs_on", ObjectID: "app", AsstObjID: "host", Mapping: "2:2"}, &invalid)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	created := struct {
+		meta.BaseResp
+		Data meta.AssociationType `json:"data"`
+	}{}
+	code = resttest.DoRequest(t, container, http.MethodPost, "/object/v3/meta/asst_type", meta.AssociationType{AsstTypeID: "runs_on", ObjectID: "app", AsstObjID: "host"}, &created)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, created.Result)
+	assert.NotZero(t, created.Data.ID)
+	assert.Equal(t, meta.AssociationMappingManyToMany, created.Data.Mapping)
+	assert.Equal(t, meta.AssociationOnDeleteRestrict, created.Data.OnDelete)
+	assert.Equal(t, "admin", created.Data.Creator)
+
+	code = resttest.DoRequest(t, container, http.MethodPost, "/object/v3/meta/asst_type", meta.AssociationType{AsstTypeID: "runs_on", ObjectID: "app", AsstObjID: "set"}, &meta.Response{})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	idURL := "/object/v3/meta/asst_type/" + strconv.FormatInt(created.Data.ID, 10)
+	code = resttest.DoRequest(t, container, http.MethodPut, idURL, map[string]interface{}{"on_delete": meta.AssociationOnDeleteUnlink}, &meta.Response{})
+	require.Equal(t, http.StatusOK, code)
+
+	searched := meta.QueryAssociationTypeResult{}
+	code = resttest.DoRequest(t, container, http.MethodPost, "/object/v3/meta/asst_types", map[string]interface{}{common.BKObjIDField: "app"}, &searched)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, searched.Data, 1)
+	assert.Equal(t, meta.AssociationOnDeleteUnlink, searched.Data[0].OnDelete)
+
+	code = resttest.DoRequest(t, container, http.MethodDelete, idURL, nil, &meta.Response{})
+	require.Equal(t, http.StatusOK, code)
+
+	searched = meta.QueryAssociationTypeResult{}
+	code = resttest.DoRequest(t, container, http.MethodPost, "/object/v3/meta/asst_types", map[string]interface{}{}, &searched)
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, searched.Data)
+}
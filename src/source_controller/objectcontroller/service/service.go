@@ -59,6 +59,11 @@ func (s *Service) WebService() *restful.WebService {
 	ws.Route(ws.POST("/meta/objectasst").To(s.CreateObjectAssociation))
 	ws.Route(ws.PUT("/meta/objectasst/{id}").To(s.UpdateObjectAssociation))
 
+	ws.Route(ws.POST("/meta/asst_types").To(s.SelectAssociationTypes))
+	ws.Route(ws.DELETE("/meta/asst_type/{id}").To(s.DeleteAssociationType))
+	ws.Route(ws.POST("/meta/asst_type").To(s.CreateAssociationType))
+	ws.Route(ws.PUT("/meta/asst_type/{id}").To(s.UpdateAssociationType))
+
 	ws.Route(ws.POST("/meta/objectatt/{id}").To(s.SelectObjectAttByID))
 	ws.Route(ws.POST("/meta/objectatts").To(s.SelectObjectAttWithParams))
 	ws.Route(ws.DELETE("/meta/objectatt/{id}").To(s.DeleteObjectAttByID))
@@ -0,0 +1,419 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memclient
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// match returns whether the document matches the mongodb style condition
+func match(doc bson.M, cond bson.M) (bool, error) {
+	for key, expect := range cond {
+		var matched bool
+		var err error
+		switch key {
+		case "$or", "$and", "$nor":
+			matched, err = matchLogic(doc, key, expect)
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("unsupported operator %s", key)
+			}
+			val, exists := getPath(doc, key)
+			matched, err = matchField(val, exists, expect)
+		}
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchLogic(doc bson.M, operator string, expect interface{}) (bool, error) {
+	conds, ok := expect.([]interface{})
+	if !ok || len(conds) <= 0 {
+		return false, fmt.Errorf("%s needs a non-empty array", operator)
+	}
+	for _, item := range conds {
+		cond, ok := item.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("%s needs an array of conditions", operator)
+		}
+		matched, err := match(doc, cond)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case operator == "$or" && matched:
+			return true, nil
+		case operator == "$and" && !matched:
+			return false, nil
+		case operator == "$nor" && matched:
+			return false, nil
+		}
+	}
+	return operator != "$or", nil
+}
+
+// matchField check the value of the field, expect is either a value or an operator document
+func matchField(val interface{}, exists bool, expect interface{}) (bool, error) {
+	switch expect := expect.(type) {
+	case bson.RegEx:
+		return matchRegex(val, expect.Pattern, expect.Options)
+	case bson.M:
+		if isOperatorDoc(expect) {
+			return matchOperators(val, exists, expect)
+		}
+	}
+	return matchEqual(val, exists, expect), nil
+}
+
+func isOperatorDoc(doc bson.M) bool {
+	if len(doc) <= 0 {
+		return false
+	}
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func matchOperators(val interface{}, exists bool, ops bson.M) (bool, error) {
+	for op, arg := range ops {
+		var matched bool
+		var err error
+		switch op {
+		case "$eq":
+			matched = matchEqual(val, exists, arg)
+		case "$ne":
+			matched = !matchEqual(val, exists, arg)
+		case "$in":
+			matched, err = matchIn(val, exists, arg)
+		case "$nin":
+			matched, err = matchIn(val, exists, arg)
+			matched = !matched
+		case "$gt", "$gte", "$lt", "$lte":
+			matched = exists && anyElement(val, func(item interface{}) bool {
+				ret, ok := compare(item, arg)
+				if !ok {
+					return false
+				}
+				switch op {
+				case "$gt":
+					return ret > 0
+				case "$gte":
+					return ret >= 0
+				case "$lt":
+					return ret < 0
+				}
+				return ret <= 0
+			})
+		case "$regex":
+			options, _ := ops["$options"].(string)
+			switch pattern := arg.(type) {
+			case string:
+				matched, err = matchRegex(val, pattern, options)
+			case bson.RegEx:
+				matched, err = matchRegex(val, pattern.Pattern, pattern.Options+options)
+			default:
+				err = fmt.Errorf("$regex needs a string, got %v", arg)
+			}
+		case "$options":
+			continue
+		case "$exists":
+			want, ok := arg.(bool)
+			if !ok {
+				want = !isZero(arg)
+			}
+			matched = exists == want
+		case "$not":
+			matched, err = matchField(val, exists, arg)
+			matched = !matched
+		default:
+			err = fmt.Errorf("unsupported operator %s", op)
+		}
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchEqual like mongodb, null matches the missing field, and an array matches when any of its element equals
+func matchEqual(val interface{}, exists bool, expect interface{}) bool {
+	if expect == nil {
+		return !exists || val == nil
+	}
+	if !exists {
+		return false
+	}
+	if valueEqual(val, expect) {
+		return true
+	}
+	if items, ok := val.([]interface{}); ok {
+		for _, item := range items {
+			if valueEqual(item, expect) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchIn(val interface{}, exists bool, arg interface{}) (bool, error) {
+	items, ok := arg.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("$in needs an array, got %v", arg)
+	}
+	for _, item := range items {
+		if regex, ok := item.(bson.RegEx); ok {
+			matched, err := matchRegex(val, regex.Pattern, regex.Options)
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				return true, nil
+			}
+			continue
+		}
+		if matchEqual(val, exists, item) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchRegex(val interface{}, pattern, options string) (bool, error) {
+	flags := ""
+	for _, option := range options {
+		if strings.ContainsRune("ims", option) {
+			flags += string(option)
+		}
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return anyElement(val, func(item interface{}) bool {
+		str, ok := item.(string)
+		return ok && regex.MatchString(str)
+	}), nil
+}
+
+// anyElement check the value, or each element when the value is an array
+func anyElement(val interface{}, check func(item interface{}) bool) bool {
+	if items, ok := val.([]interface{}); ok {
+		for _, item := range items {
+			if check(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return check(val)
+}
+
+func valueEqual(a, b interface{}) bool {
+	if ret, ok := compare(a, b); ok {
+		return ret == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare compares two numbers, strings or times, the ok is false when they are not comparable
+func compare(a, b interface{}) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		return compareFloat(fa, fb), true
+	}
+	switch va := a.(type) {
+	case string:
+		vb, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(va, vb), true
+	case time.Time:
+		vb, ok := b.(time.Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case va.Before(vb):
+			return -1, true
+		case va.After(vb):
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		vb, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		if va == vb {
+			return 0, true
+		}
+		if !va {
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// sortCompare compares any two values, the values of different types are ordered by their type like mongodb
+func sortCompare(a, b interface{}) int {
+	if ret, ok := compare(a, b); ok {
+		return ret
+	}
+	ra, rb := typeRank(a), typeRank(b)
+	switch {
+	case ra < rb:
+		return -1
+	case ra > rb:
+		return 1
+	}
+	return 0
+}
+
+func typeRank(val interface{}) int {
+	if _, ok := toFloat(val); ok {
+		return 1
+	}
+	switch val.(type) {
+	case nil:
+		return 0
+	case string:
+		return 2
+	case bson.M:
+		return 3
+	case []interface{}:
+		return 4
+	case bool:
+		return 5
+	case time.Time:
+		return 6
+	}
+	return 7
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int, int8, int16, int32, int64:
+		return float64(reflect.ValueOf(v).Int()), true
+	case uint, uint8, uint16, uint32, uint64:
+		return float64(reflect.ValueOf(v).Uint()), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func isZero(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	if f, ok := toFloat(val); ok {
+		return f == 0
+	}
+	return false
+}
+
+// getPath returns the value of the dotted path like "a.b.c"
+func getPath(doc bson.M, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	var current interface{} = doc
+	for _, key := range keys {
+		sub, ok := current.(bson.M)
+		if !ok {
+			return nil, false
+		}
+		current, ok = sub[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPath set the value of the dotted path, the missing parents are created
+func setPath(doc bson.M, path string, val interface{}) {
+	keys := strings.Split(path, ".")
+	current := doc
+	for _, key := range keys[:len(keys)-1] {
+		sub, ok := current[key].(bson.M)
+		if !ok {
+			sub = bson.M{}
+			current[key] = sub
+		}
+		current = sub
+	}
+	current[keys[len(keys)-1]] = val
+}
+
+func unsetPath(doc bson.M, path string) {
+	keys := strings.Split(path, ".")
+	current := doc
+	for _, key := range keys[:len(keys)-1] {
+		sub, ok := current[key].(bson.M)
+		if !ok {
+			return
+		}
+		current = sub
+	}
+	delete(current, keys[len(keys)-1])
+}
+
+// copyValue deep copy the documents and arrays, so that the saved documents are never shared
+func copyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case bson.M:
+		doc := bson.M{}
+		for key, item := range v {
+			doc[key] = copyValue(item)
+		}
+		return doc
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = copyValue(item)
+		}
+		return items
+	}
+	return val
+}
@@ -0,0 +1,585 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memclient
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"configcenter/src/common"
+	"configcenter/src/storage"
+	"configcenter/src/storage/mgoclient"
+)
+
+// MemCli an in-memory storage.DI which works like the mongodb client,
+// it is designed for the tests which should not depend on a real db
+type MemCli struct {
+	dbName    string
+	lock      sync.RWMutex
+	txLock    sync.Mutex
+	tables    map[string]*table
+	sequences map[string]int64
+}
+
+type table struct {
+	docs    []bson.M
+	indexes []*storage.Index
+}
+
+// NewMemCli returns an empty in-memory db
+func NewMemCli(database string) *MemCli {
+	return &MemCli{
+		dbName:    database,
+		tables:    map[string]*table{},
+		sequences: map[string]int64{},
+	}
+}
+
+// Open nothing to open for the in-memory db
+func (m *MemCli) Open() error {
+	return nil
+}
+
+// Ping always succeed
+func (m *MemCli) Ping() error {
+	return nil
+}
+
+// GetSession there is no session of the in-memory db
+func (m *MemCli) GetSession() interface{} {
+	return nil
+}
+
+// Close nothing to close for the in-memory db
+func (m *MemCli) Close() {
+}
+
+// Insert insert one document
+func (m *MemCli) Insert(cName string, data interface{}) (int, error) {
+	return 0, m.InsertMuti(cName, data)
+}
+
+// InsertMuti insert muti documents
+func (m *MemCli) InsertMuti(cName string, data ...interface{}) error {
+	mgoclient.EscapeHtml(data...)
+	docs := make([]bson.M, 0, len(data))
+	for _, item := range data {
+		doc, err := normalize(item)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	t := m.getTable(cName)
+	for _, doc := range docs {
+		if err := t.checkUnique(doc, -1); err != nil {
+			return err
+		}
+		t.docs = append(t.docs, doc)
+	}
+	return nil
+}
+
+// UpdateByCondition update documents by condiction, the data is set like the $set of mongodb
+func (m *MemCli) UpdateByCondition(cName string, data, condition interface{}) error {
+	mgoclient.EscapeHtml(data)
+	set, err := normalize(data)
+	if err != nil {
+		return err
+	}
+	return m.update(cName, condition, func(doc bson.M) {
+		for key, val := range set {
+			setPath(doc, key, copyValue(val))
+		}
+	})
+}
+
+// PushByCondition set the data and append the items to the array field like the $push with $each and $slice of mongodb
+func (m *MemCli) PushByCondition(cName string, data interface{}, field string, items []interface{}, maxLen int, condition interface{}) error {
+	mgoclient.EscapeHtml(data)
+	set, err := normalize(data)
+	if err != nil {
+		return err
+	}
+	pushed, err := normalizeValue(items)
+	if err != nil {
+		return err
+	}
+	return m.update(cName, condition, func(doc bson.M) {
+		for key, val := range set {
+			setPath(doc, key, copyValue(val))
+		}
+		current, _ := getPath(doc, field)
+		array, _ := current.([]interface{})
+		if values, ok := copyValue(pushed).([]interface{}); ok {
+			array = append(array, values...)
+		}
+		if maxLen > 0 && len(array) > maxLen {
+			array = array[len(array)-maxLen:]
+		}
+		setPath(doc, field, array)
+	})
+}
+
+// GetOneByCondition get one document by condiction, returns mgo.ErrNotFound when there is no such document
+func (m *MemCli) GetOneByCondition(cName string, fields []string, condition interface{}, result interface{}) error {
+	docs, err := m.find(cName, fields, condition, "", 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(docs) <= 0 {
+		return mgo.ErrNotFound
+	}
+	return decode(docs[0], result)
+}
+
+// GetMutilByCondition get multiple document by condiction
+func (m *MemCli) GetMutilByCondition(cName string, fields []string, condition interface{}, result interface{}, sort string, start, limit int) error {
+	if len(fields) == 1 && fields[0] == "" {
+		fields = nil
+	}
+	docs, err := m.find(cName, fields, condition, sort, start, limit)
+	if err != nil {
+		return err
+	}
+	return decodeAll(docs, result)
+}
+
+// GetCntByCondition returns count number filter by condiction
+func (m *MemCli) GetCntByCondition(cName string, condition interface{}) (int, error) {
+	docs, err := m.find(cName, nil, condition, "", 0, 0)
+	return len(docs), err
+}
+
+// GetIncID returns next sequence ID for cName collection
+func (m *MemCli) GetIncID(cName string) (int64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.sequences[cName]++
+	return m.sequences[cName], nil
+}
+
+// DelByCondition delete the documents by condiction
+func (m *MemCli) DelByCondition(cName string, condition interface{}) error {
+	cond, err := normalize(condition)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	t, ok := m.tables[cName]
+	if !ok {
+		return nil
+	}
+	remains := make([]bson.M, 0, len(t.docs))
+	for _, doc := range t.docs {
+		matched, err := match(doc, cond)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			remains = append(remains, doc)
+		}
+	}
+	t.docs = remains
+	return nil
+}
+
+// HasTable returns whether the collection exists
+func (m *MemCli) HasTable(cName string) (bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	_, ok := m.tables[cName]
+	return ok, nil
+}
+
+// CreateTable create the collection
+func (m *MemCli) CreateTable(cName string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.tables[cName]; ok {
+		return fmt.Errorf("collection %s already exists", cName)
+	}
+	m.getTable(cName)
+	return nil
+}
+
+// ExecSql not supported
+func (m *MemCli) ExecSql(cmd interface{}) error {
+	return errors.New("not support method")
+}
+
+// Index create the index, only the unique indexes take effect
+func (m *MemCli) Index(cName string, index *storage.Index) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	t := m.getTable(cName)
+	key := indexKey(index)
+	for _, exist := range t.indexes {
+		if indexKey(exist) == key {
+			return nil
+		}
+	}
+	t.indexes = append(t.indexes, index)
+	for i, doc := range t.docs {
+		if err := t.checkUnique(doc, i); err != nil {
+			t.indexes = t.indexes[:len(t.indexes)-1]
+			return err
+		}
+	}
+	return nil
+}
+
+// indexKey returns the name of the index, the unnamed index is keyed by its columns like mongodb does
+func indexKey(index *storage.Index) string {
+	if index.Name != "" {
+		return index.Name
+	}
+	return strings.Join(index.Columns, "_1_") + "_1"
+}
+
+// DropTable drop the collection
+func (m *MemCli) DropTable(cName string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.tables[cName]; !ok {
+		return errors.New("ns not found")
+	}
+	delete(m.tables, cName)
+	return nil
+}
+
+// HasFields returns whether any document of the collection has the field
+func (m *MemCli) HasFields(cName, field string) (bool, error) {
+	count, err := m.GetCntByCondition(cName, bson.M{field: bson.M{"$exists": true}})
+	return count > 0, err
+}
+
+// AddColumn set the column to the documents which do not have it
+func (m *MemCli) AddColumn(cName string, column *storage.Column) error {
+	val, err := normalizeValue(column.Ext)
+	if err != nil {
+		return err
+	}
+	return m.update(cName, bson.M{column.Name: bson.M{"$exists": false}}, func(doc bson.M) {
+		setPath(doc, column.Name, copyValue(val))
+	})
+}
+
+// ModifyColumn rename the field of all the documents
+func (m *MemCli) ModifyColumn(cName, oldName, newColumn string) error {
+	return m.update(cName, nil, func(doc bson.M) {
+		if val, ok := getPath(doc, oldName); ok {
+			unsetPath(doc, oldName)
+			setPath(doc, newColumn, val)
+		}
+	})
+}
+
+// DropColumn remove the field from all the documents
+func (m *MemCli) DropColumn(cName, field string) error {
+	return m.update(cName, nil, func(doc bson.M) {
+		unsetPath(doc, field)
+	})
+}
+
+// GetType returns storage.DI_MEMORY
+func (m *MemCli) GetType() string {
+	return storage.DI_MEMORY
+}
+
+// IsDuplicateErr returns whether err is duplicate error
+func (m *MemCli) IsDuplicateErr(err error) bool {
+	return mgo.IsDup(err)
+}
+
+// IsNotFoundErr returns whether err is not found error
+func (m *MemCli) IsNotFoundErr(err error) bool {
+	return mgo.ErrNotFound == err
+}
+
+// GetDBName returns the db name
+func (m *MemCli) GetDBName() string {
+	return m.dbName
+}
+
+// WithTransaction run fn in a transaction, the collections are restored from the snapshot taken
+// before fn when it fails. The transactions are run one by one, but the writes outside the
+// transactions are not isolated from them.
+func (m *MemCli) WithTransaction(fn func(db storage.DI) error) error {
+	m.txLock.Lock()
+	defer m.txLock.Unlock()
+
+	snapshot := m.snapshot()
+	if err := fn(&memTx{MemCli: m}); err != nil {
+		m.lock.Lock()
+		m.tables = snapshot
+		m.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// memTx the db in a transaction, the nested transaction joins the outer one
+type memTx struct {
+	*MemCli
+}
+
+func (tx *memTx) WithTransaction(fn func(db storage.DI) error) error {
+	return fn(tx)
+}
+
+func (m *MemCli) snapshot() map[string]*table {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	tables := make(map[string]*table, len(m.tables))
+	for name, t := range m.tables {
+		docs := make([]bson.M, 0, len(t.docs))
+		for _, doc := range t.docs {
+			docs = append(docs, copyValue(doc).(bson.M))
+		}
+		tables[name] = &table{docs: docs, indexes: append([]*storage.Index{}, t.indexes...)}
+	}
+	return tables
+}
+
+func (m *MemCli) getTable(cName string) *table {
+	t, ok := m.tables[cName]
+	if !ok {
+		t = &table{}
+		m.tables[cName] = t
+	}
+	return t
+}
+
+// update apply the change to all the documents matching the condition,
+// nothing changes when the change breaks a unique index
+func (m *MemCli) update(cName string, condition interface{}, change func(doc bson.M)) error {
+	cond, err := normalize(condition)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	t, ok := m.tables[cName]
+	if !ok {
+		return nil
+	}
+	docs := make([]bson.M, len(t.docs))
+	for i, doc := range t.docs {
+		docs[i] = doc
+		matched, err := match(doc, cond)
+		if err != nil {
+			return err
+		}
+		if matched {
+			docs[i] = copyValue(doc).(bson.M)
+			change(docs[i])
+		}
+	}
+
+	origin := t.docs
+	t.docs = docs
+	for i, doc := range t.docs {
+		if err := t.checkUnique(doc, i); err != nil {
+			t.docs = origin
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemCli) find(cName string, fields []string, condition interface{}, sortStr string, start, limit int) ([]bson.M, error) {
+	cond, err := normalize(condition)
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	t, ok := m.tables[cName]
+	if !ok {
+		return nil, nil
+	}
+	docs := []bson.M{}
+	for _, doc := range t.docs {
+		matched, err := match(doc, cond)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			docs = append(docs, doc)
+		}
+	}
+
+	if sortStr != "" {
+		sortDocs(docs, strings.Split(sortStr, common.BKDBSortFieldSep))
+	}
+	if start > 0 {
+		if start >= len(docs) {
+			return nil, nil
+		}
+		docs = docs[start:]
+	}
+	if limit > 0 && limit < len(docs) {
+		docs = docs[:limit]
+	}
+
+	results := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		results = append(results, project(doc, fields))
+	}
+	return results, nil
+}
+
+// checkUnique make sure the document does not break the unique indexes, skip is the index of the document itself
+func (t *table) checkUnique(doc bson.M, skip int) error {
+	for _, index := range t.indexes {
+		if index.Type != storage.INDEX_TYPE_UNIQUE && index.Type != storage.INDEX_TYPE_BACKGROUP_UNIQUE {
+			continue
+		}
+		for i, exist := range t.docs {
+			if i == skip {
+				continue
+			}
+			duplicate := true
+			for _, column := range index.Columns {
+				column = strings.TrimLeft(column, "+-")
+				a, _ := getPath(doc, column)
+				b, _ := getPath(exist, column)
+				if !valueEqual(a, b) {
+					duplicate = false
+					break
+				}
+			}
+			if duplicate {
+				return &mgo.LastError{Code: 11000, Err: fmt.Sprintf("E11000 duplicate key error index: %s", index.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+// sortDocs sort the documents like the mongodb, the field with "-" prefix is sorted descending
+func sortDocs(docs []bson.M, fields []string) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, field := range fields {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimLeft(field, "+-")
+			if field == "" {
+				continue
+			}
+			a, _ := getPath(docs[i], field)
+			b, _ := getPath(docs[j], field)
+			ret := sortCompare(a, b)
+			if ret == 0 {
+				continue
+			}
+			if desc {
+				return ret > 0
+			}
+			return ret < 0
+		}
+		return false
+	})
+}
+
+// project keep only the fields of the document, all the fields are kept when fields is empty
+func project(doc bson.M, fields []string) bson.M {
+	if len(fields) <= 0 {
+		result := copyValue(doc).(bson.M)
+		delete(result, "_id")
+		return result
+	}
+	result := bson.M{}
+	for _, field := range fields {
+		if field == "_id" {
+			continue
+		}
+		if val, ok := getPath(doc, field); ok {
+			setPath(result, field, copyValue(val))
+		}
+	}
+	return result
+}
+
+// normalize convert the struct or map into bson.M, just like how it is saved into mongodb
+func normalize(data interface{}) (bson.M, error) {
+	if data == nil {
+		return bson.M{}, nil
+	}
+	if val := reflect.ValueOf(data); val.Kind() == reflect.Ptr && val.IsNil() {
+		return bson.M{}, nil
+	}
+	out, err := bson.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	if err := bson.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func normalizeValue(val interface{}) (interface{}, error) {
+	doc, err := normalize(bson.M{"v": val})
+	if err != nil {
+		return nil, err
+	}
+	return doc["v"], nil
+}
+
+func decode(doc bson.M, result interface{}) error {
+	if val, ok := result.(*interface{}); ok {
+		*val = doc
+		return nil
+	}
+	out, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(out, result)
+}
+
+// decodeAll decode the documents into the result, which should be a pointer to slice
+func decodeAll(docs []bson.M, result interface{}) error {
+	resultv := reflect.ValueOf(result)
+	if resultv.Kind() != reflect.Ptr || resultv.Elem().Kind() != reflect.Slice {
+		return errors.New("result argument must be a slice address")
+	}
+	slicev := resultv.Elem().Slice(0, 0)
+	elemt := slicev.Type().Elem()
+	for _, doc := range docs {
+		elemp := reflect.New(elemt)
+		if err := decode(doc, elemp.Interface()); err != nil {
+			return err
+		}
+		slicev = reflect.Append(slicev, elemp.Elem())
+	}
+	resultv.Elem().Set(slicev)
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/storage"
+)
+
+type host struct {
+	HostID   int64    `bson:"bk_host_id"`
+	InnerIP  string   `bson:"bk_host_innerip"`
+	Cloud    int64    `bson:"bk_cloud_id"`
+	Tags     []string `bson:"tags"`
+	Operator string   `bson:"operator,omitempty"`
+}
+
+func newTestDB(t *testing.T) *MemCli {
+	db := NewMemCli("cmdb")
+	require.NoError(t, db.Index("cc_HostBase", storage.GetMongoIndex("bk_host_id_1", []string{"bk_host_id"}, true, false)))
+	hosts := []interface{}{
+		host{HostID: 1, InnerIP: "10.0.0.1", Cloud: 0, Tags: []string{"db"}, Operator: "admin"},
+		host{HostID: 2, InnerIP: "10.0.0.2", Cloud: 0, Tags: []string{"web"}},
+		host{HostID: 3, InnerIP: "192.168.0.1", Cloud: 1, Tags: []string{"web", "db"}},
+	}
+	require.NoError(t, db.InsertMuti("cc_HostBase", hosts...))
+	return db
+}
+
+func TestMemCliQuery(t *testing.T) {
+	db := newTestDB(t)
+
+	cases := []struct {
+		cond  map[string]interface{}
+		count int
+	}{
+		{map[string]interface{}{"bk_host_id": 1}, 1},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$in": []int64{1, 3, 5}}}, 2},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$nin": []int64{1}}}, 2},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$gt": 1, "$lt": 3}}, 1},
+		{map[string]interface{}{"bk_host_id": map[string]interface{}{"$gte": 2}}, 2},
+		{map[string]interface{}{"bk_cloud_id": map[string]interface{}{"$ne": 0}}, 1},
+		{map[string]interface{}{"bk_host_innerip": map[string]interface{}{"$regex": "^10\\."}}, 2},
+		{map[string]interface{}{"operator": map[string]interface{}{"$exists": true}}, 1},
+		{map[string]interface{}{"operator": map[string]interface{}{"$exists": false}}, 2},
+		{map[string]interface{}{"tags": "db"}, 2},
+		{map[string]interface{}{"$or": []interface{}{
+			map[string]interface{}{"bk_host_id": 1},
+			map[string]interface{}{"bk_cloud_id": 1},
+		}}, 2},
+		{nil, 3},
+	}
+	for _, c := range cases {
+		count, err := db.GetCntByCondition("cc_HostBase", c.cond)
+		require.NoError(t, err)
+		assert.Equal(t, c.count, count, "condition %v", c.cond)
+	}
+
+	_, err := db.GetCntByCondition("cc_HostBase", map[string]interface{}{"bk_host_id": map[string]interface{}{"$unknown": 1}})
+	assert.Error(t, err)
+}
+
+func TestMemCliSortAndPage(t *testing.T) {
+	db := newTestDB(t)
+
+	results := []host{}
+	require.NoError(t, db.GetMutilByCondition("cc_HostBase", nil, nil, &results, "-bk_host_id", 1, 1))
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(2), results[0].HostID)
+
+	results = []host{}
+	require.NoError(t, db.GetMutilByCondition("cc_HostBase", nil, nil, &results, "bk_cloud_id,-bk_host_id", 0, 0))
+	ids := []int64{}
+	for _, result := range results {
+		ids = append(ids, result.HostID)
+	}
+	assert.Equal(t, []int64{2, 1, 3}, ids)
+
+	maps := []map[string]interface{}{}
+	require.NoError(t, db.GetMutilByCondition("cc_HostBase", []string{"bk_host_id"}, map[string]interface{}{"bk_host_id": 3}, &maps, "", 0, 0))
+	require.Len(t, maps, 1)
+	assert.Len(t, maps[0], 1)
+}
+
+func TestMemCliWrite(t *testing.T) {
+	db := newTestDB(t)
+
+	_, err := db.Insert("cc_HostBase", host{HostID: 1})
+	assert.True(t, db.IsDuplicateErr(err))
+
+	require.NoError(t, db.UpdateByCondition("cc_HostBase", map[string]interface{}{"bk_cloud_id": 2, "attr.os": "linux"}, map[string]interface{}{"bk_host_id": 2}))
+	result := map[string]interface{}{}
+	require.NoError(t, db.GetOneByCondition("cc_HostBase", nil, map[string]interface{}{"attr.os": "linux"}, &result))
+	assert.EqualValues(t, 2, result["bk_host_id"])
+	assert.EqualValues(t, 2, result["bk_cloud_id"])
+
+	err = db.UpdateByCondition("cc_HostBase", map[string]interface{}{"bk_host_id": 1}, map[string]interface{}{"bk_host_id": 2})
+	assert.True(t, db.IsDuplicateErr(err))
+
+	require.NoError(t, db.DelByCondition("cc_HostBase", map[string]interface{}{"bk_cloud_id": map[string]interface{}{"$in": []int{1, 2}}}))
+	count, err := db.GetCntByCondition("cc_HostBase", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	err = db.GetOneByCondition("cc_HostBase", nil, map[string]interface{}{"bk_host_id": 3}, &result)
+	assert.True(t, db.IsNotFoundErr(err))
+
+	require.NoError(t, db.DropColumn("cc_HostBase", "operator"))
+	has, err := db.HasFields("cc_HostBase", "operator")
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestMemCliPush(t *testing.T) {
+	db := newTestDB(t)
+
+	cond := map[string]interface{}{"bk_host_id": 1}
+	require.NoError(t, db.PushByCondition("cc_HostBase", map[string]interface{}{"operator": "user"}, "tags", []interface{}{"cache", "queue"}, 0, cond))
+	result := host{}
+	require.NoError(t, db.GetOneByCondition("cc_HostBase", nil, cond, &result))
+	assert.Equal(t, []string{"db", "cache", "queue"}, result.Tags)
+	assert.Equal(t, "user", result.Operator)
+
+	// only the last items are kept
+	require.NoError(t, db.PushByCondition("cc_HostBase", nil, "tags", []interface{}{"web"}, 2, cond))
+	require.NoError(t, db.GetOneByCondition("cc_HostBase", nil, cond, &result))
+	assert.Equal(t, []string{"queue", "web"}, result.Tags)
+
+	// the array is created when the field does not exist
+	require.NoError(t, db.PushByCondition("cc_HostBase", nil, "labels", []interface{}{"a"}, 0, cond))
+	doc := map[string]interface{}{}
+	require.NoError(t, db.GetOneByCondition("cc_HostBase", nil, cond, &doc))
+	assert.Equal(t, []interface{}{"a"}, doc["labels"])
+}
+
+func TestMemCliUnnamedIndex(t *testing.T) {
+	db := newTestDB(t)
+
+	// the unnamed indexes are keyed by their columns, so they do not collapse into one
+	require.NoError(t, db.Index("cc_HostBase", storage.GetMongoIndex("", []string{"bk_host_innerip"}, true, false)))
+	require.NoError(t, db.Index("cc_HostBase", storage.GetMongoIndex("", []string{"bk_host_innerip", "bk_cloud_id"}, true, false)))
+	require.NoError(t, db.Index("cc_HostBase", storage.GetMongoIndex("", []string{"bk_host_innerip"}, true, false)))
+	assert.Len(t, db.getTable("cc_HostBase").indexes, 3)
+
+	_, err := db.Insert("cc_HostBase", host{HostID: 4, InnerIP: "10.0.0.1", Cloud: 2})
+	assert.True(t, db.IsDuplicateErr(err))
+}
+
+func TestMemCliGetIncID(t *testing.T) {
+	db := NewMemCli("cmdb")
+	for expect := int64(1); expect <= 3; expect++ {
+		id, err := db.GetIncID("cc_HostBase")
+		require.NoError(t, err)
+		assert.Equal(t, expect, id)
+	}
+	id, err := db.GetIncID("cc_ApplicationBase")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
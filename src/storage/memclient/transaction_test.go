@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"configcenter/src/storage"
+)
+
+// transfer move host 1 to module 2 and update its operator, then fails when fail is set
+func transfer(fail bool) func(db storage.DI) error {
+	return func(db storage.DI) error {
+		if err := db.DelByCondition("cc_ModuleHostConfig", map[string]interface{}{"bk_host_id": 1}); err != nil {
+			return err
+		}
+		if _, err := db.Insert("cc_ModuleHostConfig", map[string]interface{}{"bk_host_id": 1, "bk_module_id": 2, "name": "a&b"}); err != nil {
+			return err
+		}
+		if err := db.UpdateByCondition("cc_HostBase", map[string]interface{}{"operator": "user"}, map[string]interface{}{"bk_host_id": 1}); err != nil {
+			return err
+		}
+		if fail {
+			return errors.New("transfer failed")
+		}
+		return nil
+	}
+}
+
+func newTransferDB(t *testing.T) *MemCli {
+	db := newTestDB(t)
+	require.NoError(t, db.InsertMuti("cc_ModuleHostConfig",
+		map[string]interface{}{"bk_host_id": 1, "bk_module_id": 1, "name": "a&b"},
+		map[string]interface{}{"bk_host_id": 2, "bk_module_id": 1, "name": "c"},
+	))
+	return db
+}
+
+func assertNotTransferred(t *testing.T, db storage.DI) {
+	relations := []map[string]interface{}{}
+	require.NoError(t, db.GetMutilByCondition("cc_ModuleHostConfig", nil, nil, &relations, "bk_host_id", 0, 0))
+	require.Len(t, relations, 2)
+	assert.EqualValues(t, 1, relations[0]["bk_module_id"])
+	assert.Equal(t, "a&amp;b", relations[0]["name"])
+
+	result := map[string]interface{}{}
+	require.NoError(t, db.GetOneByCondition("cc_HostBase", nil, map[string]interface{}{"bk_host_id": 1}, &result))
+	assert.Equal(t, "admin", result["operator"])
+}
+
+func TestMemCliTransaction(t *testing.T) {
+	db := newTransferDB(t)
+	assert.Error(t, db.WithTransaction(transfer(true)))
+	assertNotTransferred(t, db)
+
+	require.NoError(t, db.WithTransaction(transfer(false)))
+	count, err := db.GetCntByCondition("cc_ModuleHostConfig", map[string]interface{}{"bk_host_id": 1, "bk_module_id": 2})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestCompensation(t *testing.T) {
+	db := newTransferDB(t)
+	err := storage.WithCompensation(db, func(tx storage.DI) error {
+		// the nested transaction joins the outer one
+		return tx.WithTransaction(transfer(true))
+	})
+	assert.Error(t, err)
+	assertNotTransferred(t, db)
+
+	require.NoError(t, storage.WithCompensation(db, transfer(false)))
+	count, err := db.GetCntByCondition("cc_ModuleHostConfig", map[string]interface{}{"bk_host_id": 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestCompensationPush(t *testing.T) {
+	db := newTestDB(t)
+	cond := map[string]interface{}{"bk_host_id": 1}
+	err := storage.WithCompensation(db, func(tx storage.DI) error {
+		if err := tx.PushByCondition("cc_HostBase", map[string]interface{}{"operator": "user"}, "tags", []interface{}{"web"}, 0, cond); err != nil {
+			return err
+		}
+		return errors.New("push failed")
+	})
+	assert.Error(t, err)
+
+	result := host{}
+	require.NoError(t, db.GetOneByCondition("cc_HostBase", nil, cond, &result))
+	assert.Equal(t, []string{"db"}, result.Tags)
+	assert.Equal(t, "admin", result.Operator)
+}
+
+func TestCompensationInsertByID(t *testing.T) {
+	db := newTestDB(t)
+	err := storage.WithCompensation(db, func(tx storage.DI) error {
+		if _, err := tx.Insert("cc_ModuleHostConfig", map[string]interface{}{"bk_host_id": 1, "bk_module_id": 2}); err != nil {
+			return err
+		}
+		if err := tx.InsertMuti("cc_ModuleHostConfig", map[string]interface{}{"bk_host_id": 2, "bk_module_id": 2}); err != nil {
+			return err
+		}
+		// the same documents inserted by others meanwhile are not undone
+		require.NoError(t, db.InsertMuti("cc_ModuleHostConfig",
+			map[string]interface{}{"bk_host_id": 1, "bk_module_id": 2},
+			map[string]interface{}{"bk_host_id": 2, "bk_module_id": 2},
+		))
+		return errors.New("insert failed")
+	})
+	assert.Error(t, err)
+
+	count, err := db.GetCntByCondition("cc_ModuleHostConfig", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
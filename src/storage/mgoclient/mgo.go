@@ -154,6 +154,18 @@ func (m *MgoCli) UpdateByCondition(cName string, data, condition interface{}) er
 	return nil
 }
 
+// PushByCondition set the data and push the items to the field of the documents by condiction
+func (m *MgoCli) PushByCondition(cName string, data interface{}, field string, items []interface{}, maxLen int, condition interface{}) error {
+	m.session.Refresh()
+	c := m.session.DB(m.dbName).C(cName)
+	EscapeHtml(data)
+	_, err := c.UpdateAll(condition, storage.PushUpdate(data, field, items, maxLen))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // GetOneByCondition get one document by condiction
 func (m *MgoCli) GetOneByCondition(cName string, fields []string, condiction interface{}, result interface{}) error {
 	m.session.Refresh()
@@ -372,3 +384,9 @@ func (m *MgoCli) IsNotFoundErr(err error) bool {
 func (m *MgoCli) GetDBName() string {
 	return m.dbName
 }
+
+// WithTransaction the mongodb driver can not do multi-document transactions,
+// so the writes are rolled back by compensating writes
+func (m *MgoCli) WithTransaction(fn func(db storage.DI) error) error {
+	return storage.WithCompensation(m, fn)
+}
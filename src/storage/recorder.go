@@ -0,0 +1,167 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"sync"
+)
+
+// the actions of the recorded operations
+const (
+	ActionGetIncID     = "get_inc_id"
+	ActionInsert       = "insert"
+	ActionUpdate       = "update"
+	ActionPush         = "push"
+	ActionDelete       = "delete"
+	ActionExecSql      = "exec_sql"
+	ActionIndex        = "index"
+	ActionCreateTable  = "create_table"
+	ActionDropTable    = "drop_table"
+	ActionAddColumn    = "add_column"
+	ActionModifyColumn = "modify_column"
+	ActionDropColumn   = "drop_column"
+	ActionGetSession   = "get_session"
+)
+
+// Operation a write recorded by the Recorder
+type Operation struct {
+	Action     string      `json:"action"`
+	Collection string      `json:"collection,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	Condition  interface{} `json:"condition,omitempty"`
+}
+
+// Recorder a DI which records the writes instead of doing them, the reads go to the
+// underlying db. It is used to preview the writes, so the reads never see the recorded
+// writes, the ids returned by GetIncID are placeholders, and GetSession returns nil
+// because the writes through the raw session can not be recorded.
+type Recorder struct {
+	DI
+	lock       sync.Mutex
+	operations []Operation
+	sequences  map[string]int64
+}
+
+// NewRecorder create a Recorder reads from db
+func NewRecorder(db DI) *Recorder {
+	return &Recorder{DI: db, sequences: map[string]int64{}}
+}
+
+// Operations return the recorded writes in order
+func (r *Recorder) Operations() []Operation {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Operation{}, r.operations...)
+}
+
+// Reset drop the recorded writes
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.operations = nil
+}
+
+func (r *Recorder) record(action, cName string, data, condition interface{}) {
+	// copy the documents, the caller may change them after the call
+	if doc, err := toDocument(data); err == nil && data != nil {
+		data = doc
+	}
+	if doc, err := toDocument(condition); err == nil && condition != nil {
+		condition = doc
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.operations = append(r.operations, Operation{Action: action, Collection: cName, Data: data, Condition: condition})
+}
+
+// GetIncID return a placeholder id, count from 1 for each collection
+func (r *Recorder) GetIncID(cName string) (int64, error) {
+	r.record(ActionGetIncID, cName, nil, nil)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sequences[cName]++
+	return r.sequences[cName], nil
+}
+
+func (r *Recorder) Insert(cName string, data interface{}) (int, error) {
+	r.record(ActionInsert, cName, data, nil)
+	return 0, nil
+}
+
+func (r *Recorder) InsertMuti(cName string, data ...interface{}) error {
+	for _, item := range data {
+		r.record(ActionInsert, cName, item, nil)
+	}
+	return nil
+}
+
+func (r *Recorder) UpdateByCondition(cName string, data, condition interface{}) error {
+	r.record(ActionUpdate, cName, data, condition)
+	return nil
+}
+
+func (r *Recorder) PushByCondition(cName string, data interface{}, field string, items []interface{}, maxLen int, condition interface{}) error {
+	r.record(ActionPush, cName, PushUpdate(data, field, items, maxLen), condition)
+	return nil
+}
+
+func (r *Recorder) DelByCondition(cName string, condition interface{}) error {
+	r.record(ActionDelete, cName, nil, condition)
+	return nil
+}
+
+func (r *Recorder) ExecSql(cmd interface{}) error {
+	r.record(ActionExecSql, "", cmd, nil)
+	return nil
+}
+
+func (r *Recorder) Index(cName string, index *Index) error {
+	r.record(ActionIndex, cName, index, nil)
+	return nil
+}
+
+func (r *Recorder) CreateTable(sql string) error {
+	r.record(ActionCreateTable, sql, nil, nil)
+	return nil
+}
+
+func (r *Recorder) DropTable(cName string) error {
+	r.record(ActionDropTable, cName, nil, nil)
+	return nil
+}
+
+func (r *Recorder) AddColumn(cName string, column *Column) error {
+	r.record(ActionAddColumn, cName, column, nil)
+	return nil
+}
+
+func (r *Recorder) ModifyColumn(cName, oldName, newColumn string) error {
+	r.record(ActionModifyColumn, cName, map[string]string{"old": oldName, "new": newColumn}, nil)
+	return nil
+}
+
+func (r *Recorder) DropColumn(cName, field string) error {
+	r.record(ActionDropColumn, cName, field, nil)
+	return nil
+}
+
+// GetSession the writes through the raw session can not be recorded, so return nil
+func (r *Recorder) GetSession() interface{} {
+	r.record(ActionGetSession, "", nil, nil)
+	return nil
+}
+
+// WithTransaction nothing is written, so there is nothing to rollback
+func (r *Recorder) WithTransaction(fn func(db DI) error) error {
+	return fn(r)
+}
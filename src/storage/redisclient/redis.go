@@ -237,6 +237,10 @@ func (r *Redis) UpdateByCondition(cName string, oldobj, newobj interface{}) erro
 	return errors.New("no support method")
 }
 
+func (r *Redis) PushByCondition(cName string, data interface{}, field string, items []interface{}, maxLen int, condition interface{}) error {
+	return errors.New("no support method")
+}
+
 func (r *Redis) GetOneByCondition(cName string, fields []string, selector, results interface{}) error {
 	ret, _ := results.(*interface{})
 	var err error
@@ -432,3 +436,8 @@ func (r *Redis) IsNotFoundErr(err error) bool {
 func (m *Redis) GetDBName() string {
 	return m.dbName
 }
+
+// WithTransaction the redis commands are not documents, they are not rolled back when fn fails
+func (r *Redis) WithTransaction(fn func(db storage.DI) error) error {
+	return fn(r)
+}
@@ -20,6 +20,9 @@ type DI interface {
 	IsDuplicateErr(err error) bool
 	IsNotFoundErr(err error) bool
 	UpdateByCondition(cName string, data, condition interface{}) error
+	// PushByCondition set the data like UpdateByCondition and append the items to the array field
+	// at once, the array keeps the last maxLen items when maxLen is positive
+	PushByCondition(cName string, data interface{}, field string, items []interface{}, maxLen int, condition interface{}) error
 	GetOneByCondition(cName string, fields []string, condition interface{}, result interface{}) error
 	GetMutilByCondition(cName string, fields []string, condition interface{}, result interface{}, sort string, start, limit int) error
 	GetCntByCondition(cName string, condition interface{}) (int, error)
@@ -39,6 +42,22 @@ type DI interface {
 	Close()
 	GetSession() interface{}
 	GetDBName() string
+	// WithTransaction run fn in a transaction, the writes by the db passed to fn are
+	// committed when fn returns nil, and rolled back when fn returns an error
+	WithTransaction(fn func(db DI) error) error
+}
+
+// PushUpdate returns the mongodb style update document of PushByCondition
+func PushUpdate(data interface{}, field string, items []interface{}, maxLen int) map[string]interface{} {
+	each := map[string]interface{}{"$each": items}
+	if maxLen > 0 {
+		each["$slice"] = -maxLen
+	}
+	update := map[string]interface{}{"$push": map[string]interface{}{field: each}}
+	if data != nil {
+		update["$set"] = data
+	}
+	return update
 }
 
 const (
@@ -90,4 +109,6 @@ const (
 	DI_MYSQL string = "mysql"
 	DI_MONGO string = "mongodb"
 	DI_REDIS string = "redis"
+	// DI_MEMORY the in-memory db for tests, it is not served by dbclient.NewDB
+	DI_MEMORY string = "memory"
 )
@@ -0,0 +1,261 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// WithCompensation run fn with a db which records how to undo its writes, and undo them
+// in the reverse order when fn returns an error. It is the fallback of the backends which
+// can not do transactions: the inserted documents are undone by their _id, but the updated ones
+// are found by their contents, so it is not isolated from the concurrent writes, and the schema
+// changes are never undone.
+func WithCompensation(db DI, fn func(db DI) error) error {
+	tx := &compensatingDI{DI: db}
+	err := fn(tx)
+	if err == nil {
+		return nil
+	}
+	if undoErr := tx.undo(); undoErr != nil {
+		return fmt.Errorf("%v, and undo the writes failed: %v", err, undoErr)
+	}
+	return err
+}
+
+type compensatingDI struct {
+	DI
+	undos []func() error
+}
+
+// WithTransaction the nested transaction joins the outer one
+func (c *compensatingDI) WithTransaction(fn func(db DI) error) error {
+	return fn(c)
+}
+
+// Insert undo by deleting the inserted document by its _id
+func (c *compensatingDI) Insert(cName string, data interface{}) (int, error) {
+	docs, ids, err := withObjectIDs(data)
+	if err != nil {
+		return 0, err
+	}
+	cnt, err := c.DI.Insert(cName, docs[0])
+	if err != nil {
+		return cnt, err
+	}
+	c.recordInsert(cName, ids)
+	return cnt, nil
+}
+
+// InsertMuti undo by deleting the inserted documents by their _id
+func (c *compensatingDI) InsertMuti(cName string, data ...interface{}) error {
+	docs, ids, err := withObjectIDs(data...)
+	if err != nil {
+		return err
+	}
+	if err := c.DI.InsertMuti(cName, docs...); err != nil {
+		return err
+	}
+	c.recordInsert(cName, ids)
+	return nil
+}
+
+// UpdateByCondition undo by setting the updated fields back to their previous values
+func (c *compensatingDI) UpdateByCondition(cName string, data, condition interface{}) error {
+	set, err := toDocument(data)
+	if err != nil {
+		return err
+	}
+	return c.restorable(cName, fieldNames(set), condition, func() error {
+		return c.DI.UpdateByCondition(cName, data, condition)
+	})
+}
+
+// PushByCondition undo by setting the updated fields and the array back to their previous values
+func (c *compensatingDI) PushByCondition(cName string, data interface{}, field string, items []interface{}, maxLen int, condition interface{}) error {
+	set, err := toDocument(data)
+	if err != nil {
+		return err
+	}
+	set[field] = items
+	return c.restorable(cName, fieldNames(set), condition, func() error {
+		return c.DI.PushByCondition(cName, data, field, items, maxLen, condition)
+	})
+}
+
+// restorable do the write, and record the undo which set the updated fields of the documents
+// matching the condition back to their values before the write
+func (c *compensatingDI) restorable(cName string, updated map[string]bool, condition interface{}, write func() error) error {
+	previous, err := c.find(cName, condition)
+	if err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+
+	c.undos = append(c.undos, func() error {
+		for _, doc := range previous {
+			restore := bson.M{}
+			for key := range updated {
+				restore[key] = doc[key]
+			}
+			cond, err := identify(doc, updated)
+			if err != nil {
+				return err
+			}
+			if err := c.DI.UpdateByCondition(cName, keepEscaped(restore), cond); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+// DelByCondition undo by inserting the deleted documents again
+func (c *compensatingDI) DelByCondition(cName string, condition interface{}) error {
+	previous, err := c.find(cName, condition)
+	if err != nil {
+		return err
+	}
+	if err := c.DI.DelByCondition(cName, condition); err != nil {
+		return err
+	}
+	if len(previous) <= 0 {
+		return nil
+	}
+
+	c.undos = append(c.undos, func() error {
+		docs := make([]interface{}, 0, len(previous))
+		for _, doc := range previous {
+			docs = append(docs, keepEscaped(doc))
+		}
+		return c.DI.InsertMuti(cName, docs...)
+	})
+	return nil
+}
+
+func (c *compensatingDI) recordInsert(cName string, ids []interface{}) {
+	c.undos = append(c.undos, func() error {
+		return c.DI.DelByCondition(cName, bson.M{"_id": bson.M{"$in": ids}})
+	})
+}
+
+func (c *compensatingDI) find(cName string, condition interface{}) ([]bson.M, error) {
+	docs := []bson.M{}
+	if err := c.DI.GetMutilByCondition(cName, nil, condition, &docs, "", 0, 0); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// undo run all the undos in the reverse order, it goes on when an undo fails and returns the first error
+func (c *compensatingDI) undo() error {
+	var firstErr error
+	for i := len(c.undos) - 1; i >= 0; i-- {
+		if err := c.undos[i](); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.undos = nil
+	return firstErr
+}
+
+// withObjectIDs convert the data into documents with the _id, which is generated if the data has none,
+// so that the inserted documents could be found exactly
+func withObjectIDs(data ...interface{}) ([]interface{}, []interface{}, error) {
+	docs := make([]interface{}, 0, len(data))
+	ids := make([]interface{}, 0, len(data))
+	for _, item := range data {
+		doc, err := toDocument(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := doc["_id"]; !ok {
+			doc["_id"] = bson.NewObjectId()
+		}
+		docs = append(docs, doc)
+		ids = append(ids, doc["_id"])
+	}
+	return docs, ids, nil
+}
+
+// fieldNames returns the top level fields of the updated keys, e.g. data of data.os
+func fieldNames(set bson.M) map[string]bool {
+	updated := map[string]bool{}
+	for key := range set {
+		updated[strings.Split(key, ".")[0]] = true
+	}
+	return updated
+}
+
+// identify returns the condition to find the document by its scalar fields, the skipped fields are excluded
+func identify(doc bson.M, skip map[string]bool) (bson.M, error) {
+	cond := bson.M{}
+	for key, val := range doc {
+		if key == "_id" || skip[key] {
+			continue
+		}
+		switch val.(type) {
+		case string, bool, time.Time, nil:
+			cond[key] = val
+		default:
+			kind := reflect.ValueOf(val).Kind()
+			if kind >= reflect.Int && kind <= reflect.Float64 {
+				cond[key] = val
+			}
+		}
+	}
+	if len(cond) <= 0 {
+		return nil, fmt.Errorf("can not identify the document %v", doc)
+	}
+	return cond, nil
+}
+
+// escapedString the strings read from the db were escaped by the db client when saved,
+// the named type keeps them from being escaped again when they are written back
+type escapedString string
+
+func keepEscaped(doc bson.M) bson.M {
+	result := bson.M{}
+	for key, val := range doc {
+		if str, ok := val.(string); ok {
+			result[key] = escapedString(str)
+			continue
+		}
+		result[key] = val
+	}
+	return result
+}
+
+// toDocument convert the struct or map into bson.M, just like how it is saved into the db
+func toDocument(data interface{}) (bson.M, error) {
+	doc := bson.M{}
+	if data == nil {
+		return doc, nil
+	}
+	out, err := bson.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
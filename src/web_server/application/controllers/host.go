@@ -134,18 +134,6 @@ func ExportHost(c *gin.Context) {
 	defErr := cc.Error.CreateDefaultCCErrorIf(language)
 
 	apiSite, _ := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
-	hostInfo, err := logics.GetHostData(appIDStr, hostIDStr, apiSite, c.Request.Header)
-	if err != nil {
-		blog.Error(err.Error())
-		msg := getReturnStr(common.CCErrWebGetHostFail, defErr.Errorf(common.CCErrWebGetHostFail, err.Error()).Error(), nil)
-		c.String(http.StatusBadGateway, msg, nil)
-		return
-	}
-	var file *xlsx.File
-	var sheet *xlsx.Sheet
-
-	file = xlsx.NewFile()
-	sheet, err = file.AddSheet("host")
 
 	objID := common.BKInnerObjIDHost
 	fields, err := logics.GetObjFieldIDs(objID, apiSite, logics.GetFilterFields(objID), c.Request.Header)
@@ -155,13 +143,6 @@ func ExportHost(c *gin.Context) {
 		c.Writer.Write([]byte(reply))
 		return
 	}
-	err = logics.BuildHostExcelFromData(objID, fields, nil, hostInfo, sheet, defLang)
-	if nil != err {
-		blog.Errorf("ExportHost object:%s error:%s", objID, err.Error())
-		reply := getReturnStr(common.CCErrCommExcelTemplateFailed, defErr.Errorf(common.CCErrCommExcelTemplateFailed, objID).Error(), nil)
-		c.Writer.Write([]byte(reply))
-		return
-	}
 
 	dirFileName := fmt.Sprintf("%s/export", webCommon.ResourcePath)
 	_, err = os.Stat(dirFileName)
@@ -171,8 +152,31 @@ func ExportHost(c *gin.Context) {
 	fileName := fmt.Sprintf("%dhost.xlsx", time.Now().UnixNano())
 	dirFileName = fmt.Sprintf("%s/%s", dirFileName, fileName)
 
-	logics.ProductExcelCommentSheet(file, defLang)
-	err = file.Save(dirFileName)
+	file, err := os.Create(dirFileName)
+	if err != nil {
+		blog.Error("ExportHost create file error:%s", err.Error())
+		reply := getReturnStr(common.CCErrWebCreateEXCELFail, defErr.Errorf(common.CCErrCommExcelTemplateFailed, err.Error()).Error(), nil)
+		c.Writer.Write([]byte(reply))
+		return
+	}
+	defer os.Remove(dirFileName)
+
+	// the hosts are streamed to the file page by page as they are read, only one page is kept in memory
+	writer, err := logics.NewHostExcelWriter(file, fields, nil, defLang)
+	if err == nil {
+		err = logics.GetHostDataByPage(appIDStr, hostIDStr, apiSite, c.Request.Header, writer.Write)
+		if err != nil {
+			file.Close()
+			blog.Error(err.Error())
+			msg := getReturnStr(common.CCErrWebGetHostFail, defErr.Errorf(common.CCErrWebGetHostFail, err.Error()).Error(), nil)
+			c.String(http.StatusBadGateway, msg, nil)
+			return
+		}
+		err = writer.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		blog.Error("ExportHost save file error:%s", err.Error())
 		reply := getReturnStr(common.CCErrWebCreateEXCELFail, defErr.Errorf(common.CCErrCommExcelTemplateFailed, err.Error()).Error(), nil)
@@ -182,8 +186,6 @@ func ExportHost(c *gin.Context) {
 	logics.AddDownExcelHttpHeader(c, "host.xlsx")
 	c.File(dirFileName)
 
-	os.Remove(dirFileName)
-
 }
 
 //BuildDownLoadExcelTemplate build download excel template
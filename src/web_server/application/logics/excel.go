@@ -15,6 +15,7 @@ package logics
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -58,18 +59,61 @@ func BuildExcelFromData(objID string, fields map[string]Property, filter []strin
 	return nil
 }
 
-// BuildHostExcelFromData product excel from data
-func BuildHostExcelFromData(objID string, fields map[string]Property, filter []string, data []interface{}, sheet *xlsx.Sheet, defLang lang.DefaultCCLanguageIf) error {
-	extFieldsTopoID := "cc_ext_field_topo"
+// HostExcelWriter stream the hosts to the excel file page by page,
+// the rows written are flushed to the writer and not kept in memory.
+// the cells are written as strings without the styles of the excel template
+type HostExcelWriter struct {
+	fields      map[string]Property
+	file        *xlsx.StreamFile
+	colCount    int
+	commentRows [][]string
+}
+
+const extFieldsTopoID = "cc_ext_field_topo"
+
+// NewHostExcelWriter write the header of the host sheet to the writer and create the writer of the rows,
+// Close must be called after all the hosts are written
+func NewHostExcelWriter(writer io.Writer, fields map[string]Property, filter []string, defLang lang.DefaultCCLanguageIf) (*HostExcelWriter, error) {
 	extFields := map[string]string{
 		extFieldsTopoID: defLang.Language("web_ext_field_topo"),
 	}
 	fields = addExtFields(fields, extFields)
 	addSystemField(fields, common.BKInnerObjIDHost, defLang)
 
-	productExcelHealer(fields, filter, sheet, defLang)
-	//indexID := getFieldsIDIndexMap(fields)
-	rowIndex := common.HostAddMethodExcelIndexOffset
+	header := getExcelHeaderValues(fields, filter, defLang)
+	builder := xlsx.NewStreamFileBuilder(writer)
+	if err := builder.AddSheet("host", header[0], nil); nil != err {
+		return nil, err
+	}
+	commentName, commentRows := getCommentSheetValues(defLang)
+	if 0 < len(commentRows) {
+		if err := builder.AddSheet(commentName, commentRows[0], nil); nil != err {
+			return nil, err
+		}
+	}
+	file, err := builder.Build()
+	if nil != err {
+		return nil, err
+	}
+	for _, row := range header[1:] {
+		if err := file.Write(row); nil != err {
+			return nil, err
+		}
+	}
+
+	w := &HostExcelWriter{
+		fields:   fields,
+		file:     file,
+		colCount: len(header[0]),
+	}
+	if 0 < len(commentRows) {
+		w.commentRows = commentRows[1:]
+	}
+	return w, nil
+}
+
+// Write write the hosts after the rows written
+func (w *HostExcelWriter) Write(data []interface{}) error {
 	for _, row := range data {
 		hostData, ok := row.(map[string]interface{})
 		if false == ok {
@@ -90,14 +134,29 @@ func BuildHostExcelFromData(objID string, fields map[string]Property, filter []s
 			rowMap[extFieldsTopoID] = strings.Join(topo, "\n")
 		}
 
-		setExcelRowDataByIndex(rowMap, sheet, rowIndex, fields)
-		rowIndex++
-
+		if err := w.file.Write(getExcelRowValues(rowMap, w.fields, w.colCount)); nil != err {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// Close write the comment sheet and the end of the excel file
+func (w *HostExcelWriter) Close() error {
+	if nil != w.commentRows {
+		if err := w.file.NextSheet(); nil != err {
+			return err
+		}
+		for _, row := range w.commentRows {
+			if err := w.file.Write(row); nil != err {
+				return err
+			}
+		}
+	}
+	return w.file.Close()
+}
+
 //BuildExcelTemplate  return httpcode, error
 func BuildExcelTemplate(url, objID, filename string, header http.Header, defLang lang.DefaultCCLanguageIf) error {
 	filterFields := getFilterFields(objID)
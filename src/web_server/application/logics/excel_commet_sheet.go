@@ -26,25 +26,15 @@ import (
 
 // ProductExcelHealer Excel comment sheet，
 func ProductExcelCommentSheet(excel *xlsx.File, defLang lang.DefaultCCLanguageIf) {
-	sheetName := defLang.Language(common.ExcelCommentSheetCotentLangPrefixKey + "_sheet_name")
-	if "" == sheetName {
-		sheetName = "comment"
-	}
+	sheetName := getCommentSheetName(defLang)
 
 	sheet, err := excel.AddSheet(sheetName)
 	if nil != err {
 		blog.Errorf("add comment sheet error,sheet name:%s, error:%s ", sheetName, err.Error())
 		return
 	}
-	strJSON := defLang.Language(common.ExcelCommentSheetCotentLangPrefixKey + "_sheet")
-	if "" == strJSON {
-		blog.Errorf("excel comment sheet content is empty")
-		return
-	}
-	var jsSheet jsonSheet
-	err = json.Unmarshal([]byte(strJSON), &jsSheet)
-	if nil != err {
-		blog.Errorf("excel comment sheet content not json format ")
+	jsSheet := getCommentSheetContent(defLang)
+	if nil == jsSheet {
 		return
 	}
 
@@ -96,15 +86,7 @@ func ProductExcelCommentSheet(excel *xlsx.File, defLang lang.DefaultCCLanguageIf
 			cell.SetFormula(c.Formula)
 			cell.Hidden = c.Hidden
 			cell.HMerge = c.HMerge
-			value := c.Value
-
-			if strings.HasPrefix(c.Value, "_") && !strings.HasPrefix(c.Value, "__") {
-				value = defLang.Language(common.ExcelCommentSheetCotentLangPrefixKey + c.Value)
-				if "" == value {
-					value = c.Value
-				}
-			}
-			cell.SetValue(value)
+			cell.SetValue(getCommentCellValue(c, defLang))
 
 			cell.VMerge = c.VMerge
 			if nil != c.Style {
@@ -130,6 +112,72 @@ func ProductExcelCommentSheet(excel *xlsx.File, defLang lang.DefaultCCLanguageIf
 
 }
 
+func getCommentSheetName(defLang lang.DefaultCCLanguageIf) string {
+	sheetName := defLang.Language(common.ExcelCommentSheetCotentLangPrefixKey + "_sheet_name")
+	if "" == sheetName {
+		sheetName = "comment"
+	}
+	return sheetName
+}
+
+// getCommentSheetContent return nil when the comment sheet content is not configured
+func getCommentSheetContent(defLang lang.DefaultCCLanguageIf) *jsonSheet {
+	strJSON := defLang.Language(common.ExcelCommentSheetCotentLangPrefixKey + "_sheet")
+	if "" == strJSON {
+		blog.Errorf("excel comment sheet content is empty")
+		return nil
+	}
+	var jsSheet jsonSheet
+	err := json.Unmarshal([]byte(strJSON), &jsSheet)
+	if nil != err {
+		blog.Errorf("excel comment sheet content not json format ")
+		return nil
+	}
+	return &jsSheet
+}
+
+// getCommentCellValue the value starts with one underline is the language key of the content
+func getCommentCellValue(c *cell, defLang lang.DefaultCCLanguageIf) string {
+	value := c.Value
+	if strings.HasPrefix(c.Value, "_") && !strings.HasPrefix(c.Value, "__") {
+		value = defLang.Language(common.ExcelCommentSheetCotentLangPrefixKey + c.Value)
+		if "" == value {
+			value = c.Value
+		}
+	}
+	return value
+}
+
+// getCommentSheetValues return the comment sheet as the cell values of the rows, without the styles,
+// all the rows have the same number of cells
+func getCommentSheetValues(defLang lang.DefaultCCLanguageIf) (string, [][]string) {
+	sheetName := getCommentSheetName(defLang)
+	jsSheet := getCommentSheetContent(defLang)
+	if nil == jsSheet {
+		return sheetName, nil
+	}
+
+	colCount := 1
+	for _, row := range jsSheet.Rows {
+		if nil != row && colCount < len(row.Cells) {
+			colCount = len(row.Cells)
+		}
+	}
+	rows := make([][]string, 0, len(jsSheet.Rows))
+	for _, row := range jsSheet.Rows {
+		values := make([]string, colCount)
+		if nil != row {
+			for cIdx, c := range row.Cells {
+				if nil != c {
+					values[cIdx] = getCommentCellValue(c, defLang)
+				}
+			}
+		}
+		rows = append(rows, values)
+	}
+	return sheetName, rows
+}
+
 type style struct {
 	Border         xlsx.Border
 	Fill           xlsx.Fill
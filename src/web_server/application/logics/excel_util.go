@@ -257,34 +257,17 @@ func productExcelHealer(fields map[string]Property, filter []string, sheet *xlsx
 	for _, field := range fields {
 		index := field.ExcelColIndex
 		sheet.Col(index).Width = 18
-		fieldTypeName, skip := getPropertyTypeAliasName(field.PropertyType, defLang)
+		headerName, headerType, skip := getExcelHeaderNames(field, filter, defLang)
 		if true == skip {
 			//不需要用户输入的类型continue
 			continue
 		}
-		isRequire := ""
-
-		if field.IsRequire {
-			isRequire = defLang.Language("web_excel_header_required") //"(必填)"
-		}
-		if util.Contains(filter, field.ID) {
-			continue
-		}
 		cellName := sheet.Cell(0, index)
-		cellName.Value = field.Name + isRequire
+		cellName.Value = headerName
 		cellName.SetStyle(getHeaderFirstRowCellStyle(field.IsRequire))
 
-		asstPrimaryKey := ""
-		if 0 < len(field.AsstObjPrimaryProperty) {
-			var primaryKeys []string
-			for _, f := range field.AsstObjPrimaryProperty {
-				primaryKeys = append(primaryKeys, f.Name)
-			}
-			asstPrimaryKey = fmt.Sprintf("(%s)", strings.Join(primaryKeys, common.ExcelAsstPrimaryKeySplitChar))
-		}
-
 		cellType := sheet.Cell(1, index)
-		cellType.Value = fieldTypeName + asstPrimaryKey
+		cellType.Value = headerType
 		cellType.SetStyle(styleCell)
 
 		cellEnName := sheet.Cell(2, index)
@@ -322,3 +305,103 @@ func productExcelHealer(fields map[string]Property, filter []string, sheet *xlsx
 	}
 
 }
+
+// getExcelHeaderNames return the values of the first two header rows of the field,
+// skip is true when the field is not written to the header
+func getExcelHeaderNames(field Property, filter []string, defLang lang.DefaultCCLanguageIf) (name, typeName string, skip bool) {
+	fieldTypeName, skip := getPropertyTypeAliasName(field.PropertyType, defLang)
+	if true == skip {
+		return "", "", true
+	}
+	if util.Contains(filter, field.ID) {
+		return "", "", true
+	}
+	isRequire := ""
+	if field.IsRequire {
+		isRequire = defLang.Language("web_excel_header_required") //"(必填)"
+	}
+
+	asstPrimaryKey := ""
+	if 0 < len(field.AsstObjPrimaryProperty) {
+		var primaryKeys []string
+		for _, f := range field.AsstObjPrimaryProperty {
+			primaryKeys = append(primaryKeys, f.Name)
+		}
+		asstPrimaryKey = fmt.Sprintf("(%s)", strings.Join(primaryKeys, common.ExcelAsstPrimaryKeySplitChar))
+	}
+	return field.Name + isRequire, fieldTypeName + asstPrimaryKey, false
+}
+
+// getExcelColCount return the number of the columns the fields used
+func getExcelColCount(fields map[string]Property) int {
+	colCount := 0
+	for _, field := range fields {
+		if colCount <= field.ExcelColIndex {
+			colCount = field.ExcelColIndex + 1
+		}
+	}
+	return colCount
+}
+
+// getExcelHeaderValues return the values of the three header rows, the same as productExcelHealer writes
+func getExcelHeaderValues(fields map[string]Property, filter []string, defLang lang.DefaultCCLanguageIf) [][]string {
+	colCount := getExcelColCount(fields)
+	rows := [][]string{make([]string, colCount), make([]string, colCount), make([]string, colCount)}
+	for _, field := range fields {
+		name, typeName, skip := getExcelHeaderNames(field, filter, defLang)
+		if true == skip {
+			continue
+		}
+		rows[0][field.ExcelColIndex] = name
+		rows[1][field.ExcelColIndex] = typeName
+		rows[2][field.ExcelColIndex] = field.ID
+	}
+	return rows
+}
+
+// getExcelRowValues convert the row to the cell values, the same as setExcelRowDataByIndex writes
+func getExcelRowValues(rowMap map[string]interface{}, fields map[string]Property, colCount int) []string {
+	values := make([]string, colCount)
+	for id, val := range rowMap {
+		property, ok := fields[id]
+		if false == ok || nil == val || property.ExcelColIndex >= colCount {
+			continue
+		}
+
+		switch property.PropertyType {
+		case common.FieldTypeMultiAsst, common.FieldTypeSingleAsst:
+			arrVal, ok := val.([]interface{})
+			if true == ok {
+				vals := getAssociatePrimaryKey(arrVal, property.AsstObjPrimaryProperty)
+				values[property.ExcelColIndex] = strings.Join(vals, "\n")
+			}
+
+		case common.FieldTypeEnum:
+			arrVal, ok := property.Option.([]interface{})
+			strEnumID, enumIDOk := val.(string)
+			if true == ok || true == enumIDOk {
+				values[property.ExcelColIndex] = getEnumNameByID(strEnumID, arrVal)
+			}
+
+		case common.FieldTypeBool:
+			bl, ok := val.(bool)
+			if ok {
+				if bl {
+					values[property.ExcelColIndex] = fieldTypeBoolTrue
+				} else {
+					values[property.ExcelColIndex] = fieldTypeBoolFalse
+				}
+			}
+
+		case common.FieldTypeInt:
+			intVal, err := util.GetInt64ByInterface(val)
+			if nil == err {
+				values[property.ExcelColIndex] = strconv.FormatInt(intVal, 10)
+			}
+
+		default:
+			values[property.ExcelColIndex] = fmt.Sprintf("%v", val)
+		}
+	}
+	return values
+}
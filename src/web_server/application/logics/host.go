@@ -20,19 +20,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
-	simplejson "github.com/bitly/go-simplejson"
 	"github.com/rentiansheng/xlsx"
 
 	webCommon "configcenter/src/web_server/common"
 )
 
-//GetHostData get host data from excel
-func GetHostData(appIDStr, hostIDStr, apiAddr string, header http.Header) ([]interface{}, error) {
-	hostInfo := make([]interface{}, 0)
+// hostExportPageSize the count of the hosts read and written to the excel at a time
+const hostExportPageSize = 500
+
+// GetHostDataByPage read the hosts from the export of the host server page by page,
+// and pass each page to the handler, so the hosts are never loaded at once
+func GetHostDataByPage(appIDStr, hostIDStr, apiAddr string, header http.Header, handle func(hosts []interface{}) error) error {
 	sHostCond := make(map[string]interface{})
 	appID, _ := strconv.Atoi(appIDStr)
 	hostIDArr := strings.Split(hostIDStr, ",")
@@ -89,24 +92,62 @@ func GetHostData(appIDStr, hostIDStr, apiAddr string, header http.Header) ([]int
 		sHostCond["page"] = make(map[string]interface{})
 
 	}
-	url := apiAddr + fmt.Sprintf("/api/%s/hosts/search/asstdetail", webCommon.API_VERSION)
-	result, _ := httpRequest(url, sHostCond, header)
-	blog.Infof("search host  url:%s", url)
-	blog.Infof("search host  return:%s", result)
-	js, _ := simplejson.NewJson([]byte(result))
-	hostData, _ := js.Map()
-	hostResult := hostData["result"].(bool)
-	if false == hostResult {
-		return hostInfo, errors.New(hostData["bk_error_msg"].(string))
+	url := apiAddr + fmt.Sprintf("/api/%s/hosts/export?format=ndjson&detail=true&page_size=%d", webCommon.API_VERSION, hostExportPageSize)
+	params, _ := json.Marshal(sHostCond)
+	blog.Infof("export host url:%s, input:%s", url, params)
+	httpClient := httpclient.NewHttpClient()
+	httpClient.SetHeader("Content-Type", "application/json")
+	rsp, err := httpClient.RequestStream(url, http.MethodPost, header, params)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	decoder := json.NewDecoder(rsp.Body)
+	decoder.UseNumber()
+	if http.StatusOK != rsp.StatusCode {
+		result := make(map[string]interface{})
+		if err := decoder.Decode(&result); err != nil {
+			return fmt.Errorf("export host failed, status: %s", rsp.Status)
+		}
+		return fmt.Errorf("%v", result["bk_error_msg"])
+	}
+
+	count := 0
+	page := make([]interface{}, 0, hostExportPageSize)
+	for {
+		row := make(map[string]interface{})
+		err := decoder.Decode(&row)
+		if io.EOF == err {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		// the export ends with a failed response when it fails after the hosts are written
+		if errMsg, ok := row["bk_error_msg"]; ok {
+			return fmt.Errorf("%v", errMsg)
+		}
+
+		page = append(page, row)
+		count++
+		if hostExportPageSize == len(page) {
+			if err := handle(page); err != nil {
+				return err
+			}
+			page = make([]interface{}, 0, hostExportPageSize)
+		}
+	}
+	if 0 != len(page) {
+		if err := handle(page); err != nil {
+			return err
+		}
 	}
-	hostDataArr := hostData["data"].(map[string]interface{})
-	hostInfo = hostDataArr["info"].([]interface{})
-	hostCnt, _ := hostDataArr["count"].(json.Number).Int64()
-	if !hostResult || 0 == hostCnt {
-		return hostInfo, errors.New("no host")
+	if 0 == count {
+		return errors.New("no host")
 	}
 
-	return hostInfo, nil
+	return nil
 }
 
 // GetImportHosts get import hosts